@@ -0,0 +1,261 @@
+package debugutils
+
+import (
+	detour "github.com/fananchong/recastnavigation-go/Detour"
+)
+
+const DU_PI float32 = 3.14159265
+
+/// Primitives that can be drawn with a DuDebugDraw.
+type DuDebugDrawPrimitives int
+
+const (
+	DU_DRAW_POINTS DuDebugDrawPrimitives = iota
+	DU_DRAW_LINES
+	DU_DRAW_TRIS
+	DU_DRAW_QUADS
+)
+
+/// Abstract debug draw interface.
+/// Vertices are given in world space. Lines are submitted as pairs, triangles
+/// as triples and quads as quadruples of vertices between Begin and End.
+type DuDebugDraw interface {
+	/// Begin drawing primitives.
+	///  @param[in]		prim	The type of primitive.
+	///  @param[in]		size	The size of the primitive, applies to point size and line width only.
+	Begin(prim DuDebugDrawPrimitives, size float32)
+
+	/// Submit a vertex.
+	///  @param[in]		pos		Position of the vertex. [(x, y, z)]
+	///  @param[in]		color	Color of the vertex. (See: #DuRGBA)
+	Vertex(pos []float32, color uint32)
+
+	/// End drawing primitives.
+	End()
+}
+
+/// Packs a color into the 32 bit RGBA format used by the debug utilities.
+func DuRGBA(r, g, b, a int) uint32 {
+	return uint32(r&0xff) | uint32(g&0xff)<<8 | uint32(b&0xff)<<16 | uint32(a&0xff)<<24
+}
+
+/// Packs a color from float components in range [0..1].
+func DuRGBAf(fr, fg, fb, fa float32) uint32 {
+	return DuRGBA(int(fr*255), int(fg*255), int(fb*255), int(fa*255))
+}
+
+/// Unpacks a color into float components in range [0..1]. [(r, g, b, a)]
+func DuColToRGBAf(col uint32, out []float32) {
+	out[0] = float32(col&0xff) / 255
+	out[1] = float32((col>>8)&0xff) / 255
+	out[2] = float32((col>>16)&0xff) / 255
+	out[3] = float32((col>>24)&0xff) / 255
+}
+
+func bit(a, b int) int {
+	return (a & (1 << uint(b))) >> uint(b)
+}
+
+/// Returns a distinct color for the specified integer.
+func DuIntToCol(i, a int) uint32 {
+	r := bit(i, 1) + bit(i, 3)*2 + 1
+	g := bit(i, 2) + bit(i, 4)*2 + 1
+	b := bit(i, 0) + bit(i, 5)*2 + 1
+	return DuRGBA(r*63, g*63, b*63, a)
+}
+
+/// Replaces the alpha of the color.
+func DuTransCol(c uint32, a uint32) uint32 {
+	return (a << 24) | (c & 0x00ffffff)
+}
+
+/// Multiplies the color channels by d/255.
+func DuMultCol(col uint32, d uint32) uint32 {
+	r := col & 0xff
+	g := (col >> 8) & 0xff
+	b := (col >> 16) & 0xff
+	a := (col >> 24) & 0xff
+	return DuRGBA(int((r*d)>>8), int((g*d)>>8), int((b*d)>>8), int(a))
+}
+
+/// Halves the color intensity, keeping alpha.
+func DuDarkenCol(col uint32) uint32 {
+	return ((col >> 1) & 0x007f7f7f) | (col & 0xff000000)
+}
+
+/// Linearly interpolates between two colors, u in range [0..255].
+func DuLerpCol(ca, cb, u uint32) uint32 {
+	ra := ca & 0xff
+	ga := (ca >> 8) & 0xff
+	ba := (ca >> 16) & 0xff
+	aa := (ca >> 24) & 0xff
+	rb := cb & 0xff
+	gb := (cb >> 8) & 0xff
+	bb := (cb >> 16) & 0xff
+	ab := (cb >> 24) & 0xff
+
+	r := (ra*(255-u) + rb*u) / 255
+	g := (ga*(255-u) + gb*u) / 255
+	b := (ba*(255-u) + bb*u) / 255
+	a := (aa*(255-u) + ab*u) / 255
+	return DuRGBA(int(r), int(g), int(b), int(a))
+}
+
+/// Returns the color used to draw the specified area id.
+/// Area 0 is the default walkable area and is drawn in light blue.
+func DuAreaToCol(area uint8) uint32 {
+	if area == 0 {
+		return DuRGBA(0, 192, 255, 255)
+	}
+	return DuIntToCol(int(area), 255)
+}
+
+func duVertex(dd DuDebugDraw, x, y, z float32, color uint32) {
+	pos := [3]float32{x, y, z}
+	dd.Vertex(pos[:], color)
+}
+
+func evalArc(x0, y0, z0, dx, dy, dz, h, u float32, res []float32) {
+	res[0] = x0 + dx*u
+	res[1] = y0 + dy*u + h*(1-(u*2-1)*(u*2-1))
+	res[2] = z0 + dz*u
+}
+
+func appendArrowHead(dd DuDebugDraw, p, q []float32, s float32, col uint32) {
+	const eps float32 = 0.001
+	if detour.DtVdistSqr(p, q) < eps*eps {
+		return
+	}
+	var ax, az [3]float32
+	ay := [3]float32{0, 1, 0}
+	detour.DtVsub(az[:], q, p)
+	detour.DtVnormalize(az[:])
+	detour.DtVcross(ax[:], ay[:], az[:])
+	detour.DtVcross(ay[:], az[:], ax[:])
+	detour.DtVnormalize(ay[:])
+
+	dd.Vertex(p, col)
+	duVertex(dd, p[0]+az[0]*s+ax[0]*s/3, p[1]+az[1]*s+ax[1]*s/3, p[2]+az[2]*s+ax[2]*s/3, col)
+
+	dd.Vertex(p, col)
+	duVertex(dd, p[0]+az[0]*s-ax[0]*s/3, p[1]+az[1]*s-ax[1]*s/3, p[2]+az[2]*s-ax[2]*s/3, col)
+}
+
+/// Appends an arc from (x0, y0, z0) to (x1, y1, z1) as line segments.
+///  @param[in]		h		The height of the arc relative to its length.
+///  @param[in]		as0		The size of the arrow head at the start, or zero for none.
+///  @param[in]		as1		The size of the arrow head at the end, or zero for none.
+func DuAppendArc(dd DuDebugDraw, x0, y0, z0, x1, y1, z1, h, as0, as1 float32, col uint32) {
+	const NUM_ARC_PTS int = 8
+	const PAD float32 = 0.05
+	const ARC_PTS_SCALE float32 = (1.0 - PAD*2) / float32(NUM_ARC_PTS)
+	dx := x1 - x0
+	dy := y1 - y0
+	dz := z1 - z0
+	length := detour.DtMathSqrtf(dx*dx + dy*dy + dz*dz)
+	var prev, pt [3]float32
+	evalArc(x0, y0, z0, dx, dy, dz, length*h, PAD, prev[:])
+	for i := 1; i <= NUM_ARC_PTS; i++ {
+		u := PAD + float32(i)*ARC_PTS_SCALE
+		evalArc(x0, y0, z0, dx, dy, dz, length*h, u, pt[:])
+		dd.Vertex(prev[:], col)
+		dd.Vertex(pt[:], col)
+		prev = pt
+	}
+
+	// End arrows
+	if as0 > 0.001 {
+		var p, q [3]float32
+		evalArc(x0, y0, z0, dx, dy, dz, length*h, PAD, p[:])
+		evalArc(x0, y0, z0, dx, dy, dz, length*h, PAD+0.05, q[:])
+		appendArrowHead(dd, p[:], q[:], as0, col)
+	}
+	if as1 > 0.001 {
+		var p, q [3]float32
+		evalArc(x0, y0, z0, dx, dy, dz, length*h, 1-PAD, p[:])
+		evalArc(x0, y0, z0, dx, dy, dz, length*h, 1-(PAD+0.05), q[:])
+		appendArrowHead(dd, p[:], q[:], as1, col)
+	}
+}
+
+const duCircleSegs int = 40
+
+var duCircleDir = func() (dir [duCircleSegs * 2]float32) {
+	for i := 0; i < duCircleSegs; i++ {
+		a := float32(i) / float32(duCircleSegs) * DU_PI * 2
+		dir[i*2] = detour.DtMathCosf(a)
+		dir[i*2+1] = detour.DtMathSinf(a)
+	}
+	return
+}()
+
+/// Appends a horizontal circle as line segments.
+func DuAppendCircle(dd DuDebugDraw, x, y, z, r float32, col uint32) {
+	for i, j := 0, duCircleSegs-1; i < duCircleSegs; j, i = i, i+1 {
+		duVertex(dd, x+duCircleDir[j*2+0]*r, y, z+duCircleDir[j*2+1]*r, col)
+		duVertex(dd, x+duCircleDir[i*2+0]*r, y, z+duCircleDir[i*2+1]*r, col)
+	}
+}
+
+/// Appends a horizontal cross as line segments.
+func DuAppendCross(dd DuDebugDraw, x, y, z, s float32, col uint32) {
+	duVertex(dd, x-s, y, z, col)
+	duVertex(dd, x+s, y, z, col)
+	duVertex(dd, x, y-s, z, col)
+	duVertex(dd, x, y+s, z, col)
+	duVertex(dd, x, y, z-s, col)
+	duVertex(dd, x, y, z+s, col)
+}
+
+type duDisplayPrim struct {
+	prim  DuDebugDrawPrimitives
+	size  float32
+	verts []float32 ///< [(x, y, z) * n]
+	cols  []uint32  ///< [n]
+}
+
+/// A debug draw implementation that records the submitted primitives, so
+/// that they can be rendered later. (See: #DuDisplayList.WriteSVG, #DuDisplayList.WritePNG)
+type DuDisplayList struct {
+	m_prims []duDisplayPrim
+	m_cur   *duDisplayPrim
+}
+
+func (this *DuDisplayList) Begin(prim DuDebugDrawPrimitives, size float32) {
+	this.m_prims = append(this.m_prims, duDisplayPrim{prim: prim, size: size})
+	this.m_cur = &this.m_prims[len(this.m_prims)-1]
+}
+
+func (this *DuDisplayList) Vertex(pos []float32, color uint32) {
+	if this.m_cur == nil {
+		return
+	}
+	this.m_cur.verts = append(this.m_cur.verts, pos[0], pos[1], pos[2])
+	this.m_cur.cols = append(this.m_cur.cols, color)
+}
+
+func (this *DuDisplayList) End() {
+	this.m_cur = nil
+}
+
+/// Removes all recorded primitives.
+func (this *DuDisplayList) Clear() {
+	this.m_prims = this.m_prims[:0]
+	this.m_cur = nil
+}
+
+/// Replays the recorded primitives to another debug draw.
+func (this *DuDisplayList) Draw(dd DuDebugDraw) {
+	for i := range this.m_prims {
+		p := &this.m_prims[i]
+		dd.Begin(p.prim, p.size)
+		for j := range p.cols {
+			dd.Vertex(p.verts[j*3:], p.cols[j])
+		}
+		dd.End()
+	}
+}
+
+func DuAllocDisplayList() *DuDisplayList {
+	return &DuDisplayList{}
+}
@@ -0,0 +1,298 @@
+package debugutils
+
+import (
+	"bufio"
+	"fmt"
+	"image"
+	"image/png"
+	"io"
+	"math"
+
+	detour "github.com/fananchong/recastnavigation-go/Detour"
+)
+
+/// Parameters used to render a DuDisplayList as a top-down image.
+/// The world x-axis maps to the image x-axis and the world z-axis maps to
+/// the image y-axis.
+type DuRenderParams struct {
+	Width      int        ///< The width of the image in pixels. The height follows the aspect ratio of the bounds.
+	Margin     int        ///< The empty border around the drawing in pixels.
+	Background uint32     ///< The background color. (See: #DuRGBA)
+	Bmin       [3]float32 ///< The minimum world bounds to render. [(x, y, z)]
+	Bmax       [3]float32 ///< The maximum world bounds to render. If equal to #Bmin, the bounds of the primitives are used. [(x, y, z)]
+}
+
+/// Returns the default render parameters.
+func DuDefaultRenderParams() *DuRenderParams {
+	return &DuRenderParams{
+		Width:      1024,
+		Margin:     8,
+		Background: DuRGBA(255, 255, 255, 255),
+	}
+}
+
+type duProjection struct {
+	minx, minz float32
+	scale      float32
+	margin     float32
+	width      int
+	height     int
+}
+
+func (this *duProjection) project(v []float32) (float32, float32) {
+	return this.margin + (v[0]-this.minx)*this.scale, this.margin + (v[2]-this.minz)*this.scale
+}
+
+func (this *DuDisplayList) calcBounds(bmin, bmax []float32) bool {
+	detour.DtVset(bmin, math.MaxFloat32, math.MaxFloat32, math.MaxFloat32)
+	detour.DtVset(bmax, -math.MaxFloat32, -math.MaxFloat32, -math.MaxFloat32)
+	found := false
+	for i := range this.m_prims {
+		p := &this.m_prims[i]
+		for j := range p.cols {
+			detour.DtVmin(bmin, p.verts[j*3:])
+			detour.DtVmax(bmax, p.verts[j*3:])
+			found = true
+		}
+	}
+	return found
+}
+
+func (this *DuDisplayList) projection(params *DuRenderParams) *duProjection {
+	var bmin, bmax [3]float32
+	if params.Bmin != params.Bmax {
+		bmin = params.Bmin
+		bmax = params.Bmax
+	} else if !this.calcBounds(bmin[:], bmax[:]) {
+		bmin = [3]float32{}
+		bmax = [3]float32{1, 1, 1}
+	}
+	proj := &duProjection{
+		minx:   bmin[0],
+		minz:   bmin[2],
+		margin: float32(params.Margin),
+		width:  params.Width,
+	}
+	w := bmax[0] - bmin[0]
+	h := bmax[2] - bmin[2]
+	if w < 1e-6 {
+		w = 1e-6
+	}
+	inner := float32(params.Width - 2*params.Margin)
+	if inner < 1 {
+		inner = 1
+	}
+	proj.scale = inner / w
+	proj.height = int(detour.DtMathCeilf(h*proj.scale)) + 2*params.Margin
+	if proj.height < 1 {
+		proj.height = 1
+	}
+	return proj
+}
+
+/// Visits every primitive as triangles, lines and points in drawing order.
+/// Quads are split into two triangles.
+func (this *DuDisplayList) visit(tri func(a, b, c []float32, col uint32), line func(a, b []float32, col uint32, size float32),
+	point func(a []float32, col uint32, size float32)) {
+	for i := range this.m_prims {
+		p := &this.m_prims[i]
+		n := len(p.cols)
+		switch p.prim {
+		case DU_DRAW_POINTS:
+			for j := 0; j < n; j++ {
+				point(p.verts[j*3:], p.cols[j], p.size)
+			}
+		case DU_DRAW_LINES:
+			for j := 0; j+1 < n; j += 2 {
+				line(p.verts[j*3:], p.verts[(j+1)*3:], p.cols[j], p.size)
+			}
+		case DU_DRAW_TRIS:
+			for j := 0; j+2 < n; j += 3 {
+				tri(p.verts[j*3:], p.verts[(j+1)*3:], p.verts[(j+2)*3:], p.cols[j])
+			}
+		case DU_DRAW_QUADS:
+			for j := 0; j+3 < n; j += 4 {
+				tri(p.verts[j*3:], p.verts[(j+1)*3:], p.verts[(j+2)*3:], p.cols[j])
+				tri(p.verts[j*3:], p.verts[(j+2)*3:], p.verts[(j+3)*3:], p.cols[j])
+			}
+		}
+	}
+}
+
+func svgColor(col uint32) (string, float32) {
+	var c [4]float32
+	DuColToRGBAf(col, c[:])
+	return fmt.Sprintf("#%02x%02x%02x", col&0xff, (col>>8)&0xff, (col>>16)&0xff), c[3]
+}
+
+/// Writes the recorded primitives as a top-down SVG image.
+///  @param[in]		w		The writer to write to.
+///  @param[in]		params	The render parameters, or nil for the defaults.
+/// @return The first write error, if any.
+func (this *DuDisplayList) WriteSVG(w io.Writer, params *DuRenderParams) error {
+	if params == nil {
+		params = DuDefaultRenderParams()
+	}
+	proj := this.projection(params)
+	bw := bufio.NewWriter(w)
+
+	fmt.Fprintf(bw, "<svg xmlns=\"http://www.w3.org/2000/svg\" width=\"%d\" height=\"%d\" viewBox=\"0 0 %d %d\">\n",
+		proj.width, proj.height, proj.width, proj.height)
+	bg, bga := svgColor(params.Background)
+	fmt.Fprintf(bw, "<rect width=\"100%%\" height=\"100%%\" fill=\"%s\" fill-opacity=\"%.3f\"/>\n", bg, bga)
+
+	this.visit(func(a, b, c []float32, col uint32) {
+		ax, ay := proj.project(a)
+		bx, by := proj.project(b)
+		cx, cy := proj.project(c)
+		fill, opacity := svgColor(col)
+		fmt.Fprintf(bw, "<polygon points=\"%.2f,%.2f %.2f,%.2f %.2f,%.2f\" fill=\"%s\" fill-opacity=\"%.3f\"/>\n",
+			ax, ay, bx, by, cx, cy, fill, opacity)
+	}, func(a, b []float32, col uint32, size float32) {
+		ax, ay := proj.project(a)
+		bx, by := proj.project(b)
+		stroke, opacity := svgColor(col)
+		fmt.Fprintf(bw, "<line x1=\"%.2f\" y1=\"%.2f\" x2=\"%.2f\" y2=\"%.2f\" stroke=\"%s\" stroke-opacity=\"%.3f\" stroke-width=\"%.2f\" stroke-linecap=\"round\"/>\n",
+			ax, ay, bx, by, stroke, opacity, size)
+	}, func(a []float32, col uint32, size float32) {
+		ax, ay := proj.project(a)
+		fill, opacity := svgColor(col)
+		fmt.Fprintf(bw, "<circle cx=\"%.2f\" cy=\"%.2f\" r=\"%.2f\" fill=\"%s\" fill-opacity=\"%.3f\"/>\n",
+			ax, ay, size*0.5, fill, opacity)
+	})
+
+	fmt.Fprintf(bw, "</svg>\n")
+	return bw.Flush()
+}
+
+func blendPixel(img *image.NRGBA, x, y int, col uint32) {
+	if x < 0 || y < 0 || x >= img.Rect.Dx() || y >= img.Rect.Dy() {
+		return
+	}
+	sa := (col >> 24) & 0xff
+	if sa == 0 {
+		return
+	}
+	o := img.PixOffset(x, y)
+	pix := img.Pix[o : o+4 : o+4]
+	da := uint32(pix[3])
+	// Non-premultiplied source over.
+	oa := sa*255 + da*(255-sa)
+	if oa == 0 {
+		return
+	}
+	for k := uint32(0); k < 3; k++ {
+		sc := (col >> (k * 8)) & 0xff
+		dc := uint32(pix[k])
+		pix[k] = uint8((sc*sa*255 + dc*da*(255-sa)) / oa)
+	}
+	pix[3] = uint8(oa / 255)
+}
+
+func clampRange(lo, hi float32, n int) (int, int) {
+	a := int(detour.DtMathFloorf(lo))
+	b := int(detour.DtMathCeilf(hi))
+	if a < 0 {
+		a = 0
+	}
+	if b > n-1 {
+		b = n - 1
+	}
+	return a, b
+}
+
+func rasterTri(img *image.NRGBA, ax, ay, bx, by, cx, cy float32, col uint32) {
+	area := (bx-ax)*(cy-ay) - (by-ay)*(cx-ax)
+	if area == 0 {
+		return
+	}
+	if area < 0 {
+		bx, by, cx, cy = cx, cy, bx, by
+	}
+	x0, x1 := clampRange(detour.DtMinFloat32(ax, detour.DtMinFloat32(bx, cx)), detour.DtMaxFloat32(ax, detour.DtMaxFloat32(bx, cx)), img.Rect.Dx())
+	y0, y1 := clampRange(detour.DtMinFloat32(ay, detour.DtMinFloat32(by, cy)), detour.DtMaxFloat32(ay, detour.DtMaxFloat32(by, cy)), img.Rect.Dy())
+	for y := y0; y <= y1; y++ {
+		py := float32(y) + 0.5
+		for x := x0; x <= x1; x++ {
+			px := float32(x) + 0.5
+			w0 := (bx-ax)*(py-ay) - (by-ay)*(px-ax)
+			w1 := (cx-bx)*(py-by) - (cy-by)*(px-bx)
+			w2 := (ax-cx)*(py-cy) - (ay-cy)*(px-cx)
+			if w0 >= 0 && w1 >= 0 && w2 >= 0 {
+				blendPixel(img, x, y, col)
+			}
+		}
+	}
+}
+
+func rasterLine(img *image.NRGBA, ax, ay, bx, by float32, col uint32, width float32) {
+	hw := detour.DtMaxFloat32(width*0.5, 0.5)
+	x0, x1 := clampRange(detour.DtMinFloat32(ax, bx)-hw, detour.DtMaxFloat32(ax, bx)+hw, img.Rect.Dx())
+	y0, y1 := clampRange(detour.DtMinFloat32(ay, by)-hw, detour.DtMaxFloat32(ay, by)+hw, img.Rect.Dy())
+	a := [3]float32{ax, 0, ay}
+	b := [3]float32{bx, 0, by}
+	for y := y0; y <= y1; y++ {
+		for x := x0; x <= x1; x++ {
+			pt := [3]float32{float32(x) + 0.5, 0, float32(y) + 0.5}
+			var t float32
+			if detour.DtDistancePtSegSqr2D(pt[:], a[:], b[:], &t) <= hw*hw {
+				blendPixel(img, x, y, col)
+			}
+		}
+	}
+}
+
+func rasterPoint(img *image.NRGBA, ax, ay float32, col uint32, size float32) {
+	r := detour.DtMaxFloat32(size*0.5, 0.5)
+	x0, x1 := clampRange(ax-r, ax+r, img.Rect.Dx())
+	y0, y1 := clampRange(ay-r, ay+r, img.Rect.Dy())
+	for y := y0; y <= y1; y++ {
+		dy := float32(y) + 0.5 - ay
+		for x := x0; x <= x1; x++ {
+			dx := float32(x) + 0.5 - ax
+			if dx*dx+dy*dy <= r*r {
+				blendPixel(img, x, y, col)
+			}
+		}
+	}
+}
+
+/// Rasterizes the recorded primitives into a top-down image.
+///  @param[in]		params	The render parameters, or nil for the defaults.
+/// @return The rendered image.
+func (this *DuDisplayList) Rasterize(params *DuRenderParams) *image.NRGBA {
+	if params == nil {
+		params = DuDefaultRenderParams()
+	}
+	proj := this.projection(params)
+	img := image.NewNRGBA(image.Rect(0, 0, proj.width, proj.height))
+	for i := 0; i < len(img.Pix); i += 4 {
+		img.Pix[i+0] = uint8(params.Background & 0xff)
+		img.Pix[i+1] = uint8((params.Background >> 8) & 0xff)
+		img.Pix[i+2] = uint8((params.Background >> 16) & 0xff)
+		img.Pix[i+3] = uint8((params.Background >> 24) & 0xff)
+	}
+
+	this.visit(func(a, b, c []float32, col uint32) {
+		ax, ay := proj.project(a)
+		bx, by := proj.project(b)
+		cx, cy := proj.project(c)
+		rasterTri(img, ax, ay, bx, by, cx, cy, col)
+	}, func(a, b []float32, col uint32, size float32) {
+		ax, ay := proj.project(a)
+		bx, by := proj.project(b)
+		rasterLine(img, ax, ay, bx, by, col, size)
+	}, func(a []float32, col uint32, size float32) {
+		ax, ay := proj.project(a)
+		rasterPoint(img, ax, ay, col, size)
+	})
+	return img
+}
+
+/// Writes the recorded primitives as a top-down PNG image.
+///  @param[in]		w		The writer to write to.
+///  @param[in]		params	The render parameters, or nil for the defaults.
+/// @return The encoding or write error, if any.
+func (this *DuDisplayList) WritePNG(w io.Writer, params *DuRenderParams) error {
+	return png.Encode(w, this.Rasterize(params))
+}
@@ -0,0 +1,414 @@
+package debugutils
+
+import (
+	detour "github.com/fananchong/recastnavigation-go/Detour"
+)
+
+/// Flags for DuDebugDrawNavMesh.
+type DuDrawNavMeshFlags uint8
+
+const (
+	DU_DRAWNAVMESH_OFFMESHCONS  DuDrawNavMeshFlags = 0x01 ///< Draw off-mesh connections.
+	DU_DRAWNAVMESH_CLOSEDLIST   DuDrawNavMeshFlags = 0x02 ///< Highlight polygons in the closed list of the query.
+	DU_DRAWNAVMESH_COLOR_TILES  DuDrawNavMeshFlags = 0x04 ///< Color polygons by tile instead of by area.
+	DU_DRAWNAVMESH_TILE_BOUNDS  DuDrawNavMeshFlags = 0x08 ///< Draw the bounds of each tile.
+	DU_DRAWNAVMESH_POLY_VERTS   DuDrawNavMeshFlags = 0x10 ///< Draw the polygon vertices.
+	DU_DRAWNAVMESH_INNER_BOUNDS DuDrawNavMeshFlags = 0x20 ///< Draw the boundaries between polygons.
+)
+
+func distancePtLine2d(pt, p, q []float32) float32 {
+	pqx := q[0] - p[0]
+	pqz := q[2] - p[2]
+	dx := pt[0] - p[0]
+	dz := pt[2] - p[2]
+	d := pqx*pqx + pqz*pqz
+	t := pqx*dx + pqz*dz
+	if d != 0 {
+		t /= d
+	}
+	dx = p[0] + t*pqx - pt[0]
+	dz = p[2] + t*pqz - pt[2]
+	return dx*dx + dz*dz
+}
+
+func drawPolyBoundaries(dd DuDebugDraw, tile *detour.DtMeshTile, col uint32, linew float32, inner bool) {
+	const thr float32 = 0.01 * 0.01
+
+	dd.Begin(DU_DRAW_LINES, linew)
+	for i := 0; i < int(tile.Header.PolyCount); i++ {
+		p := &tile.Polys[i]
+		if p.GetType() == detour.DT_POLYTYPE_OFFMESH_CONNECTION {
+			continue
+		}
+		pd := &tile.DetailMeshes[i]
+
+		nj := int(p.VertCount)
+		for j := 0; j < nj; j++ {
+			c := col
+			if inner {
+				if p.Neis[j] == 0 {
+					continue
+				}
+				if (p.Neis[j] & detour.DT_EXT_LINK) != 0 {
+					con := false
+					for k := p.FirstLink; k != detour.DT_NULL_LINK; k = tile.Links[k].Next {
+						if int(tile.Links[k].Edge) == j {
+							con = true
+							break
+						}
+					}
+					if con {
+						c = DuRGBA(255, 255, 255, 48)
+					} else {
+						c = DuRGBA(0, 0, 0, 48)
+					}
+				} else {
+					c = DuRGBA(0, 48, 64, 32)
+				}
+			} else {
+				if p.Neis[j] != 0 {
+					continue
+				}
+			}
+
+			v0 := tile.Verts[p.Verts[j]*3:]
+			v1 := tile.Verts[p.Verts[(j+1)%nj]*3:]
+
+			// Draw detail mesh edges which align with the actual poly edge.
+			// This is really slow.
+			for k := 0; k < int(pd.TriCount); k++ {
+				t := tile.DetailTris[(pd.TriBase+uint32(k))*4:]
+				var tv [3][]float32
+				for m := 0; m < 3; m++ {
+					tv[m] = duGetDetailVert(tile, p, pd, t[m])
+				}
+				for m, n := 0, 2; m < 3; n, m = m, m+1 {
+					if distancePtLine2d(tv[n], v0, v1) < thr &&
+						distancePtLine2d(tv[m], v0, v1) < thr {
+						dd.Vertex(tv[n], c)
+						dd.Vertex(tv[m], c)
+					}
+				}
+			}
+		}
+	}
+	dd.End()
+}
+
+func drawMeshTile(dd DuDebugDraw, mesh *detour.DtNavMesh, query *detour.DtNavMeshQuery,
+	tile *detour.DtMeshTile, flags DuDrawNavMeshFlags) {
+	base := mesh.GetPolyRefBase(tile)
+
+	tileNum := mesh.DecodePolyIdTile(base)
+	tileColor := DuIntToCol(int(tileNum), 128)
+
+	dd.Begin(DU_DRAW_TRIS, 1.0)
+	for i := 0; i < int(tile.Header.PolyCount); i++ {
+		p := &tile.Polys[i]
+		if p.GetType() == detour.DT_POLYTYPE_OFFMESH_CONNECTION { // Skip off-mesh links.
+			continue
+		}
+		pd := &tile.DetailMeshes[i]
+
+		var col uint32
+		if query != nil && query.IsInClosedList(base|detour.DtPolyRef(i)) {
+			col = DuRGBA(255, 196, 0, 64)
+		} else if (flags & DU_DRAWNAVMESH_COLOR_TILES) != 0 {
+			col = tileColor
+		} else {
+			col = DuTransCol(DuAreaToCol(p.GetArea()), 64)
+		}
+
+		for j := 0; j < int(pd.TriCount); j++ {
+			t := tile.DetailTris[(pd.TriBase+uint32(j))*4:]
+			for k := 0; k < 3; k++ {
+				dd.Vertex(duGetDetailVert(tile, p, pd, t[k]), col)
+			}
+		}
+	}
+	dd.End()
+
+	// Draw inter poly boundaries
+	if (flags & DU_DRAWNAVMESH_INNER_BOUNDS) != 0 {
+		drawPolyBoundaries(dd, tile, DuRGBA(0, 48, 64, 32), 1.5, true)
+	}
+
+	// Draw outer poly boundaries
+	drawPolyBoundaries(dd, tile, DuRGBA(0, 48, 64, 220), 2.5, false)
+
+	if (flags & DU_DRAWNAVMESH_OFFMESHCONS) != 0 {
+		dd.Begin(DU_DRAW_LINES, 2.0)
+		for i := 0; i < int(tile.Header.PolyCount); i++ {
+			p := &tile.Polys[i]
+			if p.GetType() != detour.DT_POLYTYPE_OFFMESH_CONNECTION { // Skip regular polys.
+				continue
+			}
+
+			var col, col2 uint32
+			if query != nil && query.IsInClosedList(base|detour.DtPolyRef(i)) {
+				col = DuRGBA(255, 196, 0, 220)
+			} else {
+				col = DuDarkenCol(DuTransCol(DuAreaToCol(p.GetArea()), 220))
+			}
+
+			con := &tile.OffMeshCons[i-int(tile.Header.OffMeshBase)]
+			va := tile.Verts[p.Verts[0]*3:]
+			vb := tile.Verts[p.Verts[1]*3:]
+
+			// Check to see if start and end end-points have links.
+			startSet := false
+			endSet := false
+			for k := p.FirstLink; k != detour.DT_NULL_LINK; k = tile.Links[k].Next {
+				if tile.Links[k].Edge == 0 {
+					startSet = true
+				}
+				if tile.Links[k].Edge == 1 {
+					endSet = true
+				}
+			}
+
+			// End points and their on-mesh locations.
+			dd.Vertex(va, col)
+			dd.Vertex(con.Pos[0:], col)
+			col2 = col
+			if !startSet {
+				col2 = DuRGBA(220, 32, 16, 196)
+			}
+			DuAppendCircle(dd, con.Pos[0], con.Pos[1]+0.1, con.Pos[2], con.Rad, col2)
+
+			dd.Vertex(vb, col)
+			dd.Vertex(con.Pos[3:], col)
+			col2 = col
+			if !endSet {
+				col2 = DuRGBA(220, 32, 16, 196)
+			}
+			DuAppendCircle(dd, con.Pos[3], con.Pos[4]+0.1, con.Pos[5], con.Rad, col2)
+
+			// End point vertices.
+			duVertex(dd, con.Pos[0], con.Pos[1], con.Pos[2], DuRGBA(0, 48, 64, 196))
+			duVertex(dd, con.Pos[0], con.Pos[1]+0.2, con.Pos[2], DuRGBA(0, 48, 64, 196))
+
+			duVertex(dd, con.Pos[3], con.Pos[4], con.Pos[5], DuRGBA(0, 48, 64, 196))
+			duVertex(dd, con.Pos[3], con.Pos[4]+0.2, con.Pos[5], DuRGBA(0, 48, 64, 196))
+
+			// Connection arc.
+			var as0 float32
+			if (con.Flags & detour.DT_OFFMESH_CON_BIDIR) != 0 {
+				as0 = 0.6
+			}
+			DuAppendArc(dd, con.Pos[0], con.Pos[1], con.Pos[2], con.Pos[3], con.Pos[4], con.Pos[5], 0.25,
+				as0, 0.6, col)
+		}
+		dd.End()
+	}
+
+	if (flags & DU_DRAWNAVMESH_POLY_VERTS) != 0 {
+		vcol := DuRGBA(0, 0, 0, 196)
+		dd.Begin(DU_DRAW_POINTS, 3.0)
+		for i := 0; i < int(tile.Header.VertCount); i++ {
+			dd.Vertex(tile.Verts[i*3:], vcol)
+		}
+		dd.End()
+	}
+
+	if (flags & DU_DRAWNAVMESH_TILE_BOUNDS) != 0 {
+		DuDebugDrawTileBounds(dd, tile, DuRGBA(255, 255, 255, 128), 1.0)
+	}
+}
+
+/// Draws the navigation mesh.
+///  @param[in]		dd		The debug draw to draw with.
+///  @param[in]		mesh	The navigation mesh to draw.
+///  @param[in]		flags	Draw flags. (See: #DuDrawNavMeshFlags)
+func DuDebugDrawNavMesh(dd DuDebugDraw, mesh *detour.DtNavMesh, flags DuDrawNavMeshFlags) {
+	for i := 0; i < int(mesh.GetMaxTiles()); i++ {
+		tile := mesh.GetTile(i)
+		if tile == nil || tile.Header == nil {
+			continue
+		}
+		drawMeshTile(dd, mesh, nil, tile, flags)
+	}
+}
+
+/// Draws the navigation mesh, highlighting the polygons in the closed list of
+/// the query if #DU_DRAWNAVMESH_CLOSEDLIST is set.
+///  @param[in]		dd		The debug draw to draw with.
+///  @param[in]		mesh	The navigation mesh to draw.
+///  @param[in]		query	The query whose closed list is highlighted.
+///  @param[in]		flags	Draw flags. (See: #DuDrawNavMeshFlags)
+func DuDebugDrawNavMeshWithClosedList(dd DuDebugDraw, mesh *detour.DtNavMesh, query *detour.DtNavMeshQuery,
+	flags DuDrawNavMeshFlags) {
+	var q *detour.DtNavMeshQuery
+	if (flags & DU_DRAWNAVMESH_CLOSEDLIST) != 0 {
+		q = query
+	}
+	for i := 0; i < int(mesh.GetMaxTiles()); i++ {
+		tile := mesh.GetTile(i)
+		if tile == nil || tile.Header == nil {
+			continue
+		}
+		drawMeshTile(dd, mesh, q, tile, flags)
+	}
+}
+
+/// Draws the horizontal bounds of the tile as a rectangle.
+func DuDebugDrawTileBounds(dd DuDebugDraw, tile *detour.DtMeshTile, col uint32, linew float32) {
+	bmin := tile.Header.Bmin[:]
+	bmax := tile.Header.Bmax[:]
+	y := bmax[1]
+	dd.Begin(DU_DRAW_LINES, linew)
+	duVertex(dd, bmin[0], y, bmin[2], col)
+	duVertex(dd, bmax[0], y, bmin[2], col)
+	duVertex(dd, bmax[0], y, bmin[2], col)
+	duVertex(dd, bmax[0], y, bmax[2], col)
+	duVertex(dd, bmax[0], y, bmax[2], col)
+	duVertex(dd, bmin[0], y, bmax[2], col)
+	duVertex(dd, bmin[0], y, bmax[2], col)
+	duVertex(dd, bmin[0], y, bmin[2], col)
+	dd.End()
+}
+
+/// Draws a single polygon of the navigation mesh.
+///  @param[in]		dd		The debug draw to draw with.
+///  @param[in]		mesh	The navigation mesh.
+///  @param[in]		ref		The reference id of the polygon to draw.
+///  @param[in]		col		The color of the polygon. The alpha is overridden.
+func DuDebugDrawNavMeshPoly(dd DuDebugDraw, mesh *detour.DtNavMesh, ref detour.DtPolyRef, col uint32) {
+	var tile *detour.DtMeshTile
+	var poly *detour.DtPoly
+	if detour.DtStatusFailed(mesh.GetTileAndPolyByRef(ref, &tile, &poly)) {
+		return
+	}
+
+	c := (col & 0x00ffffff) | (64 << 24)
+	ip := mesh.DecodePolyIdPoly(ref)
+
+	if poly.GetType() == detour.DT_POLYTYPE_OFFMESH_CONNECTION {
+		con := &tile.OffMeshCons[ip-uint32(tile.Header.OffMeshBase)]
+
+		dd.Begin(DU_DRAW_LINES, 2.0)
+
+		// Connection arc.
+		var as0 float32
+		if (con.Flags & detour.DT_OFFMESH_CON_BIDIR) != 0 {
+			as0 = 0.6
+		}
+		DuAppendArc(dd, con.Pos[0], con.Pos[1], con.Pos[2], con.Pos[3], con.Pos[4], con.Pos[5], 0.25,
+			as0, 0.6, c)
+
+		dd.End()
+	} else {
+		pd := &tile.DetailMeshes[ip]
+
+		dd.Begin(DU_DRAW_TRIS, 1.0)
+		for i := 0; i < int(pd.TriCount); i++ {
+			t := tile.DetailTris[(pd.TriBase+uint32(i))*4:]
+			for j := 0; j < 3; j++ {
+				dd.Vertex(duGetDetailVert(tile, poly, pd, t[j]), c)
+			}
+		}
+		dd.End()
+	}
+}
+
+/// Draws the polygon corridor returned by dtNavMeshQuery::findPath.
+///  @param[in]		dd			The debug draw to draw with.
+///  @param[in]		mesh		The navigation mesh.
+///  @param[in]		path		The polygon corridor. [(polyRef) * @p pathCount]
+///  @param[in]		pathCount	The number of polygons in the corridor.
+///  @param[in]		col			The color of the corridor.
+func DuDebugDrawPathPolys(dd DuDebugDraw, mesh *detour.DtNavMesh, path []detour.DtPolyRef, pathCount int, col uint32) {
+	for i := 0; i < pathCount; i++ {
+		DuDebugDrawNavMeshPoly(dd, mesh, path[i], col)
+	}
+}
+
+/// Draws the straight path returned by dtNavMeshQuery::findStraightPath.
+///  @param[in]		dd					The debug draw to draw with.
+///  @param[in]		straightPath		The path points. [(x, y, z) * @p straightPathCount]
+///  @param[in]		straightPathFlags	The flags of the points, or nil. [(flags) * @p straightPathCount]
+///  @param[in]		straightPathCount	The number of points.
+///  @param[in]		col					The color of the path segments.
+func DuDebugDrawStraightPath(dd DuDebugDraw, straightPath []float32, straightPathFlags []detour.DtStraightPathFlags,
+	straightPathCount int, col uint32) {
+	if straightPathCount == 0 {
+		return
+	}
+
+	dd.Begin(DU_DRAW_LINES, 2.0)
+	for i := 0; i < straightPathCount-1; i++ {
+		var segCol uint32 = col
+		if straightPathFlags != nil && (straightPathFlags[i+1]&detour.DT_STRAIGHTPATH_OFFMESH_CONNECTION) != 0 {
+			segCol = DuRGBA(128, 96, 0, 220)
+		}
+		duVertex(dd, straightPath[i*3], straightPath[i*3+1]+0.4, straightPath[i*3+2], segCol)
+		duVertex(dd, straightPath[(i+1)*3], straightPath[(i+1)*3+1]+0.4, straightPath[(i+1)*3+2], segCol)
+	}
+	dd.End()
+
+	dd.Begin(DU_DRAW_POINTS, 6.0)
+	for i := 0; i < straightPathCount; i++ {
+		var ptCol uint32
+		var flags detour.DtStraightPathFlags
+		if straightPathFlags != nil {
+			flags = straightPathFlags[i]
+		}
+		if (flags & detour.DT_STRAIGHTPATH_START) != 0 {
+			ptCol = DuRGBA(128, 25, 0, 220)
+		} else if (flags & detour.DT_STRAIGHTPATH_END) != 0 {
+			ptCol = DuRGBA(0, 128, 25, 220)
+		} else if (flags & detour.DT_STRAIGHTPATH_OFFMESH_CONNECTION) != 0 {
+			ptCol = DuRGBA(128, 96, 0, 220)
+		} else {
+			ptCol = DuRGBA(64, 16, 0, 220)
+		}
+		duVertex(dd, straightPath[i*3], straightPath[i*3+1]+0.4, straightPath[i*3+2], ptCol)
+	}
+	dd.End()
+}
+
+/// Draws the search nodes left in the node pool of the query by the last
+/// search. Closed nodes are drawn in orange, nodes still in the open list in
+/// green, and every node is connected to its parent.
+///  @param[in]		dd		The debug draw to draw with.
+///  @param[in]		query	The query whose node pool is drawn.
+func DuDebugDrawNavMeshNodes(dd DuDebugDraw, query *detour.DtNavMeshQuery) {
+	pool := query.GetNodePool()
+	if pool == nil {
+		return
+	}
+
+	const off float32 = 0.5
+	closedCol := DuRGBA(255, 192, 0, 255)
+	openCol := DuRGBA(0, 192, 64, 255)
+
+	dd.Begin(DU_DRAW_POINTS, 4.0)
+	for i := uint32(1); i <= pool.GetNodeCount(); i++ {
+		node := pool.GetNodeAtIdx(i)
+		col := closedCol
+		if (node.Flags & detour.DT_NODE_OPEN) != 0 {
+			col = openCol
+		}
+		duVertex(dd, node.Pos[0], node.Pos[1]+off, node.Pos[2], col)
+	}
+	dd.End()
+
+	dd.Begin(DU_DRAW_LINES, 2.0)
+	for i := uint32(1); i <= pool.GetNodeCount(); i++ {
+		node := pool.GetNodeAtIdx(i)
+		if node.Pidx == 0 {
+			continue
+		}
+		parent := pool.GetNodeAtIdx(node.Pidx)
+		if parent == nil {
+			continue
+		}
+		col := DuRGBA(255, 192, 0, 128)
+		if (node.Flags & detour.DT_NODE_PARENT_DETACHED) != 0 {
+			col = DuRGBA(255, 64, 0, 128)
+		}
+		duVertex(dd, node.Pos[0], node.Pos[1]+off, node.Pos[2], col)
+		duVertex(dd, parent.Pos[0], parent.Pos[1]+off, parent.Pos[2], col)
+	}
+	dd.End()
+}
@@ -0,0 +1,89 @@
+package debugutils
+
+import (
+	detour "github.com/fananchong/recastnavigation-go/Detour"
+)
+
+/// Flat geometry gathered from a navigation mesh, shared by the exporters.
+/// Every primitive owns its vertices so that each one can carry the color of
+/// its polygon's area.
+type duNavMeshGeometry struct {
+	polyVerts   []float32 ///< Polygon vertices. [(x, y, z) * n]
+	polyColors  []uint32  ///< Polygon vertex colors. [n]
+	polyFaces   [][]int   ///< Polygon faces, indices into polyVerts.
+	polyAreas   []uint8   ///< The area id of each polygon face.
+	detailVerts []float32 ///< Detail triangle vertices. [(x, y, z) * 3 * ntris]
+	detailCols  []uint32  ///< Detail triangle vertex colors. [3 * ntris]
+	detailAreas []uint8   ///< The area id of each detail triangle.
+	linkVerts   []float32 ///< Off-mesh link end points. [(ax, ay, az, bx, by, bz) * nlinks]
+	linkCols    []uint32  ///< Off-mesh link vertex colors. [2 * nlinks]
+	linkAreas   []uint8   ///< The area id of each off-mesh link.
+}
+
+func (this *duNavMeshGeometry) addPolyVert(v []float32, col uint32) int {
+	idx := len(this.polyColors)
+	this.polyVerts = append(this.polyVerts, v[0], v[1], v[2])
+	this.polyColors = append(this.polyColors, col)
+	return idx
+}
+
+/// Returns the position of the detail triangle vertex @p t of @p poly.
+func duGetDetailVert(tile *detour.DtMeshTile, poly *detour.DtPoly, pd *detour.DtPolyDetail, t uint8) []float32 {
+	if t < poly.VertCount {
+		return tile.Verts[poly.Verts[t]*3:]
+	}
+	return tile.DetailVerts[(pd.VertBase+uint32(t-poly.VertCount))*3:]
+}
+
+func duGatherTile(geom *duNavMeshGeometry, tile *detour.DtMeshTile) {
+	for i := 0; i < int(tile.Header.PolyCount); i++ {
+		p := &tile.Polys[i]
+		if p.GetType() == detour.DT_POLYTYPE_OFFMESH_CONNECTION {
+			continue
+		}
+		area := p.GetArea()
+		col := DuAreaToCol(area)
+
+		face := make([]int, p.VertCount)
+		for j := 0; j < int(p.VertCount); j++ {
+			face[j] = geom.addPolyVert(tile.Verts[p.Verts[j]*3:], col)
+		}
+		geom.polyFaces = append(geom.polyFaces, face)
+		geom.polyAreas = append(geom.polyAreas, area)
+
+		if tile.DetailMeshes == nil {
+			continue
+		}
+		pd := &tile.DetailMeshes[i]
+		for j := 0; j < int(pd.TriCount); j++ {
+			t := tile.DetailTris[(pd.TriBase+uint32(j))*4:]
+			for k := 0; k < 3; k++ {
+				v := duGetDetailVert(tile, p, pd, t[k])
+				geom.detailVerts = append(geom.detailVerts, v[0], v[1], v[2])
+				geom.detailCols = append(geom.detailCols, col)
+			}
+			geom.detailAreas = append(geom.detailAreas, area)
+		}
+	}
+
+	for i := 0; i < int(tile.Header.OffMeshConCount); i++ {
+		con := &tile.OffMeshCons[i]
+		area := tile.Polys[con.Poly].GetArea()
+		col := DuAreaToCol(area)
+		geom.linkVerts = append(geom.linkVerts, con.Pos[:]...)
+		geom.linkCols = append(geom.linkCols, col, col)
+		geom.linkAreas = append(geom.linkAreas, area)
+	}
+}
+
+func duGatherNavMesh(mesh *detour.DtNavMesh) *duNavMeshGeometry {
+	geom := &duNavMeshGeometry{}
+	for i := 0; i < int(mesh.GetMaxTiles()); i++ {
+		tile := mesh.GetTile(i)
+		if tile == nil || tile.Header == nil {
+			continue
+		}
+		duGatherTile(geom, tile)
+	}
+	return geom
+}
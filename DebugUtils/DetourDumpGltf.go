@@ -0,0 +1,209 @@
+package debugutils
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"math"
+
+	detour "github.com/fananchong/recastnavigation-go/Detour"
+)
+
+const (
+	gltfFloat        = 5126
+	gltfUnsignedInt  = 5125
+	gltfArrayBuffer  = 34962
+	gltfElementArray = 34963
+	gltfModeLines    = 1
+	gltfModeTris     = 4
+)
+
+type gltfAsset struct {
+	Version   string `json:"version"`
+	Generator string `json:"generator,omitempty"`
+}
+
+type gltfScene struct {
+	Nodes []int `json:"nodes"`
+}
+
+type gltfNode struct {
+	Name string `json:"name,omitempty"`
+	Mesh int    `json:"mesh"`
+}
+
+type gltfPrimitive struct {
+	Attributes map[string]int `json:"attributes"`
+	Indices    *int           `json:"indices,omitempty"`
+	Mode       int            `json:"mode"`
+}
+
+type gltfMesh struct {
+	Name       string          `json:"name,omitempty"`
+	Primitives []gltfPrimitive `json:"primitives"`
+}
+
+type gltfBuffer struct {
+	ByteLength int    `json:"byteLength"`
+	URI        string `json:"uri"`
+}
+
+type gltfBufferView struct {
+	Buffer     int `json:"buffer"`
+	ByteOffset int `json:"byteOffset"`
+	ByteLength int `json:"byteLength"`
+	Target     int `json:"target,omitempty"`
+}
+
+type gltfAccessor struct {
+	BufferView    int       `json:"bufferView"`
+	ComponentType int       `json:"componentType"`
+	Count         int       `json:"count"`
+	Type          string    `json:"type"`
+	Min           []float32 `json:"min,omitempty"`
+	Max           []float32 `json:"max,omitempty"`
+}
+
+type gltfDocument struct {
+	Asset       gltfAsset        `json:"asset"`
+	Scene       int              `json:"scene"`
+	Scenes      []gltfScene      `json:"scenes"`
+	Nodes       []gltfNode       `json:"nodes,omitempty"`
+	Meshes      []gltfMesh       `json:"meshes,omitempty"`
+	Buffers     []gltfBuffer     `json:"buffers,omitempty"`
+	BufferViews []gltfBufferView `json:"bufferViews,omitempty"`
+	Accessors   []gltfAccessor   `json:"accessors,omitempty"`
+}
+
+type gltfBuilder struct {
+	doc gltfDocument
+	bin []byte
+}
+
+func (this *gltfBuilder) addView(data []byte, target int) int {
+	this.doc.BufferViews = append(this.doc.BufferViews, gltfBufferView{
+		Buffer:     0,
+		ByteOffset: len(this.bin),
+		ByteLength: len(data),
+		Target:     target,
+	})
+	this.bin = append(this.bin, data...)
+	return len(this.doc.BufferViews) - 1
+}
+
+func (this *gltfBuilder) addAccessor(acc gltfAccessor) int {
+	this.doc.Accessors = append(this.doc.Accessors, acc)
+	return len(this.doc.Accessors) - 1
+}
+
+func (this *gltfBuilder) addPositions(verts []float32) int {
+	n := len(verts) / 3
+	data := make([]byte, len(verts)*4)
+	bmin := []float32{math.MaxFloat32, math.MaxFloat32, math.MaxFloat32}
+	bmax := []float32{-math.MaxFloat32, -math.MaxFloat32, -math.MaxFloat32}
+	for i := 0; i < n; i++ {
+		detour.DtVmin(bmin, verts[i*3:])
+		detour.DtVmax(bmax, verts[i*3:])
+	}
+	for i, v := range verts {
+		binary.LittleEndian.PutUint32(data[i*4:], math.Float32bits(v))
+	}
+	view := this.addView(data, gltfArrayBuffer)
+	return this.addAccessor(gltfAccessor{
+		BufferView:    view,
+		ComponentType: gltfFloat,
+		Count:         n,
+		Type:          "VEC3",
+		Min:           bmin,
+		Max:           bmax,
+	})
+}
+
+func (this *gltfBuilder) addColors(cols []uint32) int {
+	data := make([]byte, len(cols)*16)
+	var c [4]float32
+	for i, col := range cols {
+		DuColToRGBAf(col, c[:])
+		for k := 0; k < 4; k++ {
+			binary.LittleEndian.PutUint32(data[i*16+k*4:], math.Float32bits(c[k]))
+		}
+	}
+	view := this.addView(data, gltfArrayBuffer)
+	return this.addAccessor(gltfAccessor{
+		BufferView:    view,
+		ComponentType: gltfFloat,
+		Count:         len(cols),
+		Type:          "VEC4",
+	})
+}
+
+func (this *gltfBuilder) addIndices(indices []uint32) int {
+	data := make([]byte, len(indices)*4)
+	for i, idx := range indices {
+		binary.LittleEndian.PutUint32(data[i*4:], idx)
+	}
+	view := this.addView(data, gltfElementArray)
+	return this.addAccessor(gltfAccessor{
+		BufferView:    view,
+		ComponentType: gltfUnsignedInt,
+		Count:         len(indices),
+		Type:          "SCALAR",
+	})
+}
+
+func (this *gltfBuilder) addMesh(name string, verts []float32, cols []uint32, indices []uint32, mode int) {
+	if len(cols) == 0 {
+		return
+	}
+	prim := gltfPrimitive{
+		Attributes: map[string]int{
+			"POSITION": this.addPositions(verts),
+			"COLOR_0":  this.addColors(cols),
+		},
+		Mode: mode,
+	}
+	if indices != nil {
+		idx := this.addIndices(indices)
+		prim.Indices = &idx
+	}
+	this.doc.Meshes = append(this.doc.Meshes, gltfMesh{Name: name, Primitives: []gltfPrimitive{prim}})
+	this.doc.Nodes = append(this.doc.Nodes, gltfNode{Name: name, Mesh: len(this.doc.Meshes) - 1})
+	this.doc.Scenes[0].Nodes = append(this.doc.Scenes[0].Nodes, len(this.doc.Nodes)-1)
+}
+
+/// Writes the navigation mesh as a self contained glTF 2.0 (.gltf) file.
+/// The polygons (triangulated as fans), the detail triangles and the off-mesh
+/// links (as line primitives) are written as separate nodes, colored by area
+/// id through the COLOR_0 vertex attribute. The binary buffer is embedded as
+/// a base64 data uri.
+///  @param[in]		w		The writer to write to.
+///  @param[in]		mesh	The navigation mesh to dump.
+/// @return The first write error, if any.
+func DumpNavMeshGLTF(w io.Writer, mesh *detour.DtNavMesh) error {
+	geom := duGatherNavMesh(mesh)
+
+	b := &gltfBuilder{}
+	b.doc.Asset = gltfAsset{Version: "2.0", Generator: "recastnavigation-go"}
+	b.doc.Scenes = []gltfScene{{Nodes: []int{}}}
+
+	var polyIndices []uint32
+	for _, face := range geom.polyFaces {
+		for j := 2; j < len(face); j++ {
+			polyIndices = append(polyIndices, uint32(face[0]), uint32(face[j-1]), uint32(face[j]))
+		}
+	}
+	b.addMesh("polys", geom.polyVerts, geom.polyColors, polyIndices, gltfModeTris)
+	b.addMesh("detail", geom.detailVerts, geom.detailCols, nil, gltfModeTris)
+	b.addMesh("offmesh", geom.linkVerts, geom.linkCols, nil, gltfModeLines)
+
+	if len(b.bin) > 0 {
+		b.doc.Buffers = []gltfBuffer{{
+			ByteLength: len(b.bin),
+			URI:        "data:application/octet-stream;base64," + base64.StdEncoding.EncodeToString(b.bin),
+		}}
+	}
+
+	enc := json.NewEncoder(w)
+	return enc.Encode(&b.doc)
+}
@@ -0,0 +1,86 @@
+package debugutils
+
+import (
+	"encoding/json"
+	"io"
+
+	detour "github.com/fananchong/recastnavigation-go/Detour"
+)
+
+/// Describes a search node left in a node pool.
+type DuNodeInfo struct {
+	Index     uint32           `json:"index"`     ///< The 1-based index of the node in the pool.
+	Ref       detour.DtPolyRef `json:"ref"`       ///< The polygon reference of the node.
+	State     uint8            `json:"state"`     ///< The extra state of the node. (E.g. The tile side it was entered from.)
+	Pos       [3]float32       `json:"pos"`       ///< The position of the node.
+	Cost      float32          `json:"cost"`      ///< The cost from the start to the node.
+	Total     float32          `json:"total"`     ///< The cost plus heuristic of the node.
+	Parent    uint32           `json:"parent"`    ///< The index of the parent node, or zero for the start node.
+	ParentRef detour.DtPolyRef `json:"parentRef"` ///< The polygon reference of the parent node, or zero.
+	Open      bool             `json:"open"`      ///< The node is in the open list.
+	Closed    bool             `json:"closed"`    ///< The node has been expanded.
+	Detached  bool             `json:"detached"`  ///< The parent is not adjacent. (Found using a raycast.)
+}
+
+/// Summary of the node pool of a query.
+type DuNodePoolInfo struct {
+	MaxNodes  uint32       `json:"maxNodes"`  ///< The capacity of the pool.
+	NodeCount uint32       `json:"nodeCount"` ///< The number of nodes used by the last search.
+	Open      int          `json:"open"`      ///< The number of nodes still in the open list.
+	Closed    int          `json:"closed"`    ///< The number of expanded nodes.
+	Nodes     []DuNodeInfo `json:"nodes"`
+}
+
+/// Collects the search nodes left in the node pool of the query by the last
+/// search. If the pool is full (NodeCount == MaxNodes) the search most likely
+/// ran out of nodes.
+///  @param[in]		query	The query whose node pool is collected.
+/// @return The node pool information, or nil if the query has no node pool.
+func DuGetNavMeshNodes(query *detour.DtNavMeshQuery) *DuNodePoolInfo {
+	pool := query.GetNodePool()
+	if pool == nil {
+		return nil
+	}
+	info := &DuNodePoolInfo{
+		MaxNodes:  pool.GetMaxNodes(),
+		NodeCount: pool.GetNodeCount(),
+		Nodes:     make([]DuNodeInfo, 0, pool.GetNodeCount()),
+	}
+	for i := uint32(1); i <= pool.GetNodeCount(); i++ {
+		node := pool.GetNodeAtIdx(i)
+		n := DuNodeInfo{
+			Index:    i,
+			Ref:      node.Id,
+			State:    node.State,
+			Pos:      node.Pos,
+			Cost:     node.Cost,
+			Total:    node.Total,
+			Parent:   node.Pidx,
+			Open:     (node.Flags & detour.DT_NODE_OPEN) != 0,
+			Closed:   (node.Flags & detour.DT_NODE_CLOSED) != 0,
+			Detached: (node.Flags & detour.DT_NODE_PARENT_DETACHED) != 0,
+		}
+		if parent := pool.GetNodeAtIdx(node.Pidx); parent != nil {
+			n.ParentRef = parent.Id
+		}
+		if n.Open {
+			info.Open++
+		}
+		if n.Closed {
+			info.Closed++
+		}
+		info.Nodes = append(info.Nodes, n)
+	}
+	return info
+}
+
+/// Writes the search nodes left in the node pool of the query as JSON.
+/// (See: #DuGetNavMeshNodes)
+///  @param[in]		w		The writer to write to.
+///  @param[in]		query	The query whose node pool is dumped.
+/// @return The first write error, if any.
+func DuDumpNavMeshNodesJSON(w io.Writer, query *detour.DtNavMeshQuery) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(DuGetNavMeshNodes(query))
+}
@@ -0,0 +1,81 @@
+package debugutils
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+
+	detour "github.com/fananchong/recastnavigation-go/Detour"
+)
+
+func duWriteObjVert(w *bufio.Writer, v []float32, col uint32) {
+	var c [4]float32
+	DuColToRGBAf(col, c[:])
+	fmt.Fprintf(w, "v %f %f %f %.3f %.3f %.3f\n", v[0], v[1], v[2], c[0], c[1], c[2])
+}
+
+/// Writes the navigation mesh as a Wavefront OBJ file.
+/// The polygons, the detail triangles and the off-mesh links are written as
+/// separate objects. Vertices carry the area color as extended "v x y z r g b"
+/// records and faces are grouped per area with "usemtl area_<id>", so the mesh
+/// can be inspected in Blender and similar tools.
+///  @param[in]		w		The writer to write to.
+///  @param[in]		mesh	The navigation mesh to dump.
+/// @return The first write error, if any.
+func DumpNavMeshOBJ(w io.Writer, mesh *detour.DtNavMesh) error {
+	geom := duGatherNavMesh(mesh)
+	bw := bufio.NewWriter(w)
+
+	fmt.Fprintf(bw, "# Detour navmesh\n")
+	fmt.Fprintf(bw, "# polys: %d, detail tris: %d, off-mesh links: %d\n",
+		len(geom.polyFaces), len(geom.detailAreas), len(geom.linkAreas))
+
+	// OBJ indices are global and 1-based.
+	base := 1
+
+	fmt.Fprintf(bw, "o polys\n")
+	for i := 0; i < len(geom.polyColors); i++ {
+		duWriteObjVert(bw, geom.polyVerts[i*3:], geom.polyColors[i])
+	}
+	lastArea := -1
+	for i, face := range geom.polyFaces {
+		if int(geom.polyAreas[i]) != lastArea {
+			lastArea = int(geom.polyAreas[i])
+			fmt.Fprintf(bw, "usemtl area_%d\n", lastArea)
+		}
+		bw.WriteString("f")
+		for _, idx := range face {
+			fmt.Fprintf(bw, " %d", base+idx)
+		}
+		bw.WriteString("\n")
+	}
+	base += len(geom.polyColors)
+
+	if len(geom.detailAreas) > 0 {
+		fmt.Fprintf(bw, "o detail\n")
+		for i := 0; i < len(geom.detailCols); i++ {
+			duWriteObjVert(bw, geom.detailVerts[i*3:], geom.detailCols[i])
+		}
+		lastArea = -1
+		for i := 0; i < len(geom.detailAreas); i++ {
+			if int(geom.detailAreas[i]) != lastArea {
+				lastArea = int(geom.detailAreas[i])
+				fmt.Fprintf(bw, "usemtl area_%d\n", lastArea)
+			}
+			fmt.Fprintf(bw, "f %d %d %d\n", base+i*3, base+i*3+1, base+i*3+2)
+		}
+		base += len(geom.detailCols)
+	}
+
+	if len(geom.linkAreas) > 0 {
+		fmt.Fprintf(bw, "o offmesh\n")
+		for i := 0; i < len(geom.linkCols); i++ {
+			duWriteObjVert(bw, geom.linkVerts[i*3:], geom.linkCols[i])
+		}
+		for i := 0; i < len(geom.linkAreas); i++ {
+			fmt.Fprintf(bw, "l %d %d\n", base+i*2, base+i*2+1)
+		}
+	}
+
+	return bw.Flush()
+}
@@ -0,0 +1,317 @@
+package detour
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+/// The number of polygon flag bits. (See #DtPoly.Flags)
+const DT_MAX_FLAGS int = 16
+
+/// Configures an area of a #DtAreaRegistry.
+type DtAreaConfig struct {
+	Id    int      `json:"id" yaml:"id"`       ///< The area id. [Limit: < #DT_MAX_AREAS]
+	Flags []string `json:"flags" yaml:"flags"` ///< The names of the flags given to the polygons of the area. [opt]
+}
+
+/// Configures a filter preset of a #DtAreaRegistry.
+type DtFilterPresetConfig struct {
+	Include   []string           `json:"include" yaml:"include"`     ///< The names of the include flags. All flags are included when empty.
+	Exclude   []string           `json:"exclude" yaml:"exclude"`     ///< The names of the exclude flags. [opt]
+	AreaCosts map[string]float32 `json:"areaCosts" yaml:"areaCosts"` ///< The cost of the areas by name, the others cost 1. [opt]
+}
+
+/// Configures a #DtAreaRegistry. (See #DtAreaRegistry.LoadConfig)
+///
+/// The registry only decodes JSON itself, with #DtAreaRegistry.LoadJSON, so
+/// that this package keeps depending on the standard library alone. The fields
+/// also have yaml tags: to load a YAML file, decode it into this type with a
+/// YAML package honouring them and pass the result to #DtAreaRegistry.LoadConfig.
+type DtAreaRegistryConfig struct {
+	Areas   map[string]DtAreaConfig         `json:"areas" yaml:"areas"`     ///< The areas by name.
+	Flags   map[string]int                  `json:"flags" yaml:"flags"`     ///< The flag bit by name. [Limit: < #DT_MAX_FLAGS]
+	Filters map[string]DtFilterPresetConfig `json:"filters" yaml:"filters"` ///< The filter presets by name.
+}
+
+/// Names the area ids and the flag bits of the polygons, and keeps filter
+/// presets built from them.
+///
+/// The areas also hold the flags given to their polygons, so the mesh process
+/// of a tile cache can set the polygon flags from the areas. (See #DtAreaRegistry.GetAreaFlags)
+/// @ingroup detour
+type DtAreaRegistry struct {
+	m_areaNames [DT_MAX_AREAS]string     ///< The name of each area, empty if not named.
+	m_areaFlags [DT_MAX_AREAS]uint16     ///< The flags given to the polygons of each area.
+	m_flagNames [DT_MAX_FLAGS]string     ///< The name of each flag bit, empty if not named.
+	m_presets   map[string]DtQueryFilter ///< The filter presets by name.
+}
+
+/// Allocates an empty registry.
+func DtAllocAreaRegistry() *DtAreaRegistry {
+	registry := &DtAreaRegistry{}
+	registry.Clear()
+	return registry
+}
+
+/// Frees the registry.
+///  @param[in]	registry	A registry allocated using #DtAllocAreaRegistry
+func DtFreeAreaRegistry(registry *DtAreaRegistry) {
+	if registry == nil {
+		return
+	}
+	registry.m_presets = nil
+}
+
+/// Removes all the names and presets.
+func (this *DtAreaRegistry) Clear() {
+	this.m_areaNames = [DT_MAX_AREAS]string{}
+	this.m_areaFlags = [DT_MAX_AREAS]uint16{}
+	this.m_flagNames = [DT_MAX_FLAGS]string{}
+	this.m_presets = make(map[string]DtQueryFilter)
+}
+
+/// Names an area.
+///  @param[in]	id		The area id. [Limit: < #DT_MAX_AREAS]
+///  @param[in]	name	The name of the area, unique among the areas. Empty removes the name.
+///  @param[in]	flags	The flags given to the polygons of the area.
+/// @return The status flags for the operation.
+func (this *DtAreaRegistry) SetArea(id uint8, name string, flags uint16) DtStatus {
+	if int(id) >= DT_MAX_AREAS {
+		return DT_FAILURE | DT_INVALID_PARAM
+	}
+	var other uint8
+	if name != "" && this.FindArea(name, &other) && other != id {
+		return DT_FAILURE | DT_INVALID_PARAM
+	}
+	this.m_areaNames[id] = name
+	this.m_areaFlags[id] = flags
+	return DT_SUCCESS
+}
+
+/// Returns the name of the area, or an empty string if it is not named.
+///  @param[in]	id		The area id.
+func (this *DtAreaRegistry) GetAreaName(id uint8) string {
+	if int(id) >= DT_MAX_AREAS {
+		return ""
+	}
+	return this.m_areaNames[id]
+}
+
+/// Returns the flags given to the polygons of the area.
+///  @param[in]	id		The area id.
+func (this *DtAreaRegistry) GetAreaFlags(id uint8) uint16 {
+	if int(id) >= DT_MAX_AREAS {
+		return 0
+	}
+	return this.m_areaFlags[id]
+}
+
+/// Finds an area by name.
+///  @param[in]		name	The name of the area.
+///  @param[out]	id		The area id.
+/// @return True if the area was found.
+func (this *DtAreaRegistry) FindArea(name string, id *uint8) bool {
+	if name == "" {
+		return false
+	}
+	for i := range this.m_areaNames {
+		if this.m_areaNames[i] == name {
+			*id = uint8(i)
+			return true
+		}
+	}
+	return false
+}
+
+/// Names a flag bit.
+///  @param[in]	bit		The index of the bit. [Limit: < #DT_MAX_FLAGS]
+///  @param[in]	name	The name of the flag, unique among the flags. Empty removes the name.
+/// @return The status flags for the operation.
+func (this *DtAreaRegistry) SetFlag(bit uint, name string) DtStatus {
+	if bit >= uint(DT_MAX_FLAGS) {
+		return DT_FAILURE | DT_INVALID_PARAM
+	}
+	var other uint16
+	if name != "" && this.FindFlag(name, &other) && other != 1<<bit {
+		return DT_FAILURE | DT_INVALID_PARAM
+	}
+	this.m_flagNames[bit] = name
+	return DT_SUCCESS
+}
+
+/// Returns the name of the flag bit, or an empty string if it is not named.
+///  @param[in]	bit		The index of the bit.
+func (this *DtAreaRegistry) GetFlagName(bit uint) string {
+	if bit >= uint(DT_MAX_FLAGS) {
+		return ""
+	}
+	return this.m_flagNames[bit]
+}
+
+/// Finds a flag by name.
+///  @param[in]		name	The name of the flag.
+///  @param[out]	flag	The flag. (The bit, not its index.)
+/// @return True if the flag was found.
+func (this *DtAreaRegistry) FindFlag(name string, flag *uint16) bool {
+	if name == "" {
+		return false
+	}
+	for i := range this.m_flagNames {
+		if this.m_flagNames[i] == name {
+			*flag = 1 << uint(i)
+			return true
+		}
+	}
+	return false
+}
+
+/// Combines the named flags.
+///  @param[in]		names	The names of the flags.
+///  @param[out]	flags	The combined flags.
+/// @return The status flags for the operation. Fails if a flag is not named.
+func (this *DtAreaRegistry) GetFlags(names []string, flags *uint16) DtStatus {
+	*flags = 0
+	for _, name := range names {
+		var flag uint16
+		if !this.FindFlag(name, &flag) {
+			return DT_FAILURE | DT_INVALID_PARAM
+		}
+		*flags |= flag
+	}
+	return DT_SUCCESS
+}
+
+/// Stores a copy of the filter as a preset.
+///  @param[in]	name	The name of the preset.
+///  @param[in]	filter	The filter, or null to remove the preset.
+func (this *DtAreaRegistry) SetFilterPreset(name string, filter *DtQueryFilter) {
+	if filter == nil {
+		delete(this.m_presets, name)
+		return
+	}
+	this.m_presets[name] = *filter
+}
+
+/// Returns a new filter with the settings of the preset, or null if there is no such preset.
+///  @param[in]	name	The name of the preset.
+/// @par
+///
+/// Each call returns a new filter, changing it does not change the preset.
+func (this *DtAreaRegistry) GetFilterPreset(name string) *DtQueryFilter {
+	preset, ok := this.m_presets[name]
+	if !ok {
+		return nil
+	}
+	filter := preset
+	return &filter
+}
+
+/// Returns the names of the filter presets, sorted.
+func (this *DtAreaRegistry) GetFilterPresetNames() []string {
+	names := make([]string, 0, len(this.m_presets))
+	for name := range this.m_presets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+/// Replaces the names and presets of the registry with the configured ones.
+///  @param[in]	config	The configuration.
+/// @return An error naming the first invalid setting, in which case the registry is left unchanged.
+func (this *DtAreaRegistry) LoadConfig(config *DtAreaRegistryConfig) error {
+	loaded := DtAllocAreaRegistry()
+
+	// The names are sorted, so the same invalid configuration always gives the same error.
+	flagNames := make([]string, 0, len(config.Flags))
+	for name := range config.Flags {
+		flagNames = append(flagNames, name)
+	}
+	sort.Strings(flagNames)
+	for _, name := range flagNames {
+		bit := config.Flags[name]
+		if name == "" || bit < 0 || bit >= DT_MAX_FLAGS {
+			return fmt.Errorf("flag %q: invalid bit %d", name, bit)
+		}
+		if loaded.m_flagNames[bit] != "" {
+			return fmt.Errorf("flag %q: bit %d is already named %q", name, bit, loaded.m_flagNames[bit])
+		}
+		loaded.m_flagNames[bit] = name
+	}
+
+	areaNames := make([]string, 0, len(config.Areas))
+	for name := range config.Areas {
+		areaNames = append(areaNames, name)
+	}
+	sort.Strings(areaNames)
+	for _, name := range areaNames {
+		area := config.Areas[name]
+		if name == "" || area.Id < 0 || area.Id >= DT_MAX_AREAS {
+			return fmt.Errorf("area %q: invalid id %d", name, area.Id)
+		}
+		if loaded.m_areaNames[area.Id] != "" {
+			return fmt.Errorf("area %q: id %d is already named %q", name, area.Id, loaded.m_areaNames[area.Id])
+		}
+		var flags uint16
+		if DtStatusFailed(loaded.GetFlags(area.Flags, &flags)) {
+			return fmt.Errorf("area %q: unknown flag in %q", name, area.Flags)
+		}
+		loaded.m_areaNames[area.Id] = name
+		loaded.m_areaFlags[area.Id] = flags
+	}
+
+	filterNames := make([]string, 0, len(config.Filters))
+	for name := range config.Filters {
+		filterNames = append(filterNames, name)
+	}
+	sort.Strings(filterNames)
+	for _, name := range filterNames {
+		preset := config.Filters[name]
+		filter := DtAllocDtQueryFilter()
+		if len(preset.Include) > 0 {
+			var flags uint16
+			if DtStatusFailed(loaded.GetFlags(preset.Include, &flags)) {
+				return fmt.Errorf("filter %q: unknown include flag in %q", name, preset.Include)
+			}
+			filter.SetIncludeFlags(flags)
+		}
+		var flags uint16
+		if DtStatusFailed(loaded.GetFlags(preset.Exclude, &flags)) {
+			return fmt.Errorf("filter %q: unknown exclude flag in %q", name, preset.Exclude)
+		}
+		filter.SetExcludeFlags(flags)
+		costNames := make([]string, 0, len(preset.AreaCosts))
+		for areaName := range preset.AreaCosts {
+			costNames = append(costNames, areaName)
+		}
+		sort.Strings(costNames)
+		for _, areaName := range costNames {
+			cost := preset.AreaCosts[areaName]
+			var id uint8
+			if !loaded.FindArea(areaName, &id) {
+				return fmt.Errorf("filter %q: unknown area %q", name, areaName)
+			}
+			if !(cost >= 0) {
+				return fmt.Errorf("filter %q: invalid cost %f of area %q", name, cost, areaName)
+			}
+			filter.SetAreaCost(int(id), cost)
+		}
+		loaded.m_presets[name] = *filter
+	}
+
+	*this = *loaded
+	return nil
+}
+
+/// Replaces the names and presets of the registry with the ones of a JSON configuration.
+/// YAML is not supported, see #DtAreaRegistryConfig for how to load it.
+///  @param[in]	data	The JSON configuration.
+/// @return An error if the configuration cannot be decoded or is invalid, in which case the registry is left unchanged.
+func (this *DtAreaRegistry) LoadJSON(data []byte) error {
+	var config DtAreaRegistryConfig
+	if err := json.Unmarshal(data, &config); err != nil {
+		return err
+	}
+	return this.LoadConfig(&config)
+}
@@ -0,0 +1,407 @@
+package detour
+
+import "math"
+
+/// Prepares the backward search of a bidirectional sliced path query.
+/// The backward node pool and open list are allocated on first use with the
+/// size of the forward ones.
+func (this *DtNavMeshQuery) initBackwardSearch() DtStatus {
+	maxNodes := this.m_nodePool.GetMaxNodes()
+	if this.m_backNodePool == nil || this.m_backNodePool.GetMaxNodes() < maxNodes {
+		if this.m_backNodePool != nil {
+			DtFreeNodePool(this.m_backNodePool)
+			this.m_backNodePool = nil
+		}
+		this.m_backNodePool = DtAllocNodePool(maxNodes, DtNextPow2(maxNodes/4))
+		if this.m_backNodePool == nil {
+			return DT_FAILURE | DT_OUT_OF_MEMORY
+		}
+	} else {
+		this.m_backNodePool.Clear()
+	}
+	if this.m_backOpenList == nil || this.m_backOpenList.GetCapacity() < int(maxNodes) {
+		if this.m_backOpenList != nil {
+			DtFreeNodeQueue(this.m_backOpenList)
+			this.m_backOpenList = nil
+		}
+		this.m_backOpenList = DtAllocNodeQueue(int(maxNodes))
+		if this.m_backOpenList == nil {
+			return DT_FAILURE | DT_OUT_OF_MEMORY
+		}
+	} else {
+		this.m_backOpenList.Clear()
+	}
+
+	endNode := this.m_backNodePool.GetNode(this.m_query.endRef, 0)
+	DtVcopy(endNode.Pos[:], this.m_query.endPos[:])
+	endNode.Pidx = 0
+	endNode.Cost = 0
+	endNode.Total = -this.bidirectionalPotential(this.m_query.endPos[:])
+	endNode.Id = this.m_query.endRef
+	endNode.Flags = DT_NODE_OPEN
+	this.m_backOpenList.Push(endNode)
+
+	this.m_query.meetCost = float32(math.MaxFloat32)
+	this.m_query.meetNode = nil
+	this.m_query.meetBackNode = nil
+
+	// Both searches share the average potential.
+	startNode := this.m_nodePool.FindNode(this.m_query.startRef, 0)
+	startNode.Total = this.bidirectionalPotential(this.m_query.startPos[:])
+	this.m_openList.Modify(startNode)
+
+	return DT_SUCCESS
+}
+
+/// Returns the potential of a position for the forward search, the backward
+/// search uses its negation. Averaging the distances to both ends keeps the
+/// potentials consistent with each other, so the searches can stop as soon as
+/// their best open nodes together cannot beat the best path found.
+func (this *DtNavMeshQuery) bidirectionalPotential(pos []float32) float32 {
+	return DtMathMulf((DtVdist(pos, this.m_query.endPos[:])-DtVdist(pos, this.m_query.startPos[:]))*0.5, H_SCALE)
+}
+
+/// Updates a bidirectional sliced path query.
+/// @par
+///
+/// The forward search runs from the start towards the end like #FindPath,
+/// the backward search runs from the end towards the start following the
+/// links in reverse. One-way off-mesh connections are only followed in their
+/// own direction by both searches. The side whose best open node is cheaper
+/// is expanded each iteration, and the search stops when the best open nodes
+/// of both sides cannot lead to a cheaper path than the best one where the two
+/// searches met.
+func (this *DtNavMeshQuery) updateSlicedFindPathBidirectional(maxIter int, doneIters *int) DtStatus {
+	iter := 0
+	done := false
+	for iter < maxIter && !done {
+		if this.m_openList.Empty() || this.m_backOpenList.Empty() {
+			done = true
+			break
+		}
+		iter++
+
+		// No open node can lead to a cheaper path, stop searching.
+		forwardTotal := this.m_openList.Top().Total
+		backwardTotal := this.m_backOpenList.Top().Total
+		if forwardTotal+backwardTotal >= this.m_query.meetCost {
+			done = true
+			break
+		}
+
+		forward := forwardTotal <= backwardTotal
+		var bestNode *DtNode
+		if forward {
+			bestNode = this.m_openList.Pop()
+		} else {
+			bestNode = this.m_backOpenList.Pop()
+		}
+		bestNode.Flags &= ^DT_NODE_OPEN
+		bestNode.Flags |= DT_NODE_CLOSED
+
+		var status DtStatus
+		if forward {
+			status = this.expandForward(bestNode)
+		} else {
+			status = this.expandBackward(bestNode)
+		}
+		if DtStatusFailed(status) {
+			// The polygon has disappeared during the sliced query, fail.
+			this.m_query.status = DT_FAILURE
+			if doneIters != nil {
+				*doneIters = iter
+			}
+			return this.m_query.status
+		}
+	}
+
+	if done || this.m_openList.Empty() || this.m_backOpenList.Empty() {
+		// The searches met or ran out of nodes to expand.
+		details := this.m_query.status & DT_STATUS_DETAIL_MASK
+		this.m_query.status = DT_SUCCESS | details
+	}
+
+	if doneIters != nil {
+		*doneIters = iter
+	}
+	return this.m_query.status
+}
+
+/// Expands a node of the forward search.
+func (this *DtNavMeshQuery) expandForward(bestNode *DtNode) DtStatus {
+	filter := this.m_query.filter
+
+	bestRef := bestNode.Id
+	var bestTile *DtMeshTile
+	var bestPoly *DtPoly
+	if DtStatusFailed(this.m_nav.GetTileAndPolyByRef(bestRef, &bestTile, &bestPoly)) {
+		return DT_FAILURE
+	}
+	var parentRef DtPolyRef
+	var parentTile *DtMeshTile
+	var parentPoly *DtPoly
+	if bestNode.Pidx != 0 {
+		parentRef = this.m_nodePool.GetNodeAtIdx(bestNode.Pidx).Id
+	}
+	if parentRef != 0 && DtStatusFailed(this.m_nav.GetTileAndPolyByRef(parentRef, &parentTile, &parentPoly)) {
+		return DT_FAILURE
+	}
+
+	for i := bestPoly.FirstLink; i != DT_NULL_LINK; i = bestTile.Links[i].Next {
+		neighbourRef := bestTile.Links[i].Ref
+
+		// Skip invalid ids and do not expand back to where we came from.
+		if neighbourRef == 0 || neighbourRef == parentRef {
+			continue
+		}
+		var neighbourTile *DtMeshTile
+		var neighbourPoly *DtPoly
+		this.m_nav.GetTileAndPolyByRefUnsafe(neighbourRef, &neighbourTile, &neighbourPoly)
+
+		if !filter.PassFilter(neighbourRef, neighbourTile, neighbourPoly) {
+			continue
+		}
+		neighbourNode := this.m_nodePool.GetNode(neighbourRef, 0)
+		if neighbourNode == nil {
+			this.m_query.status |= DT_OUT_OF_NODES
+			continue
+		}
+
+		// If the node is visited the first time, calculate node position.
+		if neighbourNode.Flags == 0 {
+			this.getEdgeMidPoint2(bestRef, bestPoly, bestTile,
+				neighbourRef, neighbourPoly, neighbourTile,
+				neighbourNode.Pos[:])
+		}
+
+		curCost := filter.GetCost(bestNode.Pos[:], neighbourNode.Pos[:],
+			parentRef, parentTile, parentPoly,
+			bestRef, bestTile, bestPoly,
+			neighbourRef, neighbourTile, neighbourPoly)
+		cost := bestNode.Cost + curCost
+		total := cost + this.bidirectionalPotential(neighbourNode.Pos[:])
+
+		// The node is already visited and the new result is worse, skip.
+		if (neighbourNode.Flags&(DT_NODE_OPEN|DT_NODE_CLOSED)) != 0 && total >= neighbourNode.Total {
+			continue
+		}
+		// Add or update the node.
+		neighbourNode.Pidx = this.m_nodePool.GetNodeIdx(bestNode)
+		neighbourNode.Id = neighbourRef
+		neighbourNode.Flags = (neighbourNode.Flags & ^DT_NODE_CLOSED)
+		neighbourNode.Cost = cost
+		neighbourNode.Total = total
+		if (neighbourNode.Flags & DT_NODE_OPEN) != 0 {
+			this.m_openList.Modify(neighbourNode)
+		} else {
+			neighbourNode.Flags |= DT_NODE_OPEN
+			this.m_openList.Push(neighbourNode)
+		}
+
+		// Update nearest node to target so far.
+		heuristic := DtMathMulf(DtVdist(neighbourNode.Pos[:], this.m_query.endPos[:]), H_SCALE)
+		if heuristic < this.m_query.lastBestNodeCost {
+			this.m_query.lastBestNodeCost = heuristic
+			this.m_query.lastBestNode = neighbourNode
+		}
+
+		if backNode := this.m_backNodePool.FindNode(neighbourRef, 0); backNode != nil && backNode.Flags != 0 {
+			this.updateMeeting(neighbourNode, backNode)
+		}
+	}
+	return DT_SUCCESS
+}
+
+/// Expands a node of the backward search.
+/// The node position is the portal towards its successor, so the cost of
+/// reaching it from a predecessor is the cost of crossing the node polygon.
+func (this *DtNavMeshQuery) expandBackward(bestNode *DtNode) DtStatus {
+	filter := this.m_query.filter
+
+	bestRef := bestNode.Id
+	var bestTile *DtMeshTile
+	var bestPoly *DtPoly
+	if DtStatusFailed(this.m_nav.GetTileAndPolyByRef(bestRef, &bestTile, &bestPoly)) {
+		return DT_FAILURE
+	}
+	var succRef DtPolyRef
+	var succTile *DtMeshTile
+	var succPoly *DtPoly
+	if bestNode.Pidx != 0 {
+		succRef = this.m_backNodePool.GetNodeAtIdx(bestNode.Pidx).Id
+	}
+	if succRef != 0 && DtStatusFailed(this.m_nav.GetTileAndPolyByRef(succRef, &succTile, &succPoly)) {
+		return DT_FAILURE
+	}
+
+	expand := func(prevRef DtPolyRef) {
+		// Do not expand back to where we came from.
+		if prevRef == 0 || prevRef == succRef {
+			return
+		}
+		var prevTile *DtMeshTile
+		var prevPoly *DtPoly
+		this.m_nav.GetTileAndPolyByRefUnsafe(prevRef, &prevTile, &prevPoly)
+
+		if !filter.PassFilter(prevRef, prevTile, prevPoly) {
+			return
+		}
+		prevNode := this.m_backNodePool.GetNode(prevRef, 0)
+		if prevNode == nil {
+			this.m_query.status |= DT_OUT_OF_NODES
+			return
+		}
+
+		// If the node is visited the first time, calculate node position.
+		if prevNode.Flags == 0 {
+			this.getEdgeMidPoint2(prevRef, prevPoly, prevTile,
+				bestRef, bestPoly, bestTile,
+				prevNode.Pos[:])
+		}
+
+		curCost := filter.GetCost(prevNode.Pos[:], bestNode.Pos[:],
+			prevRef, prevTile, prevPoly,
+			bestRef, bestTile, bestPoly,
+			succRef, succTile, succPoly)
+		cost := bestNode.Cost + curCost
+		total := cost - this.bidirectionalPotential(prevNode.Pos[:])
+
+		// The node is already visited and the new result is worse, skip.
+		if (prevNode.Flags&(DT_NODE_OPEN|DT_NODE_CLOSED)) != 0 && total >= prevNode.Total {
+			return
+		}
+		// Add or update the node.
+		prevNode.Pidx = this.m_backNodePool.GetNodeIdx(bestNode)
+		prevNode.Id = prevRef
+		prevNode.Flags = (prevNode.Flags & ^DT_NODE_CLOSED)
+		prevNode.Cost = cost
+		prevNode.Total = total
+		if (prevNode.Flags & DT_NODE_OPEN) != 0 {
+			this.m_backOpenList.Modify(prevNode)
+		} else {
+			prevNode.Flags |= DT_NODE_OPEN
+			this.m_backOpenList.Push(prevNode)
+		}
+
+		if node := this.m_nodePool.FindNode(prevRef, 0); node != nil && node.Flags != 0 {
+			this.updateMeeting(node, prevNode)
+		}
+	}
+
+	// Neighbours which can move to this polygon.
+	for i := bestPoly.FirstLink; i != DT_NULL_LINK; i = bestTile.Links[i].Next {
+		prevRef := bestTile.Links[i].Ref
+		if prevRef != 0 && this.hasLinkTo(prevRef, bestRef) {
+			expand(prevRef)
+		}
+	}
+
+	// One-way off-mesh connections landing on this polygon are not linked back.
+	// Connection end points are linked to the neighbour tiles at most.
+	const MAX_NEIS = 32
+	var neis [MAX_NEIS]*DtMeshTile
+	for dy := int32(-1); dy <= 1; dy++ {
+		for dx := int32(-1); dx <= 1; dx++ {
+			nneis := this.m_nav.GetTilesAt(bestTile.Header.X+dx, bestTile.Header.Y+dy, neis[:], MAX_NEIS)
+			for j := 0; j < nneis; j++ {
+				tile := neis[j]
+				if tile.Header.OffMeshConCount == 0 {
+					continue
+				}
+				base := this.m_nav.GetPolyRefBase(tile)
+				for k := 0; k < int(tile.Header.OffMeshConCount); k++ {
+					con := &tile.OffMeshCons[k]
+					if (con.Flags & DT_OFFMESH_CON_BIDIR) != 0 {
+						continue
+					}
+					poly := &tile.Polys[con.Poly]
+					for l := poly.FirstLink; l != DT_NULL_LINK; l = tile.Links[l].Next {
+						if tile.Links[l].Edge == 1 && tile.Links[l].Ref == bestRef {
+							expand(base | DtPolyRef(con.Poly))
+							break
+						}
+					}
+				}
+			}
+		}
+	}
+	return DT_SUCCESS
+}
+
+/// Returns true if the polygon has a link to the other polygon.
+func (this *DtNavMeshQuery) hasLinkTo(from, to DtPolyRef) bool {
+	var tile *DtMeshTile
+	var poly *DtPoly
+	this.m_nav.GetTileAndPolyByRefUnsafe(from, &tile, &poly)
+	for i := poly.FirstLink; i != DT_NULL_LINK; i = tile.Links[i].Next {
+		if tile.Links[i].Ref == to {
+			return true
+		}
+	}
+	return false
+}
+
+/// Keeps the path through the polygon visited by both searches if it is
+/// the cheapest one found so far.
+func (this *DtNavMeshQuery) updateMeeting(node, backNode *DtNode) {
+	ref := node.Id
+	var tile *DtMeshTile
+	var poly *DtPoly
+	this.m_nav.GetTileAndPolyByRefUnsafe(ref, &tile, &poly)
+
+	var parentRef, succRef DtPolyRef
+	var parentTile, succTile *DtMeshTile
+	var parentPoly, succPoly *DtPoly
+	if node.Pidx != 0 {
+		parentRef = this.m_nodePool.GetNodeAtIdx(node.Pidx).Id
+		this.m_nav.GetTileAndPolyByRefUnsafe(parentRef, &parentTile, &parentPoly)
+	}
+	if backNode.Pidx != 0 {
+		succRef = this.m_backNodePool.GetNodeAtIdx(backNode.Pidx).Id
+		this.m_nav.GetTileAndPolyByRefUnsafe(succRef, &succTile, &succPoly)
+	}
+
+	cost := node.Cost + backNode.Cost + this.m_query.filter.GetCost(node.Pos[:], backNode.Pos[:],
+		parentRef, parentTile, parentPoly,
+		ref, tile, poly,
+		succRef, succTile, succPoly)
+	if cost < this.m_query.meetCost {
+		this.m_query.meetCost = cost
+		this.m_query.meetNode = node
+		this.m_query.meetBackNode = backNode
+	}
+}
+
+/// Stores the path where the two searches met. The forward half is stored
+/// from the start and the backward half follows it to the end.
+func (this *DtNavMeshQuery) getBidirectionalPath(path []DtPolyRef, pathCount *int, maxPath int) DtStatus {
+	// Find the length of the forward half.
+	length := 0
+	for node := this.m_query.meetNode; node != nil; node = this.m_nodePool.GetNodeAtIdx(node.Pidx) {
+		length++
+	}
+	// If the path cannot fit, its start is kept.
+	var status DtStatus
+	if length > maxPath {
+		status = DT_BUFFER_TOO_SMALL
+	}
+	i := length - 1
+	for node := this.m_query.meetNode; node != nil; node = this.m_nodePool.GetNodeAtIdx(node.Pidx) {
+		if i < maxPath {
+			path[i] = node.Id
+		}
+		i--
+	}
+	n := int(DtMinInt32(int32(length), int32(maxPath)))
+
+	for node := this.m_backNodePool.GetNodeAtIdx(this.m_query.meetBackNode.Pidx); node != nil; node = this.m_backNodePool.GetNodeAtIdx(node.Pidx) {
+		if n >= maxPath {
+			status = DT_BUFFER_TOO_SMALL
+			break
+		}
+		path[n] = node.Id
+		n++
+	}
+	*pathCount = n
+	return status
+}
@@ -173,7 +173,7 @@ func DtAbsInt16(a int16) int16 {
 /// Returns the square of the value.
 ///  @param[in]		a	The value.
 ///  @return The square of the value.
-func DtSqrFloat32(a float32) float32 { return a * a }
+func DtSqrFloat32(a float32) float32 { return DtMathMulf(a, a) }
 func DtSqrUInt32(a uint32) uint32    { return a * a }
 func DtSqrInt32(a int32) int32       { return a * a }
 func DtSqrUInt16(a uint16) uint16    { return a * a }
@@ -249,9 +249,9 @@ func DtClampInt16(v, mn, mx int16) int16 {
 ///  @param[in]		v1		A Vector [(x, y, z)]
 ///  @param[in]		v2		A vector [(x, y, z)]
 func DtVcross(dest, v1, v2 []float32) {
-	dest[0] = v1[1]*v2[2] - v1[2]*v2[1]
-	dest[1] = v1[2]*v2[0] - v1[0]*v2[2]
-	dest[2] = v1[0]*v2[1] - v1[1]*v2[0]
+	dest[0] = DtMathMulf(v1[1], v2[2]) - DtMathMulf(v1[2], v2[1])
+	dest[1] = DtMathMulf(v1[2], v2[0]) - DtMathMulf(v1[0], v2[2])
+	dest[2] = DtMathMulf(v1[0], v2[1]) - DtMathMulf(v1[1], v2[0])
 }
 
 /// Derives the dot product of two vectors. (@p v1 . @p v2)
@@ -259,7 +259,7 @@ func DtVcross(dest, v1, v2 []float32) {
 ///  @param[in]		v2	A vector [(x, y, z)]
 /// @return The dot product.
 func DtVdot(v1, v2 []float32) float32 {
-	return v1[0]*v2[0] + v1[1]*v2[1] + v1[2]*v2[2]
+	return DtMathMulf(v1[0], v2[0]) + DtMathMulf(v1[1], v2[1]) + DtMathMulf(v1[2], v2[2])
 }
 
 /// Performs a scaled vector addition. (@p v1 + (@p v2 * @p s))
@@ -268,9 +268,9 @@ func DtVdot(v1, v2 []float32) float32 {
 ///  @param[in]		v2		The vector to scale and add to @p v1. [(x, y, z)]
 ///  @param[in]		s		The amount to scale @p v2 by before adding to @p v1.
 func DtVmad(dest, v1, v2 []float32, s float32) {
-	dest[0] = v1[0] + v2[0]*s
-	dest[1] = v1[1] + v2[1]*s
-	dest[2] = v1[2] + v2[2]*s
+	dest[0] = v1[0] + DtMathMulf(v2[0], s)
+	dest[1] = v1[1] + DtMathMulf(v2[1], s)
+	dest[2] = v1[2] + DtMathMulf(v2[2], s)
 }
 
 /// Performs a linear interpolation between two vectors. (@p v1 toward @p v2)
@@ -279,9 +279,9 @@ func DtVmad(dest, v1, v2 []float32, s float32) {
 ///  @param[in]		v2		The destination vector.
 ///	 @param[in]		t		The interpolation factor. [Limits: 0 <= value <= 1.0]
 func DtVlerp(dest, v1, v2 []float32, t float32) {
-	dest[0] = v1[0] + (v2[0]-v1[0])*t
-	dest[1] = v1[1] + (v2[1]-v1[1])*t
-	dest[2] = v1[2] + (v2[2]-v1[2])*t
+	dest[0] = v1[0] + DtMathMulf(v2[0]-v1[0], t)
+	dest[1] = v1[1] + DtMathMulf(v2[1]-v1[1], t)
+	dest[2] = v1[2] + DtMathMulf(v2[2]-v1[2], t)
 }
 
 /// Performs a vector addition. (@p v1 + @p v2)
@@ -309,9 +309,9 @@ func DtVsub(dest, v1, v2 []float32) {
 ///  @param[in]		v		The vector to scale. [(x, y, z)]
 ///  @param[in]		t		The scaling factor.
 func DtVscale(dest, v []float32, t float32) {
-	dest[0] = v[0] * t
-	dest[1] = v[1] * t
-	dest[2] = v[2] * t
+	dest[0] = DtMathMulf(v[0], t)
+	dest[1] = DtMathMulf(v[1], t)
+	dest[2] = DtMathMulf(v[2], t)
 }
 
 /// Selects the minimum value of each element from the specified vectors.
@@ -356,14 +356,14 @@ func DtVcopy(dest, a []float32) {
 ///  @param[in]		v The vector. [(x, y, z)]
 /// @return The scalar length of the vector.
 func DtVlen(v []float32) float32 {
-	return DtMathSqrtf(v[0]*v[0] + v[1]*v[1] + v[2]*v[2])
+	return DtMathSqrtf(DtMathMulf(v[0], v[0]) + DtMathMulf(v[1], v[1]) + DtMathMulf(v[2], v[2]))
 }
 
 /// Derives the square of the scalar length of the vector. (len * len)
 ///  @param[in]		v The vector. [(x, y, z)]
 /// @return The square of the scalar length of the vector.
 func DtVlenSqr(v []float32) float32 {
-	return v[0]*v[0] + v[1]*v[1] + v[2]*v[2]
+	return DtMathMulf(v[0], v[0]) + DtMathMulf(v[1], v[1]) + DtMathMulf(v[2], v[2])
 }
 
 /// Returns the distance between two points.
@@ -374,7 +374,7 @@ func DtVdist(v1, v2 []float32) float32 {
 	dx := v2[0] - v1[0]
 	dy := v2[1] - v1[1]
 	dz := v2[2] - v1[2]
-	return DtMathSqrtf(dx*dx + dy*dy + dz*dz)
+	return DtMathSqrtf(DtMathMulf(dx, dx) + DtMathMulf(dy, dy) + DtMathMulf(dz, dz))
 }
 
 /// Returns the square of the distance between two points.
@@ -385,7 +385,7 @@ func DtVdistSqr(v1, v2 []float32) float32 {
 	dx := v2[0] - v1[0]
 	dy := v2[1] - v1[1]
 	dz := v2[2] - v1[2]
-	return dx*dx + dy*dy + dz*dz
+	return DtMathMulf(dx, dx) + DtMathMulf(dy, dy) + DtMathMulf(dz, dz)
 }
 
 /// Derives the distance between the specified points on the xz-plane.
@@ -397,7 +397,7 @@ func DtVdistSqr(v1, v2 []float32) float32 {
 func DtVdist2D(v1, v2 []float32) float32 {
 	dx := v2[0] - v1[0]
 	dz := v2[2] - v1[2]
-	return DtMathSqrtf(dx*dx + dz*dz)
+	return DtMathSqrtf(DtMathMulf(dx, dx) + DtMathMulf(dz, dz))
 }
 
 /// Derives the square of the distance between the specified points on the xz-plane.
@@ -407,16 +407,16 @@ func DtVdist2D(v1, v2 []float32) float32 {
 func DtVdist2DSqr(v1, v2 []float32) float32 {
 	dx := v2[0] - v1[0]
 	dz := v2[2] - v1[2]
-	return dx*dx + dz*dz
+	return DtMathMulf(dx, dx) + DtMathMulf(dz, dz)
 }
 
 /// Normalizes the vector.
 ///  @param[in,out]	v	The vector to normalize. [(x, y, z)]
 func DtVnormalize(v []float32) {
 	d := 1.0 / DtMathSqrtf(DtSqrFloat32(v[0])+DtSqrFloat32(v[1])+DtSqrFloat32(v[2]))
-	v[0] *= d
-	v[1] *= d
-	v[2] *= d
+	v[0] = DtMathMulf(v[0], d)
+	v[1] = DtMathMulf(v[1], d)
+	v[2] = DtMathMulf(v[2], d)
 }
 
 var thr float32 = DtSqrFloat32(1.0 / 16384.0)
@@ -440,7 +440,7 @@ func DtVequal(p0, p1 []float32) bool {
 ///
 /// The vectors are projected onto the xz-plane, so the y-values are ignored.
 func DtVdot2D(u, v []float32) float32 {
-	return u[0]*v[0] + u[2]*v[2]
+	return DtMathMulf(u[0], v[0]) + DtMathMulf(u[2], v[2])
 }
 
 /// Derives the xz-plane 2D perp product of the two vectors. (uz*vx - ux*vz)
@@ -450,7 +450,7 @@ func DtVdot2D(u, v []float32) float32 {
 ///
 /// The vectors are projected onto the xz-plane, so the y-values are ignored.
 func DtVperp2D(u, v []float32) float32 {
-	return u[2]*v[0] - u[0]*v[2]
+	return DtMathMulf(u[2], v[0]) - DtMathMulf(u[0], v[2])
 }
 
 /// @}
@@ -467,7 +467,7 @@ func DtTriArea2D(a, b, c []float32) float32 {
 	abz := b[2] - a[2]
 	acx := c[0] - a[0]
 	acz := c[2] - a[2]
-	return acx*abz - abx*acz
+	return DtMathMulf(acx, abz) - DtMathMulf(abx, acz)
 }
 
 /// Determines if two axis-aligned bounding boxes overlap.
@@ -526,13 +526,13 @@ func DtClosestPtPointTriangle(closest, p, a, b, c []float32) {
 	}
 
 	// Check if P in edge region of AB, if so return projection of P onto AB
-	vc := d1*d4 - d3*d2
+	vc := DtMathMulf(d1, d4) - DtMathMulf(d3, d2)
 	if vc <= 0.0 && d1 >= 0.0 && d3 <= 0.0 {
 		// barycentric coordinates (1-v,v,0)
 		v := d1 / (d1 - d3)
-		closest[0] = a[0] + v*ab[0]
-		closest[1] = a[1] + v*ab[1]
-		closest[2] = a[2] + v*ab[2]
+		closest[0] = a[0] + DtMathMulf(v, ab[0])
+		closest[1] = a[1] + DtMathMulf(v, ab[1])
+		closest[2] = a[2] + DtMathMulf(v, ab[2])
 		return
 	}
 
@@ -548,34 +548,34 @@ func DtClosestPtPointTriangle(closest, p, a, b, c []float32) {
 	}
 
 	// Check if P in edge region of AC, if so return projection of P onto AC
-	vb := d5*d2 - d1*d6
+	vb := DtMathMulf(d5, d2) - DtMathMulf(d1, d6)
 	if vb <= 0.0 && d2 >= 0.0 && d6 <= 0.0 {
 		// barycentric coordinates (1-w,0,w)
 		w := d2 / (d2 - d6)
-		closest[0] = a[0] + w*ac[0]
-		closest[1] = a[1] + w*ac[1]
-		closest[2] = a[2] + w*ac[2]
+		closest[0] = a[0] + DtMathMulf(w, ac[0])
+		closest[1] = a[1] + DtMathMulf(w, ac[1])
+		closest[2] = a[2] + DtMathMulf(w, ac[2])
 		return
 	}
 
 	// Check if P in edge region of BC, if so return projection of P onto BC
-	va := d3*d6 - d5*d4
+	va := DtMathMulf(d3, d6) - DtMathMulf(d5, d4)
 	if va <= 0.0 && (d4-d3) >= 0.0 && (d5-d6) >= 0.0 {
 		// barycentric coordinates (0,1-w,w)
 		w := (d4 - d3) / ((d4 - d3) + (d5 - d6))
-		closest[0] = b[0] + w*(c[0]-b[0])
-		closest[1] = b[1] + w*(c[1]-b[1])
-		closest[2] = b[2] + w*(c[2]-b[2])
+		closest[0] = b[0] + DtMathMulf(w, c[0]-b[0])
+		closest[1] = b[1] + DtMathMulf(w, c[1]-b[1])
+		closest[2] = b[2] + DtMathMulf(w, c[2]-b[2])
 		return
 	}
 
 	// P inside face region. Compute Q through its barycentric coordinates (u,v,w)
 	denom := 1.0 / (va + vb + vc)
-	v := vb * denom
-	w := vc * denom
-	closest[0] = a[0] + ab[0]*v + ac[0]*w
-	closest[1] = a[1] + ab[1]*v + ac[1]*w
-	closest[2] = a[2] + ab[2]*v + ac[2]*w
+	v := DtMathMulf(vb, denom)
+	w := DtMathMulf(vc, denom)
+	closest[0] = a[0] + DtMathMulf(ab[0], v) + DtMathMulf(ac[0], w)
+	closest[1] = a[1] + DtMathMulf(ab[1], v) + DtMathMulf(ac[1], w)
+	closest[2] = a[2] + DtMathMulf(ab[2], v) + DtMathMulf(ac[2], w)
 }
 
 var EPS float32 = 1e-4
@@ -601,9 +601,9 @@ func DtClosestHeightPointTriangle(p, a, b, c []float32, h *float32) bool {
 	dot12 := DtVdot2D(v1[:], v2[:])
 
 	// Compute barycentric coordinates
-	invDenom := 1.0 / (dot00*dot11 - dot01*dot01)
-	u := (dot11*dot02 - dot01*dot12) * invDenom
-	v := (dot00*dot12 - dot01*dot02) * invDenom
+	invDenom := 1.0 / (DtMathMulf(dot00, dot11) - DtMathMulf(dot01, dot01))
+	u := DtMathMulf(DtMathMulf(dot11, dot02)-DtMathMulf(dot01, dot12), invDenom)
+	v := DtMathMulf(DtMathMulf(dot00, dot12)-DtMathMulf(dot01, dot02), invDenom)
 
 	// The (sloppy) epsilon is needed to allow to get height of points which
 	// are interpolated along the edges of the triangles.
@@ -611,7 +611,7 @@ func DtClosestHeightPointTriangle(p, a, b, c []float32, h *float32) bool {
 
 	// If point lies inside the triangle, return interpolated ycoord.
 	if u >= -EPS && v >= -EPS && (u+v) <= 1+EPS {
-		*h = a[1] + v0[1]*u + v1[1]*v
+		*h = a[1] + DtMathMulf(v0[1], u) + DtMathMulf(v1[1], v)
 		return true
 	}
 
@@ -670,7 +670,7 @@ func DtIntersectSegmentPoly2D(p0, p1, verts []float32, nverts int, tmin, tmax *f
 }
 
 func vperpXZ(a, b []float32) float32 {
-	return a[0]*b[2] - a[2]*b[0]
+	return DtMathMulf(a[0], b[2]) - DtMathMulf(a[2], b[0])
 }
 
 func DtIntersectSegSeg2D(ap, aq, bp, bq []float32, s, t *float32) bool {
@@ -713,8 +713,8 @@ func DtDistancePtSegSqr2D(pt, p, q []float32, t *float32) float32 {
 	pqz := q[2] - p[2]
 	dx := pt[0] - p[0]
 	dz := pt[2] - p[2]
-	d := pqx*pqx + pqz*pqz
-	*t = pqx*dx + pqz*dz
+	d := DtMathMulf(pqx, pqx) + DtMathMulf(pqz, pqz)
+	*t = DtMathMulf(pqx, dx) + DtMathMulf(pqz, dz)
 	if d > 0 {
 		*t /= d
 	}
@@ -723,9 +723,9 @@ func DtDistancePtSegSqr2D(pt, p, q []float32, t *float32) float32 {
 	} else if *t > 1 {
 		*t = 1
 	}
-	dx = p[0] + (*t)*pqx - pt[0]
-	dz = p[2] + (*t)*pqz - pt[2]
-	return dx*dx + dz*dz
+	dx = p[0] + DtMathMulf(*t, pqx) - pt[0]
+	dz = p[2] + DtMathMulf(*t, pqz) - pt[2]
+	return DtMathMulf(dx, dx) + DtMathMulf(dz, dz)
 }
 
 func DtDistancePtPolyEdgesSqr(pt, verts []float32, nverts int, ed, et []float32) bool {
@@ -926,7 +926,7 @@ func DtRandomPointInConvexPoly(pts []float32, npts int, areas []float32, s, t fl
 		areasum += DtMaxFloat32(float32(0.001), areas[i])
 	}
 	// Find sub triangle weighted by area.
-	thr := s * areasum
+	thr := DtMathMulf(s, areasum)
 	acc := float32(0.0)
 	u := float32(1.0)
 	tri := npts - 1
@@ -943,15 +943,15 @@ func DtRandomPointInConvexPoly(pts []float32, npts int, areas []float32, s, t fl
 	v := DtMathSqrtf(t)
 
 	a := 1 - v
-	b := (1 - u) * v
-	c := u * v
+	b := DtMathMulf(1-u, v)
+	c := DtMathMulf(u, v)
 	pa := pts[0:]
 	pb := pts[(tri-1)*3:]
 	pc := pts[tri*3:]
 
-	out[0] = a*pa[0] + b*pb[0] + c*pc[0]
-	out[1] = a*pa[1] + b*pb[1] + c*pc[1]
-	out[2] = a*pa[2] + b*pb[2] + c*pc[2]
+	out[0] = DtMathMulf(a, pa[0]) + DtMathMulf(b, pb[0]) + DtMathMulf(c, pc[0])
+	out[1] = DtMathMulf(a, pa[1]) + DtMathMulf(b, pb[1]) + DtMathMulf(c, pc[1])
+	out[2] = DtMathMulf(a, pa[2]) + DtMathMulf(b, pb[2]) + DtMathMulf(c, pc[2])
 }
 
 ///////////////////////////////////////////////////////////////////////////
@@ -0,0 +1,50 @@
+package detour
+
+type dtCostEntry struct {
+	id   uint32
+	cost float32
+}
+
+/// A binary heap of ids ordered by cost, used by the searches which do not
+/// run on the node pool. An id can be pushed several times, the stale entries
+/// are skipped by the caller when popped.
+type dtCostQueue []dtCostEntry
+
+func (this *dtCostQueue) push(id uint32, cost float32) {
+	q := append(*this, dtCostEntry{id, cost})
+	i := len(q) - 1
+	for i > 0 {
+		parent := (i - 1) / 2
+		if q[parent].cost <= q[i].cost {
+			break
+		}
+		q[parent], q[i] = q[i], q[parent]
+		i = parent
+	}
+	*this = q
+}
+
+func (this *dtCostQueue) pop() dtCostEntry {
+	q := *this
+	top := q[0]
+	last := len(q) - 1
+	q[0] = q[last]
+	q = q[:last]
+	i := 0
+	for {
+		child := i*2 + 1
+		if child >= len(q) {
+			break
+		}
+		if child+1 < len(q) && q[child+1].cost < q[child].cost {
+			child++
+		}
+		if q[i].cost <= q[child].cost {
+			break
+		}
+		q[i], q[child] = q[child], q[i]
+		i = child
+	}
+	*this = q
+	return top
+}
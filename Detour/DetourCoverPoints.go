@@ -0,0 +1,171 @@
+package detour
+
+import (
+	"math"
+	"sort"
+)
+
+/// A position hidden from a threat, found by #DtNavMeshQuery.FindCoverPoints.
+type DtCoverPoint struct {
+	Pos    [3]float32 ///< The cover position.
+	Ref    DtPolyRef  ///< The reference id of the polygon the position is on.
+	Normal [3]float32 ///< The normal of the wall giving cover, pointing away from the wall. (y is zero.)
+	Score  float32    ///< How squarely the wall faces the threat. [Limits: 0 < value <= 1]
+}
+
+/// Finds positions along the walls around a position which are hidden from a threat.
+///  @param[in]		startRef	The reference id of the polygon where the search starts.
+///  @param[in]		centerPos	The center of the search circle. [(x, y, z)]
+///  @param[in]		radius		The radius of the search circle.
+///  @param[in]		threatPos	The position to hide from. [(x, y, z)]
+///  @param[in]		agentRadius	The distance the cover positions keep from the walls.
+///  @param[in]		spacing		The distance between the positions sampled along the walls. [Limit: > 0]
+///  @param[in]		filter		The polygon filter to apply to the query.
+///  @param[out]	points		The cover positions, best first. [(coverPoint) * @p pointCount]
+///  @param[out]	pointCount	The number of cover positions found.
+///  @param[in]		maxPoints	The maximum number of cover positions the @p points array can hold.
+/// @returns The status flags for the query.
+/// @par
+///
+/// The polygons around the center are found with #FindPolysAroundCircle, and
+/// their walls with #GetPolyWallSegments. Positions are sampled along the walls
+/// which face away from the threat, moved @p agentRadius away from the wall, and
+/// kept if they are within the search circle and a #Raycast from them towards
+/// the threat is blocked.
+///
+/// The score is the cosine of the angle between the wall and the direction to
+/// the threat, so a wall standing right between the position and the threat
+/// scores 1. Positions with the same score are ordered by their distance to
+/// the center.
+///
+/// Like #Raycast, the visibility check is done in 2D against the boundary of
+/// the navigation mesh. Any boundary blocks the view, whether it is a wall or
+/// a ledge.
+///
+/// If the @p points array is too small to hold all the cover positions, it is
+/// filled with the best ones. The status details of #FindPolysAroundCircle are
+/// kept, so #DT_BUFFER_TOO_SMALL is also set when not all the polygons in the
+/// search circle could be searched.
+func (this *DtNavMeshQuery) FindCoverPoints(startRef DtPolyRef, centerPos []float32, radius float32,
+	threatPos []float32, agentRadius, spacing float32, filter *DtQueryFilter,
+	points []DtCoverPoint, pointCount *int, maxPoints int) DtStatus {
+	DtAssert(this.m_nav != nil)
+
+	*pointCount = 0
+
+	// Validate input
+	if startRef == 0 || !this.m_nav.IsValidPolyRef(startRef) || centerPos == nil || threatPos == nil ||
+		filter == nil || radius < 0 || agentRadius < 0 || !(spacing > 0) || maxPoints < 0 {
+		return DT_FAILURE | DT_INVALID_PARAM
+	}
+
+	const MAX_POLYS int = 64
+	var polys [MAX_POLYS]DtPolyRef
+	var npolys int
+	status := this.FindPolysAroundCircle(startRef, centerPos, radius, filter, polys[:], nil, nil, &npolys, MAX_POLYS)
+	if DtStatusFailed(status) {
+		return status
+	}
+
+	const MAX_SEGS int = int(DT_VERTS_PER_POLYGON) * 4
+	var segs [MAX_SEGS * 6]float32
+	var candidates []DtCoverPoint
+	var path [DT_VERTS_PER_POLYGON]DtPolyRef
+	radiusSqr := DtSqrFloat32(radius)
+
+	for i := 0; i < npolys; i++ {
+		ref := polys[i]
+		var tile *DtMeshTile
+		var poly *DtPoly
+		this.m_nav.GetTileAndPolyByRefUnsafe(ref, &tile, &poly)
+		if poly.GetType() == DT_POLYTYPE_OFFMESH_CONNECTION {
+			continue
+		}
+		// The polygon center tells on which side of its walls it lies.
+		var center [3]float32
+		for j := 0; j < int(poly.VertCount); j++ {
+			DtVadd(center[:], center[:], tile.Verts[poly.Verts[j]*3:])
+		}
+		DtVscale(center[:], center[:], 1/float32(poly.VertCount))
+
+		var nsegs int
+		this.GetPolyWallSegments(ref, filter, segs[:], nil, &nsegs, MAX_SEGS)
+		for j := 0; j < nsegs; j++ {
+			va := segs[j*6:]
+			vb := segs[j*6+3:]
+
+			var dir, normal [3]float32
+			DtVsub(dir[:], vb, va)
+			dir[1] = 0
+			length := DtVlen(dir[:])
+			if length < 1e-6 {
+				continue
+			}
+			DtVset(normal[:], dir[2]/length, 0, -dir[0]/length)
+			var toCenter [3]float32
+			DtVsub(toCenter[:], center[:], va)
+			if DtVdot2D(normal[:], toCenter[:]) < 0 {
+				DtVscale(normal[:], normal[:], -1)
+			}
+
+			nsamples := int(math.Ceil(float64(length / spacing)))
+			for k := 0; k < nsamples; k++ {
+				var wallPos, pos [3]float32
+				DtVlerp(wallPos[:], va, vb, (float32(k)+0.5)/float32(nsamples))
+
+				// The wall must stand between the position and the threat.
+				var toThreat [3]float32
+				DtVsub(toThreat[:], threatPos, wallPos[:])
+				toThreat[1] = 0
+				score := -DtVdot2D(normal[:], toThreat[:])
+				if d := DtVlen(toThreat[:]); d > 1e-6 {
+					score /= d
+				}
+				if score <= 0 {
+					continue
+				}
+
+				DtVmad(pos[:], wallPos[:], normal[:], agentRadius)
+				if DtVdist2DSqr(pos[:], centerPos) > radiusSqr {
+					continue
+				}
+
+				// Move away from the wall, the position may be in another polygon.
+				var t float32
+				var npath int
+				this.Raycast(ref, wallPos[:], pos[:], filter, &t, nil, path[:], &npath, len(path))
+				if t < 1 || npath == 0 {
+					continue
+				}
+				posRef := path[npath-1]
+				var h float32
+				if DtStatusSucceed(this.GetPolyHeight(posRef, pos[:], &h)) {
+					pos[1] = h
+				}
+
+				// Hidden if the view towards the threat is blocked.
+				this.Raycast(posRef, pos[:], threatPos, filter, &t, nil, nil, &npath, 0)
+				if t >= 1 {
+					continue
+				}
+
+				candidates = append(candidates, DtCoverPoint{Pos: pos, Ref: posRef, Normal: normal, Score: score})
+			}
+		}
+	}
+
+	sort.SliceStable(candidates, func(a, b int) bool {
+		if candidates[a].Score != candidates[b].Score {
+			return candidates[a].Score > candidates[b].Score
+		}
+		return DtVdist2DSqr(candidates[a].Pos[:], centerPos) < DtVdist2DSqr(candidates[b].Pos[:], centerPos)
+	})
+
+	n := copy(points[:maxPoints], candidates)
+	*pointCount = n
+	details := status & DT_STATUS_DETAIL_MASK
+	if n < len(candidates) {
+		details |= DT_BUFFER_TOO_SMALL
+	}
+	return DT_SUCCESS | details
+}
@@ -0,0 +1,377 @@
+package detour
+
+import (
+	"math"
+	"math/bits"
+)
+
+/// A Q16.16 fixed-point number: the value times 65536, in an int32.
+///
+/// The fixed-point values represent -32768 to 32768 with a resolution of
+/// 1/65536. The products of two values are held in int64 as Q32.32, so
+/// the squared distances and the cross products of the fixed-point query
+/// are exact. To keep them from overflowing, the coordinates used with the
+/// fixed-point query must lie within 8192 units of the origin.
+/// @see #DtNavMeshQueryFixed
+type DtFixed int32
+
+const (
+	DT_FIXED_SHIFT uint    = 16                  ///< The number of fractional bits of #DtFixed.
+	DT_FIXED_ONE   DtFixed = 1 << DT_FIXED_SHIFT ///< The fixed-point 1.
+	DT_FIXED_HALF  DtFixed = DT_FIXED_ONE / 2    ///< The fixed-point 0.5.
+	DT_FIXED_MAX   DtFixed = math.MaxInt32       ///< The largest fixed-point value, used like FLT_MAX.
+	DT_FIXED_MIN   DtFixed = math.MinInt32       ///< The smallest fixed-point value.
+)
+
+/// Converts a float to fixed point, rounding to the nearest value.
+///  @param[in]		f		The value to convert.
+/// @return The fixed-point value, saturated to [#DT_FIXED_MIN, #DT_FIXED_MAX].
+///
+/// The conversion only uses integer operations on the bits of @p f, so the
+/// float data of the tiles reads the same on every platform.
+func DtFixedFromFloat(f float32) DtFixed {
+	b := math.Float32bits(f)
+	exp := int((b >> 23) & 0xff)
+	mant := int64(b & 0x7fffff)
+	if exp == 0xff {
+		// Inf and NaN saturate.
+		if b>>31 != 0 {
+			return DT_FIXED_MIN
+		}
+		return DT_FIXED_MAX
+	}
+	if exp != 0 {
+		mant |= 0x800000
+	} else {
+		exp = 1
+	}
+	// f = mant * 2^(exp-150), the fixed-point value is mant * 2^(exp-134).
+	var v int64
+	if shift := exp - 134; shift >= 0 {
+		if shift > 8 {
+			v = math.MaxInt64
+		} else {
+			v = mant << uint(shift)
+		}
+	} else if shift = -shift; shift < 32 {
+		v = (mant + int64(1)<<uint(shift-1)) >> uint(shift)
+	}
+	if v > int64(DT_FIXED_MAX) {
+		v = int64(DT_FIXED_MAX)
+	}
+	if b>>31 != 0 {
+		v = -v
+	}
+	return DtFixed(v)
+}
+
+/// Converts a fixed-point value to float.
+func DtFixedToFloat(v DtFixed) float32 {
+	return float32(v) / float32(DT_FIXED_ONE)
+}
+
+/// Converts an integer to fixed point.
+func DtFixedFromInt(i int) DtFixed {
+	return DtFixed(i << DT_FIXED_SHIFT)
+}
+
+/// Multiplies two fixed-point values, rounding to the nearest value.
+func DtFixedMul(a, b DtFixed) DtFixed {
+	return DtFixed(dtFixedRound(int64(a) * int64(b)))
+}
+
+/// Divides two fixed-point values, truncating toward zero.
+///  @param[in]		a		The dividend.
+///  @param[in]		b		The divisor. [Limit: != 0]
+func DtFixedDiv(a, b DtFixed) DtFixed {
+	return DtFixed((int64(a) << DT_FIXED_SHIFT) / int64(b))
+}
+
+/// Returns the square root of a fixed-point value, zero if it is negative.
+func DtFixedSqrt(v DtFixed) DtFixed {
+	if v <= 0 {
+		return 0
+	}
+	return DtFixed(dtIsqrt(uint64(v) << DT_FIXED_SHIFT))
+}
+
+/// Converts a float vector to fixed point.
+///  @param[out]	dest	The fixed-point vector. [(x, y, z)]
+///  @param[in]		v		The float vector. [(x, y, z)]
+func DtVtoFixed(dest []DtFixed, v []float32) {
+	dest[0] = DtFixedFromFloat(v[0])
+	dest[1] = DtFixedFromFloat(v[1])
+	dest[2] = DtFixedFromFloat(v[2])
+}
+
+/// Converts a fixed-point vector to float.
+///  @param[out]	dest	The float vector. [(x, y, z)]
+///  @param[in]		v		The fixed-point vector. [(x, y, z)]
+func DtVfromFixed(dest []float32, v []DtFixed) {
+	dest[0] = DtFixedToFloat(v[0])
+	dest[1] = DtFixedToFloat(v[1])
+	dest[2] = DtFixedToFloat(v[2])
+}
+
+// Rounds a Q32.32 product to Q16.16.
+func dtFixedRound(v int64) int64 {
+	return (v + int64(DT_FIXED_HALF)) >> DT_FIXED_SHIFT
+}
+
+// Returns floor(sqrt(v)).
+func dtIsqrt(v uint64) uint64 {
+	var res uint64
+	bit := uint64(1) << 62
+	for bit > v {
+		bit >>= 2
+	}
+	for bit != 0 {
+		if v >= res+bit {
+			v -= res + bit
+			res = (res >> 1) + bit
+		} else {
+			res >>= 1
+		}
+		bit >>= 2
+	}
+	return res
+}
+
+// Returns a*b/c rounded to the nearest integer, with a 128 bit intermediate
+// product. The result must fit in an int64.
+func dtFixedMulDiv(a, b, c int64) int64 {
+	neg := false
+	if a < 0 {
+		a, neg = -a, !neg
+	}
+	if b < 0 {
+		b, neg = -b, !neg
+	}
+	if c < 0 {
+		c, neg = -c, !neg
+	}
+	hi, lo := bits.Mul64(uint64(a), uint64(b))
+	var carry uint64
+	lo, carry = bits.Add64(lo, uint64(c)/2, 0)
+	hi += carry
+	q, _ := bits.Div64(hi, lo, uint64(c))
+	if neg {
+		return -int64(q)
+	}
+	return int64(q)
+}
+
+// Returns n/d as a fixed-point ratio, clamped to [-lim, lim].
+func dtFixedRatio(n, d int64, lim DtFixed) DtFixed {
+	if d == 0 {
+		if n < 0 {
+			return -lim
+		}
+		return lim
+	}
+	an, ad := n, d
+	if an < 0 {
+		an = -an
+	}
+	if ad < 0 {
+		ad = -ad
+	}
+	// |n/d| >= lim, in 128 bits.
+	nhi, nlo := bits.Mul64(uint64(an), uint64(DT_FIXED_ONE))
+	dhi, dlo := bits.Mul64(uint64(ad), uint64(lim))
+	if nhi > dhi || (nhi == dhi && nlo >= dlo) {
+		if (n < 0) != (d < 0) {
+			return -lim
+		}
+		return lim
+	}
+	return DtFixed(dtFixedMulDiv(n, int64(DT_FIXED_ONE), d))
+}
+
+// The fixed-point vector helpers of the fixed-point query. The squared
+// lengths, dot and cross products are Q32.32 in int64.
+
+func dtFixedVcopy(dest, a []DtFixed) {
+	dest[0] = a[0]
+	dest[1] = a[1]
+	dest[2] = a[2]
+}
+
+func dtFixedVset(dest []DtFixed, x, y, z DtFixed) {
+	dest[0] = x
+	dest[1] = y
+	dest[2] = z
+}
+
+func dtFixedVlerp(dest, v1, v2 []DtFixed, t DtFixed) {
+	dest[0] = v1[0] + DtFixedMul(v2[0]-v1[0], t)
+	dest[1] = v1[1] + DtFixedMul(v2[1]-v1[1], t)
+	dest[2] = v1[2] + DtFixedMul(v2[2]-v1[2], t)
+}
+
+func dtFixedVdistSqr(v1, v2 []DtFixed) int64 {
+	dx := int64(v2[0]) - int64(v1[0])
+	dy := int64(v2[1]) - int64(v1[1])
+	dz := int64(v2[2]) - int64(v1[2])
+	return dx*dx + dy*dy + dz*dz
+}
+
+func dtFixedVdist(v1, v2 []DtFixed) int64 {
+	return int64(dtIsqrt(uint64(dtFixedVdistSqr(v1, v2))))
+}
+
+func dtFixedVequal(p0, p1 []DtFixed) bool {
+	// The same threshold as dtVequal, (1/16384)^2 in Q32.32.
+	return dtFixedVdistSqr(p0, p1) < 16
+}
+
+func dtFixedTriArea2D(a, b, c []DtFixed) int64 {
+	abx := int64(b[0]) - int64(a[0])
+	abz := int64(b[2]) - int64(a[2])
+	acx := int64(c[0]) - int64(a[0])
+	acz := int64(c[2]) - int64(a[2])
+	return acx*abz - abx*acz
+}
+
+func dtFixedDistancePtSegSqr2D(pt, p, q []DtFixed, t *DtFixed) int64 {
+	pqx := int64(q[0]) - int64(p[0])
+	pqz := int64(q[2]) - int64(p[2])
+	dx := int64(pt[0]) - int64(p[0])
+	dz := int64(pt[2]) - int64(p[2])
+	d := pqx*pqx + pqz*pqz
+	n := pqx*dx + pqz*dz
+	if n <= 0 || d == 0 {
+		*t = 0
+	} else if n >= d {
+		*t = DT_FIXED_ONE
+	} else {
+		*t = DtFixed(dtFixedMulDiv(n, int64(DT_FIXED_ONE), d))
+	}
+	dx = int64(p[0]) + dtFixedRound(int64(*t)*pqx) - int64(pt[0])
+	dz = int64(p[2]) + dtFixedRound(int64(*t)*pqz) - int64(pt[2])
+	return dx*dx + dz*dz
+}
+
+func dtFixedPointInPolygon(pt, verts []DtFixed, nverts int) bool {
+	c := false
+	for i, j := 0, nverts-1; i < nverts; j, i = i, i+1 {
+		vi := verts[i*3:]
+		vj := verts[j*3:]
+		if (vi[2] > pt[2]) != (vj[2] > pt[2]) {
+			// pt.x < (vj.x-vi.x) * (pt.z-vi.z) / (vj.z-vi.z) + vi.x, without the division.
+			lhs := (int64(pt[0]) - int64(vi[0])) * (int64(vj[2]) - int64(vi[2]))
+			rhs := (int64(vj[0]) - int64(vi[0])) * (int64(pt[2]) - int64(vi[2]))
+			if (vj[2] > vi[2] && lhs < rhs) || (vj[2] < vi[2] && lhs > rhs) {
+				c = !c
+			}
+		}
+	}
+	return c
+}
+
+func dtFixedDistancePtPolyEdgesSqr(pt, verts []DtFixed, nverts int, ed []int64, et []DtFixed) bool {
+	for i, j := 0, nverts-1; i < nverts; j, i = i, i+1 {
+		ed[j] = dtFixedDistancePtSegSqr2D(pt, verts[j*3:], verts[i*3:], &et[j])
+	}
+	return dtFixedPointInPolygon(pt, verts, nverts)
+}
+
+func dtFixedClosestHeightPointTriangle(p, a, b, c []DtFixed, h *DtFixed) bool {
+	v0x, v0y, v0z := int64(c[0])-int64(a[0]), c[1]-a[1], int64(c[2])-int64(a[2])
+	v1x, v1y, v1z := int64(b[0])-int64(a[0]), b[1]-a[1], int64(b[2])-int64(a[2])
+	v2x, v2z := int64(p[0])-int64(a[0]), int64(p[2])-int64(a[2])
+
+	// The barycentric coordinates are u = nu/den and v = nv/den.
+	den := v0z*v1x - v0x*v1z
+	nu := v2z*v1x - v2x*v1z
+	nv := v0z*v2x - v0x*v2z
+	if den == 0 {
+		return false
+	}
+	if den < 0 {
+		den, nu, nv = -den, -nu, -nv
+	}
+
+	// The same (sloppy) epsilon as dtClosestHeightPointTriangle.
+	eps := den / 10000
+	if nu >= -eps && nv >= -eps && nu+nv <= den+eps {
+		u := DtFixed(dtFixedMulDiv(nu, int64(DT_FIXED_ONE), den))
+		v := DtFixed(dtFixedMulDiv(nv, int64(DT_FIXED_ONE), den))
+		*h = a[1] + DtFixedMul(v0y, u) + DtFixedMul(v1y, v)
+		return true
+	}
+	return false
+}
+
+func dtFixedIntersectSegmentPoly2D(p0, p1, verts []DtFixed, nverts int,
+	tmin, tmax *DtFixed, segMin, segMax *int) bool {
+	*tmin = 0
+	*tmax = DT_FIXED_ONE
+	*segMin = -1
+	*segMax = -1
+
+	dirx := int64(p1[0]) - int64(p0[0])
+	dirz := int64(p1[2]) - int64(p0[2])
+
+	for i, j := 0, nverts-1; i < nverts; j, i = i, i+1 {
+		edgex := int64(verts[i*3+0]) - int64(verts[j*3+0])
+		edgez := int64(verts[i*3+2]) - int64(verts[j*3+2])
+		diffx := int64(p0[0]) - int64(verts[j*3+0])
+		diffz := int64(p0[2]) - int64(verts[j*3+2])
+		n := edgez*diffx - edgex*diffz
+		d := dirz*edgex - dirx*edgez
+		if d == 0 {
+			// S is parallel to this edge
+			if n < 0 {
+				return false
+			}
+			continue
+		}
+		// Out of range values of t only decide the tests below, clamp them.
+		t := dtFixedRatio(n, d, 2*DT_FIXED_ONE)
+		if d < 0 {
+			// segment S is entering across this edge
+			if t > *tmin {
+				*tmin = t
+				*segMin = j
+				// S enters after leaving polygon
+				if *tmin > *tmax {
+					return false
+				}
+			}
+		} else {
+			// segment S is leaving across this edge
+			if t < *tmax {
+				*tmax = t
+				*segMax = j
+				// S leaves before entering polygon
+				if *tmax < *tmin {
+					return false
+				}
+			}
+		}
+	}
+	return true
+}
+
+// Finds the intersection of the lines through segments a and b, as a point of b.
+func dtFixedIntersectSegSeg2D(ap, aq, bp, bq, pt []DtFixed) bool {
+	ux, uz := int64(aq[0])-int64(ap[0]), int64(aq[2])-int64(ap[2])
+	vx, vz := int64(bq[0])-int64(bp[0]), int64(bq[2])-int64(bp[2])
+	wx, wz := int64(ap[0])-int64(bp[0]), int64(ap[2])-int64(bp[2])
+	d := ux*vz - uz*vx
+	// The same threshold as dtIntersectSegSeg2D, 1e-6 in Q32.32.
+	if d > -4295 && d < 4295 {
+		return false
+	}
+	// The point is bp + (bq-bp)*n/d, from the exact ratio unless it is far off the segment.
+	n := ux*wz - uz*wx
+	if t := dtFixedRatio(n, d, 2*DT_FIXED_ONE); t == 2*DT_FIXED_ONE || t == -2*DT_FIXED_ONE {
+		dtFixedVlerp(pt, bp, bq, t)
+		return true
+	}
+	for k := 0; k < 3; k++ {
+		pt[k] = bp[k] + DtFixed(dtFixedMulDiv(int64(bq[k])-int64(bp[k]), n, d))
+	}
+	return true
+}
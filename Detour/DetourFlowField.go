@@ -0,0 +1,461 @@
+package detour
+
+import "math"
+
+type dtPolyFlow struct {
+	next  DtPolyRef  ///< The next polygon towards the goal, or zero.
+	cost  float32    ///< The cost from @p pos to the goal. (FLT_MAX if the goal is not reachable.)
+	pos   [3]float32 ///< The middle of the portal to the next polygon, or the goal position.
+	state uint8      ///< Temporary state used while repairing the field.
+}
+
+type dtTileFlow struct {
+	salt  uint32       ///< The salt of the tile the flow was computed for.
+	polys []dtPolyFlow ///< Flow per polygon. [Size: dtMeshHeader::polyCount]
+}
+
+const (
+	dtFlowUnknown uint8 = iota
+	dtFlowVisiting
+	dtFlowValid
+	dtFlowBroken
+)
+
+/// A flow field towards a goal over a navigation mesh.
+///
+/// The field is computed with a reverse Dijkstra search from the goal, which
+/// stores for every polygon the next polygon towards the goal and the cost
+/// to reach it. Any number of agents heading to the same goal can then look up
+/// their next move in constant time with #Sample instead of running
+/// #DtNavMeshQuery.FindPath each.
+///
+/// The field listens to the navigation mesh. When tiles are added or removed,
+/// for example when #DtTileCache rebuilds the tiles touched by an obstacle,
+/// or when polygon flags and areas change, only the polygons whose route is
+/// affected are cleared and searched again. The repair runs on the next
+/// #Sample or #Update call.
+/// @ingroup detour
+type DtFlowField struct {
+	m_nav     *DtNavMesh
+	m_filter  DtQueryFilter
+	m_query   DtNavMeshQuery ///< Only used for the portal helpers, no node pool is allocated.
+	m_tiles   []dtTileFlow   ///< Flow per tile index.
+	m_goalRef DtPolyRef
+	m_goalPos [3]float32
+
+	m_dirty   bool                      ///< True if the field must be repaired.
+	m_changed map[DtPolyRef]bool        ///< Polygons whose flags or area changed since the last repair.
+	m_oneWay  map[DtPolyRef][]DtPolyRef ///< One-way off-mesh connections leading to each polygon.
+	m_open    dtCostQueue
+}
+
+/// Allocates a flow field for the navigation mesh and starts listening to its changes.
+///  @param[in]	nav		The navigation mesh.
+///  @param[in]	filter	The polygon filter to apply to the search.
+/// @return The flow field.
+func DtAllocFlowField(nav *DtNavMesh, filter *DtQueryFilter) *DtFlowField {
+	field := &DtFlowField{
+		m_nav:     nav,
+		m_filter:  *filter,
+		m_changed: make(map[DtPolyRef]bool),
+	}
+	field.m_query.m_nav = nav
+	field.m_tiles = make([]dtTileFlow, nav.GetMaxTiles())
+	nav.AddListener(field)
+	return field
+}
+
+/// Stops listening to the navigation mesh and frees the field.
+///  @param[in]	field	A flow field allocated using #DtAllocFlowField
+func DtFreeFlowField(field *DtFlowField) {
+	if field == nil {
+		return
+	}
+	field.m_nav.RemoveListener(field)
+	field.m_tiles = nil
+	field.m_oneWay = nil
+}
+
+/// Gets the filter used by the search.
+func (this *DtFlowField) GetFilter() *DtQueryFilter { return &this.m_filter }
+
+/// Gets the goal polygon, or zero if the goal is not on the navigation mesh anymore.
+func (this *DtFlowField) GetGoalRef() DtPolyRef { return this.m_goalRef }
+
+/// Gets the goal position. [(x, y, z)]
+func (this *DtFlowField) GetGoalPos() []float32 { return this.m_goalPos[:] }
+
+/// Sets the goal and computes the whole field.
+///  @param[in]	goalRef		The reference id of the goal polygon.
+///  @param[in]	goalPos		A position within the goal polygon. [(x, y, z)]
+/// @returns The status flags for the operation.
+func (this *DtFlowField) SetGoal(goalRef DtPolyRef, goalPos []float32) DtStatus {
+	var tile *DtMeshTile
+	var poly *DtPoly
+	if DtStatusFailed(this.m_nav.GetTileAndPolyByRef(goalRef, &tile, &poly)) ||
+		!this.m_filter.PassFilter(goalRef, tile, poly) || goalPos == nil {
+		return DT_FAILURE | DT_INVALID_PARAM
+	}
+	this.m_goalRef = goalRef
+	DtVcopy(this.m_goalPos[:], goalPos)
+	this.Rebuild()
+	return DT_SUCCESS
+}
+
+/// Computes the whole field again.
+func (this *DtFlowField) Rebuild() {
+	this.reset()
+	this.m_dirty = false
+	for k := range this.m_changed {
+		delete(this.m_changed, k)
+	}
+	goal := this.flow(this.m_goalRef)
+	if goal == nil {
+		return
+	}
+	this.buildOneWayLinks()
+	goal.cost = 0
+	DtVcopy(goal.pos[:], this.m_goalPos[:])
+	this.m_open = this.m_open[:0]
+	this.m_open.push(uint32(this.m_goalRef), 0)
+	this.search()
+}
+
+/// Repairs the field after changes of the navigation mesh.
+/// It is called by #Sample, call it explicitly to control when the work is done.
+func (this *DtFlowField) Update() {
+	if !this.m_dirty {
+		return
+	}
+	if this.m_goalRef == 0 || this.flow(this.m_goalRef) == nil || this.m_changed[this.m_goalRef] {
+		// The goal tile was rebuilt, find the goal again.
+		if ref := this.findGoal(); ref != 0 {
+			this.m_goalRef = ref
+			this.Rebuild()
+		} else {
+			this.m_goalRef = 0
+			this.reset()
+			this.m_dirty = false
+		}
+		return
+	}
+	if !this.passFilter(this.m_goalRef) {
+		this.reset()
+		this.m_dirty = false
+		return
+	}
+
+	this.buildOneWayLinks()
+	this.clearBrokenRoutes()
+	for k := range this.m_changed {
+		delete(this.m_changed, k)
+	}
+
+	// Search again from the reached polygons around the cleared ones.
+	// New routes through the cleared polygons may also lower the cost of
+	// reached polygons, the search takes care of that too.
+	this.m_open = this.m_open[:0]
+	for i := range this.m_tiles {
+		t := &this.m_tiles[i]
+		if t.polys == nil {
+			continue
+		}
+		tile := this.m_nav.GetTile(i)
+		base := this.m_nav.GetPolyRefBase(tile)
+		for j := range t.polys {
+			if t.polys[j].cost != math.MaxFloat32 || !this.m_filter.PassFilter(base|DtPolyRef(j), tile, &tile.Polys[j]) {
+				continue
+			}
+			poly := &tile.Polys[j]
+			for k := poly.FirstLink; k != DT_NULL_LINK; k = tile.Links[k].Next {
+				if f := this.flow(tile.Links[k].Ref); f != nil && f.cost != math.MaxFloat32 {
+					this.m_open.push(uint32(tile.Links[k].Ref), f.cost)
+				}
+			}
+		}
+	}
+	this.search()
+	this.m_dirty = false
+}
+
+/// Returns the next move towards the goal.
+///  @param[in]		ref			The reference id of the polygon the agent is on.
+///  @param[in]		pos			The position of the agent within the polygon. [(x, y, z)]
+///  @param[out]	nextRef		The next polygon towards the goal, or zero when on the goal polygon. [opt]
+///  @param[out]	target		The point to steer towards: the portal to the next polygon, or the goal. [(x, y, z)] [opt]
+///  @param[out]	cost		The cost from @p pos to the goal. [opt]
+/// @returns The status flags for the query. Fails if the goal cannot be reached from the polygon.
+/// @par
+///
+/// The lookup takes constant time, unless the field has to be repaired first.
+func (this *DtFlowField) Sample(ref DtPolyRef, pos []float32, nextRef *DtPolyRef, target []float32, cost *float32) DtStatus {
+	this.Update()
+	f := this.flow(ref)
+	if f == nil || pos == nil {
+		return DT_FAILURE | DT_INVALID_PARAM
+	}
+	if f.cost == math.MaxFloat32 {
+		return DT_FAILURE
+	}
+	if nextRef != nil {
+		*nextRef = f.next
+	}
+	if target != nil {
+		DtVcopy(target, f.pos[:])
+	}
+	if cost != nil {
+		var tile, nextTile *DtMeshTile
+		var poly, nextPoly *DtPoly
+		this.m_nav.GetTileAndPolyByRefUnsafe(ref, &tile, &poly)
+		if f.next != 0 {
+			this.m_nav.GetTileAndPolyByRefUnsafe(f.next, &nextTile, &nextPoly)
+		}
+		*cost = f.cost + this.m_filter.GetCost(pos, f.pos[:],
+			0, nil, nil,
+			ref, tile, poly,
+			f.next, nextTile, nextPoly)
+	}
+	return DT_SUCCESS
+}
+
+func (this *DtFlowField) flow(ref DtPolyRef) *dtPolyFlow {
+	if ref == 0 {
+		return nil
+	}
+	var salt, it, ip uint32
+	this.m_nav.DecodePolyId(ref, &salt, &it, &ip)
+	if it >= uint32(len(this.m_tiles)) {
+		return nil
+	}
+	t := &this.m_tiles[it]
+	if t.polys == nil || t.salt != salt || ip >= uint32(len(t.polys)) {
+		return nil
+	}
+	return &t.polys[ip]
+}
+
+func (this *DtFlowField) passFilter(ref DtPolyRef) bool {
+	var tile *DtMeshTile
+	var poly *DtPoly
+	this.m_nav.GetTileAndPolyByRefUnsafe(ref, &tile, &poly)
+	return this.m_filter.PassFilter(ref, tile, poly)
+}
+
+func (this *DtFlowField) resetTile(tile *DtMeshTile) {
+	t := &this.m_tiles[this.m_nav.DecodePolyIdTile(DtPolyRef(this.m_nav.GetTileRef(tile)))]
+	t.salt = tile.Salt
+	if cap(t.polys) >= int(tile.Header.PolyCount) {
+		t.polys = t.polys[:tile.Header.PolyCount]
+	} else {
+		t.polys = make([]dtPolyFlow, tile.Header.PolyCount)
+	}
+	for j := range t.polys {
+		t.polys[j] = dtPolyFlow{cost: math.MaxFloat32}
+	}
+}
+
+/// Clears the flow of all the polygons.
+func (this *DtFlowField) reset() {
+	for i := range this.m_tiles {
+		if tile := this.m_nav.GetTile(i); tile.Header != nil {
+			this.resetTile(tile)
+		} else {
+			this.m_tiles[i].polys = nil
+		}
+	}
+}
+
+/// Finds the goal polygon at the goal position.
+func (this *DtFlowField) findGoal() DtPolyRef {
+	var tx, ty int32
+	this.m_nav.CalcTileLoc(this.m_goalPos[:], &tx, &ty)
+	const MAX_NEIS int = 32
+	var tiles [MAX_NEIS]*DtMeshTile
+	ntiles := this.m_nav.GetTilesAt(tx, ty, tiles[:], MAX_NEIS)
+	var best DtPolyRef
+	bestDist := float32(math.MaxFloat32)
+	for i := 0; i < ntiles; i++ {
+		h := tiles[i].Header
+		halfExtents := [3]float32{h.WalkableRadius, h.WalkableHeight, h.WalkableRadius}
+		var pt [3]float32
+		ref := this.m_nav.findNearestPolyInTile(tiles[i], this.m_goalPos[:], halfExtents[:], pt[:])
+		if ref == 0 || !this.passFilter(ref) {
+			continue
+		}
+		if d := DtVdistSqr(pt[:], this.m_goalPos[:]); d < bestDist {
+			best, bestDist = ref, d
+		}
+	}
+	return best
+}
+
+/// Finds the polygons reached by one-way off-mesh connections. The landing
+/// polygon of such a connection has no link back to it, so the reverse search
+/// would not find it otherwise.
+func (this *DtFlowField) buildOneWayLinks() {
+	this.m_oneWay = make(map[DtPolyRef][]DtPolyRef)
+	for i := range this.m_tiles {
+		tile := this.m_nav.GetTile(i)
+		if tile.Header == nil {
+			continue
+		}
+		base := this.m_nav.GetPolyRefBase(tile)
+		for j := 0; j < int(tile.Header.OffMeshConCount); j++ {
+			con := &tile.OffMeshCons[j]
+			if (con.Flags & DT_OFFMESH_CON_BIDIR) != 0 {
+				continue
+			}
+			poly := &tile.Polys[con.Poly]
+			for k := poly.FirstLink; k != DT_NULL_LINK; k = tile.Links[k].Next {
+				link := &tile.Links[k]
+				if link.Edge == 1 && link.Ref != 0 {
+					this.m_oneWay[link.Ref] = append(this.m_oneWay[link.Ref], base|DtPolyRef(con.Poly))
+				}
+			}
+		}
+	}
+}
+
+/// Returns true if the polygon has a link to the other polygon.
+func (this *DtFlowField) hasLink(from, to DtPolyRef) bool {
+	var tile *DtMeshTile
+	var poly *DtPoly
+	this.m_nav.GetTileAndPolyByRefUnsafe(from, &tile, &poly)
+	for i := poly.FirstLink; i != DT_NULL_LINK; i = tile.Links[i].Next {
+		if tile.Links[i].Ref == to {
+			return true
+		}
+	}
+	return false
+}
+
+/// Clears the flow of the polygons whose route to the goal is not valid anymore.
+func (this *DtFlowField) clearBrokenRoutes() {
+	for i := range this.m_tiles {
+		for j := range this.m_tiles[i].polys {
+			this.m_tiles[i].polys[j].state = dtFlowUnknown
+		}
+	}
+	goal := this.flow(this.m_goalRef)
+	goal.state = dtFlowValid
+
+	var route []*dtPolyFlow
+	for i := range this.m_tiles {
+		t := &this.m_tiles[i]
+		if t.polys == nil {
+			continue
+		}
+		base := this.m_nav.GetPolyRefBase(this.m_nav.GetTile(i))
+		for j := range t.polys {
+			if t.polys[j].state != dtFlowUnknown {
+				continue
+			}
+			// Follow the route until a polygon with a known state.
+			route = route[:0]
+			ref := base | DtPolyRef(j)
+			f := &t.polys[j]
+			state := dtFlowBroken
+			for {
+				if f.cost == math.MaxFloat32 || this.m_changed[ref] || !this.passFilter(ref) {
+					break
+				}
+				next := this.flow(f.next)
+				if next == nil || next.state == dtFlowVisiting || !this.hasLink(ref, f.next) {
+					break
+				}
+				f.state = dtFlowVisiting
+				route = append(route, f)
+				if next.state != dtFlowUnknown {
+					state = next.state
+					break
+				}
+				ref, f = f.next, next
+			}
+			if state == dtFlowBroken {
+				f.state = dtFlowBroken
+				f.next = 0
+				f.cost = math.MaxFloat32
+			}
+			for _, r := range route {
+				r.state = state
+				if state == dtFlowBroken {
+					r.next = 0
+					r.cost = math.MaxFloat32
+				}
+			}
+		}
+	}
+}
+
+/// Runs the reverse Dijkstra search from the polygons in the open list.
+func (this *DtFlowField) search() {
+	for len(this.m_open) != 0 {
+		e := this.m_open.pop()
+		bestRef := DtPolyRef(e.id)
+		best := this.flow(bestRef)
+		if best == nil || e.cost > best.cost {
+			continue
+		}
+		var bestTile, nextTile *DtMeshTile
+		var bestPoly, nextPoly *DtPoly
+		this.m_nav.GetTileAndPolyByRefUnsafe(bestRef, &bestTile, &bestPoly)
+		if best.next != 0 {
+			this.m_nav.GetTileAndPolyByRefUnsafe(best.next, &nextTile, &nextPoly)
+		}
+
+		relax := func(prevRef DtPolyRef) {
+			prev := this.flow(prevRef)
+			if prev == nil {
+				return
+			}
+			var prevTile *DtMeshTile
+			var prevPoly *DtPoly
+			this.m_nav.GetTileAndPolyByRefUnsafe(prevRef, &prevTile, &prevPoly)
+			if !this.m_filter.PassFilter(prevRef, prevTile, prevPoly) {
+				return
+			}
+			var pos [3]float32
+			if DtStatusFailed(this.m_query.getEdgeMidPoint2(prevRef, prevPoly, prevTile, bestRef, bestPoly, bestTile, pos[:])) {
+				return
+			}
+			cost := best.cost + this.m_filter.GetCost(pos[:], best.pos[:],
+				prevRef, prevTile, prevPoly,
+				bestRef, bestTile, bestPoly,
+				best.next, nextTile, nextPoly)
+			if cost >= prev.cost {
+				return
+			}
+			prev.next = bestRef
+			prev.cost = cost
+			prev.pos = pos
+			this.m_open.push(uint32(prevRef), cost)
+		}
+
+		// The polygons which can move to this one: the linked polygons
+		// which link back, and the one-way connections landing here.
+		for i := bestPoly.FirstLink; i != DT_NULL_LINK; i = bestTile.Links[i].Next {
+			prevRef := bestTile.Links[i].Ref
+			if prevRef != 0 && prevRef != best.next && this.hasLink(prevRef, bestRef) {
+				relax(prevRef)
+			}
+		}
+		for _, prevRef := range this.m_oneWay[bestRef] {
+			relax(prevRef)
+		}
+	}
+}
+
+func (this *DtFlowField) OnTileAdded(mesh *DtNavMesh, tile *DtMeshTile) {
+	this.resetTile(tile)
+	this.m_dirty = true
+}
+
+func (this *DtFlowField) OnTileRemoved(mesh *DtNavMesh, tile *DtMeshTile) {
+	this.m_tiles[mesh.DecodePolyIdTile(DtPolyRef(mesh.GetTileRef(tile)))].polys = nil
+	this.m_dirty = true
+}
+
+func (this *DtFlowField) OnPolyChanged(mesh *DtNavMesh, ref DtPolyRef) {
+	this.m_changed[ref] = true
+	this.m_dirty = true
+}
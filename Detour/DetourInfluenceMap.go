@@ -0,0 +1,216 @@
+package detour
+
+import "math"
+
+/// An overlay of influence values on the polygons of a navigation mesh, which
+/// decay over time. (E.g. The danger around explosions or enemy lines.)
+///
+/// The influence raises the cost of the polygons through a #DtInfluenceCost set
+/// as the custom implementation of a filter, the polygons themselves are not
+/// changed.
+///
+/// The values are keyed by #DtPolyRef. The values of the polygons of a tile are
+/// dropped when the tile is removed.
+/// @ingroup detour
+type DtInfluenceMap struct {
+	m_nav      *DtNavMesh            ///< The navigation mesh the polygons belong to.
+	m_values   map[DtPolyRef]float32 ///< The influence of each polygon.
+	m_halfLife float32               ///< The time it takes a value to halve.
+	m_minValue float32               ///< Values whose magnitude decays below this are dropped.
+}
+
+/// Allocates an empty influence map and starts listening to the changes of the navigation mesh.
+///  @param[in]	nav			The navigation mesh the polygons belong to.
+///  @param[in]	halfLife	The time it takes an influence to halve. Zero keeps the influences. [Limit: >= 0]
+///  @param[in]	minValue	Influences whose magnitude decays below this value are dropped. [Limit: >= 0]
+/// @return The influence map, or null if a parameter is not valid.
+func DtAllocInfluenceMap(nav *DtNavMesh, halfLife, minValue float32) *DtInfluenceMap {
+	if nav == nil || !(halfLife >= 0) || !(minValue >= 0) {
+		return nil
+	}
+	influence := &DtInfluenceMap{
+		m_nav:      nav,
+		m_values:   make(map[DtPolyRef]float32),
+		m_halfLife: halfLife,
+		m_minValue: minValue,
+	}
+	nav.AddListener(influence)
+	return influence
+}
+
+/// Stops listening to the navigation mesh and frees the influences.
+///  @param[in]	influence	An influence map allocated using #DtAllocInfluenceMap
+func DtFreeInfluenceMap(influence *DtInfluenceMap) {
+	if influence == nil {
+		return
+	}
+	influence.m_nav.RemoveListener(influence)
+	influence.m_values = nil
+}
+
+/// Returns the influence of the polygon, zero if it has none.
+///  @param[in]	ref		The reference id of the polygon.
+func (this *DtInfluenceMap) Get(ref DtPolyRef) float32 {
+	return this.m_values[ref]
+}
+
+/// Adds to the influence of the polygon.
+///  @param[in]	ref		The reference id of the polygon.
+///  @param[in]	amount	The influence to add. It may be negative.
+/// @return The status flags for the operation.
+func (this *DtInfluenceMap) Add(ref DtPolyRef, amount float32) DtStatus {
+	if !this.m_nav.IsValidPolyRef(ref) {
+		return DT_FAILURE | DT_INVALID_PARAM
+	}
+	this.set(ref, this.m_values[ref]+amount)
+	return DT_SUCCESS
+}
+
+func (this *DtInfluenceMap) set(ref DtPolyRef, value float32) {
+	if DtAbsFloat32(value) <= this.m_minValue {
+		delete(this.m_values, ref)
+	} else {
+		this.m_values[ref] = value
+	}
+}
+
+/// Removes all the influences.
+func (this *DtInfluenceMap) Clear() {
+	this.m_values = make(map[DtPolyRef]float32)
+}
+
+/// The number of polygons with an influence.
+func (this *DtInfluenceMap) GetEntryCount() int {
+	return len(this.m_values)
+}
+
+/// Decays the influences.
+///  @param[in]	dt		The time elapsed since the last update. [Limit: >= 0]
+func (this *DtInfluenceMap) Update(dt float32) {
+	if this.m_halfLife == 0 || !(dt > 0) {
+		return
+	}
+	scale := float32(math.Exp2(float64(-dt / this.m_halfLife)))
+	for ref, value := range this.m_values {
+		this.set(ref, value*scale)
+	}
+}
+
+/// Adds influence to the polygons touching a circle.
+///  @param[in]	query		The query used to find the polygons, on the same navigation mesh.
+///  @param[in]	startRef	The reference id of the polygon containing @p centerPos.
+///  @param[in]	centerPos	The center of the circle. [(x, y, z)]
+///  @param[in]	radius		The radius of the circle. [Limit: > 0]
+///  @param[in]	amount		The influence added at the center.
+///  @param[in]	falloff		True if the influence falls off linearly to zero at the radius.
+///  @param[in]	filter		The polygon filter used to find the polygons.
+/// @returns The status flags for the query.
+/// @par
+///
+/// The polygons are found with #DtNavMeshQuery.FindPolysAroundCircle, so the
+/// influence spreads along the mesh and does not cross walls. With falloff,
+/// the influence of a polygon depends on the distance from the center to the
+/// closest point of the polygon.
+///
+/// If the circle touches too many polygons, the ones closest along the mesh
+/// get the influence and #DT_BUFFER_TOO_SMALL is set.
+func (this *DtInfluenceMap) SplatCircle(query *DtNavMeshQuery, startRef DtPolyRef, centerPos []float32, radius, amount float32,
+	falloff bool, filter *DtQueryFilter) DtStatus {
+	if query == nil || !(radius > 0) {
+		return DT_FAILURE | DT_INVALID_PARAM
+	}
+	const MAX_POLYS int = 256
+	var polys [MAX_POLYS]DtPolyRef
+	var npolys int
+	status := query.FindPolysAroundCircle(startRef, centerPos, radius, filter, polys[:], nil, nil, &npolys, MAX_POLYS)
+	if DtStatusFailed(status) {
+		return status
+	}
+	for i := 0; i < npolys; i++ {
+		weight := float32(1)
+		if falloff {
+			var closest [3]float32
+			query.ClosestPointOnPoly(polys[i], centerPos, closest[:], nil)
+			weight = 1 - DtVdist2D(centerPos, closest[:])/radius
+			if weight <= 0 {
+				continue
+			}
+		}
+		this.set(polys[i], this.m_values[polys[i]]+DtMathMulf(amount, weight))
+	}
+	return status
+}
+
+/// Adds influence to the polygons touching a convex shape.
+///  @param[in]	query		The query used to find the polygons, on the same navigation mesh.
+///  @param[in]	startRef	The reference id of the polygon where the search starts.
+///  @param[in]	verts		The vertices describing the convex shape. (CCW) [(x, y, z) * @p nverts]
+///  @param[in]	nverts		The number of vertices in the shape.
+///  @param[in]	amount		The influence added to each polygon.
+///  @param[in]	filter		The polygon filter used to find the polygons.
+/// @returns The status flags for the query.
+/// @see #DtNavMeshQuery.FindPolysAroundShape
+func (this *DtInfluenceMap) SplatShape(query *DtNavMeshQuery, startRef DtPolyRef, verts []float32, nverts int,
+	amount float32, filter *DtQueryFilter) DtStatus {
+	if query == nil {
+		return DT_FAILURE | DT_INVALID_PARAM
+	}
+	const MAX_POLYS int = 256
+	var polys [MAX_POLYS]DtPolyRef
+	var npolys int
+	status := query.FindPolysAroundShape(startRef, verts, nverts, filter, polys[:], nil, nil, &npolys, MAX_POLYS)
+	if DtStatusFailed(status) {
+		return status
+	}
+	for i := 0; i < npolys; i++ {
+		this.set(polys[i], this.m_values[polys[i]]+amount)
+	}
+	return status
+}
+
+/// Implements #DtNavMeshListener.
+func (this *DtInfluenceMap) OnTileAdded(mesh *DtNavMesh, tile *DtMeshTile) {
+}
+
+/// Implements #DtNavMeshListener.
+func (this *DtInfluenceMap) OnTileRemoved(mesh *DtNavMesh, tile *DtMeshTile) {
+	base := mesh.GetPolyRefBase(tile)
+	it, salt := mesh.DecodePolyIdTile(base), mesh.DecodePolyIdSalt(base)
+	for ref := range this.m_values {
+		if mesh.DecodePolyIdTile(ref) == it && mesh.DecodePolyIdSalt(ref) == salt {
+			delete(this.m_values, ref)
+		}
+	}
+}
+
+/// Implements #DtNavMeshListener.
+func (this *DtInfluenceMap) OnPolyChanged(mesh *DtNavMesh, ref DtPolyRef) {
+}
+
+/// A custom filter implementation adding the influence of the polygons to
+/// their cost. (See #DtQueryFilter.SetCustom)
+///
+/// The cost of moving through a polygon becomes the default cost plus the
+/// distance times the influence of the polygon times @p Weight. Negative
+/// influences are ignored, as costs lower than the distance break the A* search.
+type DtInfluenceCost struct {
+	Influence *DtInfluenceMap ///< The influence map.
+	Weight    float32         ///< The scale of the influence. [Limit: >= 0]
+}
+
+/// Implements #DtQueryFilterCustom.
+func (this *DtInfluenceCost) PassFilter(filter *DtQueryFilter, ref DtPolyRef, tile *DtMeshTile, poly *DtPoly) bool {
+	return filter.DefaultPassFilter(ref, tile, poly)
+}
+
+/// Implements #DtQueryFilterCustom.
+func (this *DtInfluenceCost) GetCost(filter *DtQueryFilter, pa, pb []float32,
+	prevRef DtPolyRef, prevTile *DtMeshTile, prevPoly *DtPoly,
+	curRef DtPolyRef, curTile *DtMeshTile, curPoly *DtPoly,
+	nextRef DtPolyRef, nextTile *DtMeshTile, nextPoly *DtPoly) float32 {
+	cost := filter.DefaultGetCost(pa, pb, prevRef, prevTile, prevPoly, curRef, curTile, curPoly, nextRef, nextTile, nextPoly)
+	if value := this.Influence.Get(curRef); value > 0 {
+		cost += DtMathMulf(DtVdist(pa, pb)*value, this.Weight)
+	}
+	return cost
+}
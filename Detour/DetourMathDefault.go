@@ -0,0 +1,14 @@
+//go:build !deterministic
+// +build !deterministic
+
+package detour
+
+/// True if Detour is built with the deterministic tag. (See #DtMathMulf)
+const DT_DETERMINISTIC bool = false
+
+/// Returns the product of two values.
+/// @par
+///
+/// Built without the deterministic tag, the compiler is free to fuse the
+/// product with an addition using it. (See the deterministic version.)
+func DtMathMulf(a, b float32) float32 { return a * b }
@@ -0,0 +1,23 @@
+//go:build deterministic
+// +build deterministic
+
+package detour
+
+/// True if Detour is built with the deterministic tag. (See #DtMathMulf)
+const DT_DETERMINISTIC bool = true
+
+/// Returns the product of two values, rounded to float32.
+/// @par
+///
+/// The Go compiler may fuse a multiplication and the addition using its result
+/// into a single FMA instruction, which skips the rounding of the product. It
+/// does so on arm64, ppc64, s390x, riscv64, loong64, and amd64 with GOAMD64=v3,
+/// so the same query gives slightly different results on different platforms.
+///
+/// Built with the deterministic tag, the explicit conversion keeps the product
+/// from being fused, and the queries give the same results on all platforms.
+/// Without it, this is a plain multiplication the compiler is free to fuse.
+///
+/// #DtMathSqrtf needs no such care, math.Sqrt is correctly rounded on all
+/// platforms and so is its rounding to float32.
+func DtMathMulf(a, b float32) float32 { return float32(a * b) }
@@ -0,0 +1,252 @@
+package detour
+
+import "math"
+
+/// Finds the path to the cheapest of several goals.
+///  @param[in]		startRef	The reference id of the start polygon.
+///  @param[in]		startPos	A position within the start polygon. [(x, y, z)]
+///  @param[in]		goalRefs	The reference ids of the goal polygons. [(polyRef) * @p goalCount]
+///  @param[in]		goalPos		A position within each goal polygon. [(x, y, z) * @p goalCount]
+///  @param[in]		goalCount	The number of goals.
+///  @param[in]		filter		The polygon filter to apply to the query.
+///  @param[out]	bestGoal	The index of the cheapest goal, or -1 if no goal was reached.
+///  @param[out]	bestCost	The cost of the path to the cheapest goal. [opt]
+///  @param[out]	path		An ordered list of polygon references representing the path. (Start to goal.)
+///  							[(polyRef) * @p pathCount]
+///  @param[out]	pathCount	The number of polygons returned in the @p path array.
+///  @param[in]		maxPath		The maximum number of polygons the @p path array can hold. [Limit: >= 1]
+/// @returns The status flags for the query.
+/// @par
+///
+/// This is a single A* search towards all the goals, using the distance to
+/// the nearest goal as heuristic. The search stops as soon as no open node can
+/// lead to a cheaper goal than the best one found, so the result is the same
+/// as calling #FindPath for every goal and keeping the cheapest path.
+///
+/// The cost of a goal is computed like the cost of the end polygon in #FindPath.
+/// Goals which fail the filter or are invalid are ignored. If no goal can be
+/// reached, the path to the polygon nearest to any goal is returned with
+/// #DT_PARTIAL_RESULT and @p bestGoal is set to -1.
+///
+/// The heuristic takes O(goalCount) per visited neighbour, so for a very large
+/// number of goals a Dijkstra search (See: #FindPolysAroundCircle) may be faster.
+func (this *DtNavMeshQuery) FindPathToAnyGoal(startRef DtPolyRef, startPos []float32,
+	goalRefs []DtPolyRef, goalPos []float32, goalCount int,
+	filter *DtQueryFilter,
+	bestGoal *int, bestCost *float32,
+	path []DtPolyRef, pathCount *int, maxPath int) DtStatus {
+	DtAssert(this.m_nav != nil)
+	DtAssert(this.m_nodePool != nil)
+	DtAssert(this.m_openList != nil)
+
+	if pathCount != nil {
+		*pathCount = 0
+	}
+	if bestGoal != nil {
+		*bestGoal = -1
+	}
+	// Validate input
+	if !this.m_nav.IsValidPolyRef(startRef) || startPos == nil || filter == nil ||
+		goalCount <= 0 || len(goalRefs) < goalCount || len(goalPos) < goalCount*3 ||
+		maxPath <= 0 || path == nil || pathCount == nil || bestGoal == nil {
+		return DT_FAILURE | DT_INVALID_PARAM
+	}
+
+	// Goals which cannot be reached are skipped by the heuristic.
+	valid := make([]bool, goalCount)
+	validCount := 0
+	for i := 0; i < goalCount; i++ {
+		valid[i] = this.IsValidPolyRef(goalRefs[i], filter)
+		if valid[i] {
+			validCount++
+		}
+	}
+	if validCount == 0 {
+		return DT_FAILURE | DT_INVALID_PARAM
+	}
+	heuristic := func(pos []float32) float32 {
+		h := float32(math.MaxFloat32)
+		for i := 0; i < goalCount; i++ {
+			if valid[i] {
+				h = DtMinFloat32(h, DtVdist(pos, goalPos[i*3:]))
+			}
+		}
+		return DtMathMulf(h, H_SCALE)
+	}
+
+	this.m_nodePool.Clear()
+	this.m_openList.Clear()
+
+	startNode := this.m_nodePool.GetNode(startRef, 0)
+	DtVcopy(startNode.Pos[:], startPos)
+	startNode.Pidx = 0
+	startNode.Cost = 0
+	startNode.Total = heuristic(startPos)
+	startNode.Id = startRef
+	startNode.Flags = DT_NODE_OPEN
+	this.m_openList.Push(startNode)
+
+	lastBestNode := startNode
+	lastBestNodeCost := startNode.Total
+
+	// The best goal found so far and the node of its polygon.
+	goal := -1
+	goalCost := float32(math.MaxFloat32)
+	var goalNode *DtNode
+
+	// Goals on the start polygon.
+	{
+		var startTile *DtMeshTile
+		var startPoly *DtPoly
+		this.m_nav.GetTileAndPolyByRefUnsafe(startRef, &startTile, &startPoly)
+		for i := 0; i < goalCount; i++ {
+			if !valid[i] || goalRefs[i] != startRef {
+				continue
+			}
+			cost := filter.GetCost(startPos, goalPos[i*3:],
+				0, nil, nil,
+				startRef, startTile, startPoly,
+				0, nil, nil)
+			if cost < goalCost {
+				goal, goalCost, goalNode = i, cost, startNode
+			}
+		}
+	}
+
+	outOfNodes := false
+
+	for !this.m_openList.Empty() {
+		// Remove node from open list and put it in closed list.
+		bestNode := this.m_openList.Pop()
+		bestNode.Flags &= ^DT_NODE_OPEN
+		bestNode.Flags |= DT_NODE_CLOSED
+
+		// No open node can lead to a cheaper goal, stop searching.
+		if bestNode.Total >= goalCost {
+			break
+		}
+
+		// Get current poly and tile.
+		// The API input has been cheked already, skip checking internal data.
+		bestRef := bestNode.Id
+		var bestTile *DtMeshTile
+		var bestPoly *DtPoly
+		this.m_nav.GetTileAndPolyByRefUnsafe(bestRef, &bestTile, &bestPoly)
+
+		// Get parent poly and tile.
+		var parentRef DtPolyRef
+		var parentTile *DtMeshTile
+		var parentPoly *DtPoly
+		if bestNode.Pidx != 0 {
+			parentRef = this.m_nodePool.GetNodeAtIdx(bestNode.Pidx).Id
+		}
+		if parentRef != 0 {
+			this.m_nav.GetTileAndPolyByRefUnsafe(parentRef, &parentTile, &parentPoly)
+		}
+
+		for i := bestPoly.FirstLink; i != DT_NULL_LINK; i = bestTile.Links[i].Next {
+			neighbourRef := bestTile.Links[i].Ref
+
+			// Skip invalid ids and do not expand back to where we came from.
+			if neighbourRef == 0 || neighbourRef == parentRef {
+				continue
+			}
+			// Get neighbour poly and tile.
+			// The API input has been cheked already, skip checking internal data.
+			var neighbourTile *DtMeshTile
+			var neighbourPoly *DtPoly
+			this.m_nav.GetTileAndPolyByRefUnsafe(neighbourRef, &neighbourTile, &neighbourPoly)
+
+			if !filter.PassFilter(neighbourRef, neighbourTile, neighbourPoly) {
+				continue
+			}
+			// deal explicitly with crossing tile boundaries
+			var crossSide uint8
+			if bestTile.Links[i].Side != 0xff {
+				crossSide = (bestTile.Links[i].Side >> 1)
+			}
+			// get the node
+			neighbourNode := this.m_nodePool.GetNode(neighbourRef, crossSide)
+			if neighbourNode == nil {
+				outOfNodes = true
+				continue
+			}
+
+			// If the node is visited the first time, calculate node position.
+			if neighbourNode.Flags == 0 {
+				this.getEdgeMidPoint2(bestRef, bestPoly, bestTile,
+					neighbourRef, neighbourPoly, neighbourTile,
+					neighbourNode.Pos[:])
+			}
+
+			// Calculate cost and heuristic.
+			curCost := filter.GetCost(bestNode.Pos[:], neighbourNode.Pos[:],
+				parentRef, parentTile, parentPoly,
+				bestRef, bestTile, bestPoly,
+				neighbourRef, neighbourTile, neighbourPoly)
+			cost := bestNode.Cost + curCost
+			h := heuristic(neighbourNode.Pos[:])
+			total := cost + h
+
+			// The node is already in open list and the new result is worse, skip.
+			if (neighbourNode.Flags&DT_NODE_OPEN) != 0 && total >= neighbourNode.Total {
+				continue
+			}
+			// The node is already visited and process, and the new result is worse, skip.
+			if (neighbourNode.Flags&DT_NODE_CLOSED) != 0 && total >= neighbourNode.Total {
+				continue
+			}
+			// Add or update the node.
+			neighbourNode.Pidx = this.m_nodePool.GetNodeIdx(bestNode)
+			neighbourNode.Id = neighbourRef
+			neighbourNode.Flags = (neighbourNode.Flags & ^DT_NODE_CLOSED)
+			neighbourNode.Cost = cost
+			neighbourNode.Total = total
+
+			if (neighbourNode.Flags & DT_NODE_OPEN) != 0 {
+				// Already in open, update node location.
+				this.m_openList.Modify(neighbourNode)
+			} else {
+				// Put the node in open list.
+				neighbourNode.Flags |= DT_NODE_OPEN
+				this.m_openList.Push(neighbourNode)
+			}
+
+			// Goals on the neighbour polygon, the last node of their path.
+			for k := 0; k < goalCount; k++ {
+				if !valid[k] || goalRefs[k] != neighbourRef {
+					continue
+				}
+				endCost := filter.GetCost(neighbourNode.Pos[:], goalPos[k*3:],
+					bestRef, bestTile, bestPoly,
+					neighbourRef, neighbourTile, neighbourPoly,
+					0, nil, nil)
+				if cost+endCost < goalCost {
+					goal, goalCost, goalNode = k, cost+endCost, neighbourNode
+				}
+			}
+
+			// Update nearest node to target so far.
+			if h < lastBestNodeCost {
+				lastBestNodeCost = h
+				lastBestNode = neighbourNode
+			}
+		}
+	}
+
+	var status DtStatus
+	if goalNode != nil {
+		status = this.getPathToNode(goalNode, path, pathCount, maxPath)
+		*bestGoal = goal
+		if bestCost != nil {
+			*bestCost = goalCost
+		}
+	} else {
+		status = this.getPathToNode(lastBestNode, path, pathCount, maxPath)
+		status |= DT_PARTIAL_RESULT
+	}
+	if outOfNodes {
+		status |= DT_OUT_OF_NODES
+	}
+	return status
+}
@@ -0,0 +1,249 @@
+package detour
+
+/// The size of the agents a navigation mesh was built for.
+type DtAgentProfile struct {
+	Radius float32 ///< The radius of the agents.
+	Height float32 ///< The height of the agents.
+	Climb  float32 ///< The maximum climb height of the agents.
+}
+
+/// Gets the agent profile of a navigation mesh from the header of its first tile.
+///  @param[in]		nav		The navigation mesh.
+///  @param[out]	profile	The agent profile of the navigation mesh.
+/// @return The status flags for the operation. Fails if the mesh has no tile.
+func DtGetAgentProfile(nav *DtNavMesh, profile *DtAgentProfile) DtStatus {
+	for i := 0; i < int(nav.GetMaxTiles()); i++ {
+		tile := nav.GetTile(i)
+		if tile == nil || tile.Header == nil {
+			continue
+		}
+		profile.Radius = tile.Header.WalkableRadius
+		profile.Height = tile.Header.WalkableHeight
+		profile.Climb = tile.Header.WalkableClimb
+		return DT_SUCCESS
+	}
+	return DT_FAILURE | DT_INVALID_PARAM
+}
+
+/// A polygon reference namespaced by the profile of its navigation mesh in a #DtMultiNavMesh.
+/// The profile index plus one is in the high 32 bits, the #DtPolyRef in the low ones,
+/// so a plain #DtPolyRef is never a valid namespaced reference.
+type DtMultiPolyRef uint64
+
+type dtMultiNavMeshProfile struct {
+	profile DtAgentProfile
+	nav     *DtNavMesh
+	query   *DtNavMeshQuery
+	path    []DtPolyRef ///< Scratch path of #DtMultiNavMesh.FindPath.
+}
+
+/// Holds navigation meshes built for different agent sizes and routes the
+/// queries to the one fitting an agent.
+///
+/// The mesh of an agent is the one built for the smallest radius at least as
+/// large as the agent's. The polygon references returned by the container are
+/// namespaced by the profile of their mesh, so references of different meshes
+/// cannot be mixed up.
+///
+/// The container does not own the navigation meshes, it owns the query objects
+/// it allocates for them.
+/// @ingroup detour
+type DtMultiNavMesh struct {
+	m_profiles []dtMultiNavMeshProfile ///< The profiles, in the order they were added.
+}
+
+/// Allocates an empty container.
+func DtAllocMultiNavMesh() *DtMultiNavMesh {
+	return &DtMultiNavMesh{}
+}
+
+/// Frees the query objects of the container.
+///  @param[in]	multi	A container allocated using #DtAllocMultiNavMesh
+func DtFreeMultiNavMesh(multi *DtMultiNavMesh) {
+	if multi == nil {
+		return
+	}
+	for i := range multi.m_profiles {
+		DtFreeNavMeshQuery(multi.m_profiles[i].query)
+	}
+	multi.m_profiles = nil
+}
+
+/// Adds a navigation mesh, its agent profile is read from its tiles. (See #DtGetAgentProfile)
+///  @param[in]		nav			The navigation mesh.
+///  @param[in]		maxNodes	The maximum number of search nodes of its query. [Limits: 0 < value <= 65535]
+///  @param[out]	profileIdx	The index of the profile of the mesh. [opt]
+/// @return The status flags for the operation.
+func (this *DtMultiNavMesh) AddNavMesh(nav *DtNavMesh, maxNodes int, profileIdx *int) DtStatus {
+	if nav == nil {
+		return DT_FAILURE | DT_INVALID_PARAM
+	}
+	var profile DtAgentProfile
+	if status := DtGetAgentProfile(nav, &profile); DtStatusFailed(status) {
+		return status
+	}
+	return this.AddNavMeshProfile(nav, &profile, maxNodes, profileIdx)
+}
+
+/// Adds a navigation mesh built for an agent profile.
+///  @param[in]		nav			The navigation mesh.
+///  @param[in]		profile		The agent profile the mesh was built for.
+///  @param[in]		maxNodes	The maximum number of search nodes of its query. [Limits: 0 < value <= 65535]
+///  @param[out]	profileIdx	The index of the profile of the mesh. [opt]
+/// @return The status flags for the operation.
+/// @par
+///
+/// Profile indices are given in the order the meshes are added.
+func (this *DtMultiNavMesh) AddNavMeshProfile(nav *DtNavMesh, profile *DtAgentProfile, maxNodes int, profileIdx *int) DtStatus {
+	if nav == nil || profile == nil || !(profile.Radius >= 0) || maxNodes <= 0 {
+		return DT_FAILURE | DT_INVALID_PARAM
+	}
+	query := DtAllocNavMeshQuery()
+	if status := query.Init(nav, maxNodes); DtStatusFailed(status) {
+		DtFreeNavMeshQuery(query)
+		return status
+	}
+	if profileIdx != nil {
+		*profileIdx = len(this.m_profiles)
+	}
+	this.m_profiles = append(this.m_profiles, dtMultiNavMeshProfile{profile: *profile, nav: nav, query: query})
+	return DT_SUCCESS
+}
+
+/// The number of profiles.
+func (this *DtMultiNavMesh) GetProfileCount() int {
+	return len(this.m_profiles)
+}
+
+/// Returns the agent profile at the index, or null if the index is out of range.
+func (this *DtMultiNavMesh) GetProfile(i int) *DtAgentProfile {
+	if i < 0 || i >= len(this.m_profiles) {
+		return nil
+	}
+	return &this.m_profiles[i].profile
+}
+
+/// Returns the navigation mesh of the profile at the index, or null if the index is out of range.
+func (this *DtMultiNavMesh) GetNavMesh(i int) *DtNavMesh {
+	if i < 0 || i >= len(this.m_profiles) {
+		return nil
+	}
+	return this.m_profiles[i].nav
+}
+
+/// Returns the query object of the profile at the index, or null if the index is out of range.
+func (this *DtMultiNavMesh) GetQuery(i int) *DtNavMeshQuery {
+	if i < 0 || i >= len(this.m_profiles) {
+		return nil
+	}
+	return this.m_profiles[i].query
+}
+
+/// Finds the profile of the navigation mesh fitting an agent.
+///  @param[in]	agentRadius		The radius of the agent.
+/// @return The index of the profile with the smallest radius at least as large
+/// 	as @p agentRadius, or -1 if the agent is too large for all the meshes.
+func (this *DtMultiNavMesh) SelectProfile(agentRadius float32) int {
+	best := -1
+	for i := range this.m_profiles {
+		r := this.m_profiles[i].profile.Radius
+		if r >= agentRadius && (best == -1 || r < this.m_profiles[best].profile.Radius) {
+			best = i
+		}
+	}
+	return best
+}
+
+/// Namespaces a polygon reference by a profile.
+///  @param[in]	profileIdx	The index of the profile of the polygon's mesh.
+///  @param[in]	ref			The polygon reference in that mesh.
+/// @return The namespaced reference, or zero if @p ref is zero.
+func (this *DtMultiNavMesh) EncodePolyRef(profileIdx int, ref DtPolyRef) DtMultiPolyRef {
+	if ref == 0 {
+		return 0
+	}
+	return DtMultiPolyRef(uint64(uint32(profileIdx+1))<<32 | uint64(ref))
+}
+
+/// Splits a namespaced polygon reference.
+///  @param[in]		ref			The namespaced reference.
+///  @param[out]	profileIdx	The index of the profile of the polygon's mesh.
+///  @param[out]	polyRef		The polygon reference in that mesh.
+/// @return The status flags for the operation. Fails if @p ref is not namespaced
+/// 	by a profile of the container, e.g. when it is a plain #DtPolyRef.
+func (this *DtMultiNavMesh) DecodePolyRef(ref DtMultiPolyRef, profileIdx *int, polyRef *DtPolyRef) DtStatus {
+	*profileIdx = int(uint32(ref>>32)) - 1
+	*polyRef = DtPolyRef(uint32(ref))
+	if *profileIdx < 0 || *profileIdx >= len(this.m_profiles) {
+		return DT_FAILURE | DT_INVALID_PARAM
+	}
+	return DT_SUCCESS
+}
+
+/// Finds the polygon nearest to the specified center point on the mesh fitting the agent.
+///  @param[in]		agentRadius	The radius of the agent.
+///  @param[in]		center		The center of the search box. [(x, y, z)]
+///  @param[in]		halfExtents	The search distance along each axis. [(x, y, z)]
+///  @param[in]		filter		The polygon filter to apply to the query.
+///  @param[out]	nearestRef	The namespaced reference id of the nearest polygon.
+///  @param[out]	nearestPt	The nearest point on the polygon. [opt] [(x, y, z)]
+/// @returns The status flags for the query.
+/// @see #DtNavMeshQuery.FindNearestPoly
+func (this *DtMultiNavMesh) FindNearestPoly(agentRadius float32, center, halfExtents []float32,
+	filter *DtQueryFilter, nearestRef *DtMultiPolyRef, nearestPt []float32) DtStatus {
+	*nearestRef = 0
+	profileIdx := this.SelectProfile(agentRadius)
+	if profileIdx < 0 {
+		return DT_FAILURE | DT_INVALID_PARAM
+	}
+	var ref DtPolyRef
+	status := this.m_profiles[profileIdx].query.FindNearestPoly(center, halfExtents, filter, &ref, nearestPt)
+	*nearestRef = this.EncodePolyRef(profileIdx, ref)
+	return status
+}
+
+/// Finds a path from the start polygon to the end polygon on the mesh fitting the agent.
+///  @param[in]		agentRadius	The radius of the agent.
+///  @param[in]		startRef	The namespaced reference id of the start polygon.
+///  @param[in]		endRef		The namespaced reference id of the end polygon.
+///  @param[in]		startPos	A position within the start polygon. [(x, y, z)]
+///  @param[in]		endPos		A position within the end polygon. [(x, y, z)]
+///  @param[in]		filter		The polygon filter to apply to the query.
+///  @param[out]	path		An ordered list of namespaced polygon references representing the path. (Start to end.)
+///  							[(polyRef) * @p pathCount]
+///  @param[out]	pathCount	The number of polygons returned in the @p path array.
+///  @param[in]		maxPath		The maximum number of polygons the @p path array can hold. [Limit: >= 1]
+/// @returns The status flags for the query.
+/// @par
+///
+/// Fails if the references are not namespaced by the profile fitting the
+/// agent, e.g. when they were found for an agent of another size or are
+/// plain #DtPolyRef.
+/// @see #DtNavMeshQuery.FindPath
+func (this *DtMultiNavMesh) FindPath(agentRadius float32, startRef, endRef DtMultiPolyRef,
+	startPos, endPos []float32, filter *DtQueryFilter,
+	path []DtMultiPolyRef, pathCount *int, maxPath int) DtStatus {
+	*pathCount = 0
+	profileIdx := this.SelectProfile(agentRadius)
+	if profileIdx < 0 || maxPath <= 0 || len(path) < maxPath {
+		return DT_FAILURE | DT_INVALID_PARAM
+	}
+	var startIdx, endIdx int
+	var start, end DtPolyRef
+	if DtStatusFailed(this.DecodePolyRef(startRef, &startIdx, &start)) ||
+		DtStatusFailed(this.DecodePolyRef(endRef, &endIdx, &end)) ||
+		startIdx != profileIdx || endIdx != profileIdx {
+		return DT_FAILURE | DT_INVALID_PARAM
+	}
+
+	p := &this.m_profiles[profileIdx]
+	if cap(p.path) < maxPath {
+		p.path = make([]DtPolyRef, maxPath)
+	}
+	p.path = p.path[:maxPath]
+	status := p.query.FindPath(start, end, startPos, endPos, filter, p.path, pathCount, maxPath)
+	for i := 0; i < *pathCount; i++ {
+		path[i] = this.EncodePolyRef(profileIdx, p.path[i])
+	}
+	return status
+}
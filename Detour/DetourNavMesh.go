@@ -94,7 +94,8 @@ const (
 type DtFindPathOptions int
 
 const (
-	DT_FINDPATH_ANY_ANGLE DtFindPathOptions = 0x02 ///< use raycasts during pathfind to "shortcut" (raycast still consider costs)
+	DT_FINDPATH_ANY_ANGLE     DtFindPathOptions = 0x02 ///< use raycasts during pathfind to "shortcut" (raycast still consider costs)
+	DT_FINDPATH_BIDIRECTIONAL DtFindPathOptions = 0x04 ///< search from both the start and the end at the same time (overrides #DT_FINDPATH_ANY_ANGLE)
 )
 
 /// Options for dtNavMeshQuery::raycast
@@ -282,6 +283,24 @@ type DtNavMeshParams struct {
 	MaxPolys   uint32     ///< The maximum number of polygons each tile can contain.
 }
 
+/// Receives notifications about changes to a navigation mesh.
+/// Used to keep data derived from the navigation mesh up to date.
+/// @see #DtNavMesh.AddListener
+/// @ingroup detour
+type DtNavMeshListener interface {
+	/// Called after a tile has been added and connected to its neighbours.
+	OnTileAdded(mesh *DtNavMesh, tile *DtMeshTile)
+
+	/// Called when a tile is removed, after it has been disconnected from its
+	/// neighbours but before its data is released. #DtNavMesh.GetTileRef still
+	/// returns the old reference of the tile.
+	OnTileRemoved(mesh *DtNavMesh, tile *DtMeshTile)
+
+	/// Called after the flags or the area of a polygon have changed. Setting
+	/// them to their current values does not call it.
+	OnPolyChanged(mesh *DtNavMesh, ref DtPolyRef)
+}
+
 /// A navigation mesh based on tiles of convex polygons.
 /// @ingroup detour
 type DtNavMesh struct {
@@ -299,6 +318,8 @@ type DtNavMesh struct {
 	m_saltBits uint32 ///< Number of salt bits in the tile ID.
 	m_tileBits uint32 ///< Number of tile bits in the tile ID.
 	m_polyBits uint32 ///< Number of poly bits in the tile ID.
+
+	m_listeners []DtNavMeshListener ///< Listeners notified of tile and polygon changes.
 }
 
 /// @{
@@ -345,7 +345,7 @@ func DtCreateNavMeshData(params *DtNavMeshCreateParams, outData *[]byte, outData
 		} else {
 			for i := 0; i < int(params.VertCount); i++ {
 				iv := params.Verts[i*3:]
-				h := params.Bmin[1] + float32(iv[1])*params.Ch
+				h := params.Bmin[1] + DtMathMulf(float32(iv[1]), params.Ch)
 				hmin = DtMinFloat32(hmin, h)
 				hmax = DtMaxFloat32(hmax, h)
 			}
@@ -571,9 +571,9 @@ func DtCreateNavMeshData(params *DtNavMeshCreateParams, outData *[]byte, outData
 	for i := 0; i < int(params.VertCount); i++ {
 		iv := params.Verts[i*3:]
 		v := navVerts[i*3:]
-		v[0] = params.Bmin[0] + float32(iv[0])*params.Cs
-		v[1] = params.Bmin[1] + float32(iv[1])*params.Ch
-		v[2] = params.Bmin[2] + float32(iv[2])*params.Cs
+		v[0] = params.Bmin[0] + DtMathMulf(float32(iv[0]), params.Cs)
+		v[1] = params.Bmin[1] + DtMathMulf(float32(iv[1]), params.Ch)
+		v[2] = params.Bmin[2] + DtMathMulf(float32(iv[2]), params.Cs)
 	}
 	// Off-mesh link vertices.
 	n := 0
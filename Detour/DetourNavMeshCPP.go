@@ -38,13 +38,13 @@ func overlapSlabs(amin, amax, bmin, bmax []float32, px, py float32) bool {
 	}
 	// Check vertical overlap.
 	ad := (amax[1] - amin[1]) / (amax[0] - amin[0])
-	ak := amin[1] - ad*amin[0]
+	ak := amin[1] - DtMathMulf(ad, amin[0])
 	bd := (bmax[1] - bmin[1]) / (bmax[0] - bmin[0])
-	bk := bmin[1] - bd*bmin[0]
-	aminy := ad*minx + ak
-	amaxy := ad*maxx + ak
-	bminy := bd*minx + bk
-	bmaxy := bd*maxx + bk
+	bk := bmin[1] - DtMathMulf(bd, bmin[0])
+	aminy := DtMathMulf(ad, minx) + ak
+	amaxy := DtMathMulf(ad, maxx) + ak
+	bminy := DtMathMulf(bd, minx) + bk
+	bmaxy := DtMathMulf(bd, maxx) + bk
 	dmin := bminy - aminy
 	dmax := bmaxy - amaxy
 
@@ -725,12 +725,12 @@ func (this *DtNavMesh) queryPolygonsInTile(tile *DtMeshTile, qmin, qmax []float3
 		maxy := DtClampFloat32(qmax[1], tbmin[1], tbmax[1]) - tbmin[1]
 		maxz := DtClampFloat32(qmax[2], tbmin[2], tbmax[2]) - tbmin[2]
 		// Quantize
-		bmin[0] = uint16(qfac*minx) & 0xfffe
-		bmin[1] = uint16(qfac*miny) & 0xfffe
-		bmin[2] = uint16(qfac*minz) & 0xfffe
-		bmax[0] = uint16(qfac*maxx+1) | 1
-		bmax[1] = uint16(qfac*maxy+1) | 1
-		bmax[2] = uint16(qfac*maxz+1) | 1
+		bmin[0] = uint16(DtMathMulf(qfac, minx)) & 0xfffe
+		bmin[1] = uint16(DtMathMulf(qfac, miny)) & 0xfffe
+		bmin[2] = uint16(DtMathMulf(qfac, minz)) & 0xfffe
+		bmax[0] = uint16(DtMathMulf(qfac, maxx)+1) | 1
+		bmax[1] = uint16(DtMathMulf(qfac, maxy)+1) | 1
+		bmax[2] = uint16(DtMathMulf(qfac, maxz)+1) | 1
 
 		// Traverse tree
 		base := this.GetPolyRefBase(tile)
@@ -997,6 +997,10 @@ func (this *DtNavMesh) AddTile(data []byte, dataSize int, flags DtTileFlags,
 		}
 	}
 
+	for _, l := range this.m_listeners {
+		l.OnTileAdded(this, tile)
+	}
+
 	if result != nil {
 		*result = this.GetTileRef(tile)
 	}
@@ -1268,6 +1272,10 @@ func (this *DtNavMesh) RemoveTile(ref DtTileRef, data *[]byte, dataSize *int) Dt
 		}
 	}
 
+	for _, l := range this.m_listeners {
+		l.OnTileRemoved(this, tile)
+	}
+
 	// Reset tile.
 	if (tile.Flags & DT_TILE_FREE_DATA) != 0 {
 		// Owns data
@@ -1312,6 +1320,23 @@ func (this *DtNavMesh) RemoveTile(ref DtTileRef, data *[]byte, dataSize *int) Dt
 	return DT_SUCCESS
 }
 
+/// Registers a listener to be notified of tile and polygon changes.
+///  @param[in]	listener	The listener to add.
+func (this *DtNavMesh) AddListener(listener DtNavMeshListener) {
+	this.m_listeners = append(this.m_listeners, listener)
+}
+
+/// Unregisters a listener added with #AddListener.
+///  @param[in]	listener	The listener to remove.
+func (this *DtNavMesh) RemoveListener(listener DtNavMeshListener) {
+	for i, l := range this.m_listeners {
+		if l == listener {
+			this.m_listeners = append(this.m_listeners[:i], this.m_listeners[i+1:]...)
+			return
+		}
+	}
+}
+
 /// Gets the tile reference for the specified tile.
 ///  @param[in]	tile	The tile.
 /// @return The tile reference of the tile.
@@ -1451,13 +1476,27 @@ func (this *DtNavMesh) RestoreTileState(tile *DtMeshTile, data []byte, maxDataSi
 	}
 
 	// Restore per poly state.
+	var changed []int
 	for i := 0; i < int(tile.Header.PolyCount); i++ {
 		p := &tile.Polys[i]
 		s := &polyStates[i]
+		if p.Flags != s.flags || p.GetArea() != s.area {
+			changed = append(changed, i)
+		}
 		p.Flags = s.flags
 		p.SetArea(s.area)
 	}
 
+	// Notify the polygons which changed, once the whole tile is restored.
+	if len(this.m_listeners) != 0 {
+		base := this.GetPolyRefBase(tile)
+		for _, i := range changed {
+			for _, l := range this.m_listeners {
+				l.OnPolyChanged(this, base|DtPolyRef(i))
+			}
+		}
+	}
+
 	return DT_SUCCESS
 }
 
@@ -1576,8 +1615,15 @@ func (this *DtNavMesh) SetPolyFlags(ref DtPolyRef, flags uint16) DtStatus {
 	poly := &tile.Polys[ip]
 
 	// Change flags.
+	if poly.Flags == flags {
+		return DT_SUCCESS
+	}
 	poly.Flags = flags
 
+	for _, l := range this.m_listeners {
+		l.OnPolyChanged(this, ref)
+	}
+
 	return DT_SUCCESS
 }
 
@@ -1630,8 +1676,15 @@ func (this *DtNavMesh) SetPolyArea(ref DtPolyRef, area uint8) DtStatus {
 	}
 	poly := &tile.Polys[ip]
 
+	if poly.GetArea() == area&0x3f {
+		return DT_SUCCESS
+	}
 	poly.SetArea(area)
 
+	for _, l := range this.m_listeners {
+		l.OnPolyChanged(this, ref)
+	}
+
 	return DT_SUCCESS
 }
 
@@ -0,0 +1,280 @@
+package detour
+
+/// The island id of polygons which do not pass the island filter.
+const DT_NULL_ISLAND uint32 = 0
+
+type dtTileIslands struct {
+	salt   uint32   ///< The salt of the tile the labels were computed for.
+	labels []uint32 ///< Island id per polygon. [Size: dtMeshHeader::polyCount]
+}
+
+/// Labels every polygon of a navigation mesh with the id of the island
+/// (connected component) it belongs to.
+///
+/// Two polygons are on the same island if a chain of links connects them,
+/// where every polygon of the chain passes the filter. Off-mesh connections
+/// are followed in both directions, so the labeling is a necessary condition
+/// for a path to exist: polygons on different islands are never connected,
+/// while a one-way connection can make two polygons share an island even
+/// though one cannot be reached from the other.
+///
+/// The labels are updated incrementally as tiles are added and removed and
+/// as polygon flags change. Only the include and exclude flags of the filter
+/// are used; call #Rebuild after changing them.
+/// @see #DtNavMeshQuery.AreConnected
+/// @ingroup detour
+type DtNavMeshIslands struct {
+	m_nav    *DtNavMesh      ///< The navigation mesh being labeled.
+	m_filter DtQueryFilter   ///< The filter polygons must pass to be labeled.
+	m_tiles  []dtTileIslands ///< Labels per tile index.
+	m_nextId uint32          ///< The next unused island id.
+	m_stack  []DtPolyRef     ///< Flood fill stack.
+}
+
+/// Allocates an island labeling for the navigation mesh, labels all its
+/// tiles and starts listening to its changes.
+///  @param[in]	nav		The navigation mesh to label.
+///  @param[in]	filter	The filter polygons must pass to be part of an island.
+/// @return The island labeling.
+func DtAllocNavMeshIslands(nav *DtNavMesh, filter *DtQueryFilter) *DtNavMeshIslands {
+	islands := &DtNavMeshIslands{
+		m_nav:    nav,
+		m_filter: *filter,
+	}
+	islands.Rebuild()
+	nav.AddListener(islands)
+	return islands
+}
+
+/// Stops listening to the navigation mesh and frees the labels.
+///  @param[in]	islands		An island labeling allocated using #DtAllocNavMeshIslands
+func DtFreeNavMeshIslands(islands *DtNavMeshIslands) {
+	if islands == nil {
+		return
+	}
+	islands.m_nav.RemoveListener(islands)
+	islands.m_tiles = nil
+	islands.m_stack = nil
+}
+
+/// Gets the filter used for labeling.
+func (this *DtNavMeshIslands) GetFilter() *DtQueryFilter { return &this.m_filter }
+
+/// Relabels the whole navigation mesh.
+func (this *DtNavMeshIslands) Rebuild() {
+	this.m_tiles = make([]dtTileIslands, this.m_nav.GetMaxTiles())
+	this.m_nextId = DT_NULL_ISLAND + 1
+	for i := range this.m_tiles {
+		tile := this.m_nav.GetTile(i)
+		if tile.Header == nil {
+			continue
+		}
+		this.m_tiles[i].salt = tile.Salt
+		this.m_tiles[i].labels = make([]uint32, tile.Header.PolyCount)
+	}
+	this.labelUnassigned()
+}
+
+/// Returns the island id of the polygon, or #DT_NULL_ISLAND if the polygon is
+/// invalid or does not pass the filter.
+///  @param[in]	ref		The polygon reference.
+func (this *DtNavMeshIslands) GetIsland(ref DtPolyRef) uint32 {
+	if label := this.label(ref); label != nil {
+		return *label
+	}
+	return DT_NULL_ISLAND
+}
+
+/// Returns true if the polygons are on the same island.
+///  @param[in]	a	The reference of the first polygon.
+///  @param[in]	b	The reference of the second polygon.
+func (this *DtNavMeshIslands) AreConnected(a, b DtPolyRef) bool {
+	ia := this.GetIsland(a)
+	return ia != DT_NULL_ISLAND && ia == this.GetIsland(b)
+}
+
+func (this *DtNavMeshIslands) label(ref DtPolyRef) *uint32 {
+	if ref == 0 {
+		return nil
+	}
+	var salt, it, ip uint32
+	this.m_nav.DecodePolyId(ref, &salt, &it, &ip)
+	if it >= uint32(len(this.m_tiles)) {
+		return nil
+	}
+	t := &this.m_tiles[it]
+	if t.labels == nil || t.salt != salt || ip >= uint32(len(t.labels)) {
+		return nil
+	}
+	return &t.labels[ip]
+}
+
+func (this *DtNavMeshIslands) passFilter(ref DtPolyRef, tile *DtMeshTile, poly *DtPoly) bool {
+	return this.m_filter.PassFilter(ref, tile, poly)
+}
+
+/// Assigns the island id to every polygon reachable from the start polygon.
+/// Polygons with a different id are relabeled, which merges their islands.
+func (this *DtNavMeshIslands) flood(startRef DtPolyRef, id uint32) {
+	*this.label(startRef) = id
+	this.m_stack = append(this.m_stack[:0], startRef)
+	for len(this.m_stack) != 0 {
+		ref := this.m_stack[len(this.m_stack)-1]
+		this.m_stack = this.m_stack[:len(this.m_stack)-1]
+
+		var tile *DtMeshTile
+		var poly *DtPoly
+		this.m_nav.GetTileAndPolyByRefUnsafe(ref, &tile, &poly)
+		for i := poly.FirstLink; i != DT_NULL_LINK; i = tile.Links[i].Next {
+			neiRef := tile.Links[i].Ref
+			label := this.label(neiRef)
+			if label == nil || *label == id {
+				continue
+			}
+			var neiTile *DtMeshTile
+			var neiPoly *DtPoly
+			this.m_nav.GetTileAndPolyByRefUnsafe(neiRef, &neiTile, &neiPoly)
+			if !this.passFilter(neiRef, neiTile, neiPoly) {
+				continue
+			}
+			*label = id
+			this.m_stack = append(this.m_stack, neiRef)
+		}
+	}
+}
+
+/// Starts a new island from every unlabeled polygon of the tile which passes the filter.
+func (this *DtNavMeshIslands) labelTile(tile *DtMeshTile) {
+	t := &this.m_tiles[this.m_nav.DecodePolyIdTile(DtPolyRef(this.m_nav.GetTileRef(tile)))]
+	base := this.m_nav.GetPolyRefBase(tile)
+	for j := range t.labels {
+		if t.labels[j] != DT_NULL_ISLAND || !this.passFilter(base|DtPolyRef(j), tile, &tile.Polys[j]) {
+			continue
+		}
+		this.flood(base|DtPolyRef(j), this.m_nextId)
+		this.m_nextId++
+	}
+}
+
+/// Starts a new island from every unlabeled polygon which passes the filter.
+func (this *DtNavMeshIslands) labelUnassigned() {
+	for i := range this.m_tiles {
+		if this.m_tiles[i].labels != nil {
+			this.labelTile(this.m_nav.GetTile(i))
+		}
+	}
+	this.mergeOffMeshLinks()
+}
+
+/// Links to the landing polygon of a one-way off-mesh connection only exist
+/// from the connection, so a flood fill started on the landing side does not
+/// reach it. Merge such islands by flooding from the connections again.
+func (this *DtNavMeshIslands) mergeOffMeshLinks() {
+	for i := range this.m_tiles {
+		t := &this.m_tiles[i]
+		if t.labels == nil {
+			continue
+		}
+		tile := this.m_nav.GetTile(i)
+		base := this.m_nav.GetPolyRefBase(tile)
+		for j := int(tile.Header.OffMeshBase); j < int(tile.Header.PolyCount); j++ {
+			id := t.labels[j]
+			if id == DT_NULL_ISLAND {
+				continue
+			}
+			poly := &tile.Polys[j]
+			for k := poly.FirstLink; k != DT_NULL_LINK; k = tile.Links[k].Next {
+				if label := this.label(tile.Links[k].Ref); label != nil && *label != DT_NULL_ISLAND && *label != id {
+					this.flood(base|DtPolyRef(j), id)
+					break
+				}
+			}
+		}
+	}
+}
+
+/// Clears the labels of the islands and labels their polygons again.
+/// Used when polygons are removed from the islands, which may split them.
+func (this *DtNavMeshIslands) relabel(dirty map[uint32]bool) {
+	if len(dirty) == 0 {
+		return
+	}
+	for i := range this.m_tiles {
+		t := &this.m_tiles[i]
+		for j := range t.labels {
+			if dirty[t.labels[j]] {
+				t.labels[j] = DT_NULL_ISLAND
+			}
+		}
+	}
+	this.labelUnassigned()
+}
+
+func (this *DtNavMeshIslands) OnTileAdded(mesh *DtNavMesh, tile *DtMeshTile) {
+	it := mesh.DecodePolyIdTile(DtPolyRef(mesh.GetTileRef(tile)))
+	t := &this.m_tiles[it]
+	t.salt = tile.Salt
+	t.labels = make([]uint32, tile.Header.PolyCount)
+	this.labelTile(tile)
+	this.mergeOffMeshLinks()
+}
+
+func (this *DtNavMeshIslands) OnTileRemoved(mesh *DtNavMesh, tile *DtMeshTile) {
+	it := mesh.DecodePolyIdTile(DtPolyRef(mesh.GetTileRef(tile)))
+	t := &this.m_tiles[it]
+	dirty := make(map[uint32]bool)
+	for _, id := range t.labels {
+		if id != DT_NULL_ISLAND {
+			dirty[id] = true
+		}
+	}
+	// The tile is no longer linked from its neighbours, and without labels
+	// the flood fill does not enter it.
+	t.labels = nil
+	this.relabel(dirty)
+}
+
+func (this *DtNavMeshIslands) OnPolyChanged(mesh *DtNavMesh, ref DtPolyRef) {
+	label := this.label(ref)
+	if label == nil {
+		return
+	}
+	var tile *DtMeshTile
+	var poly *DtPoly
+	mesh.GetTileAndPolyByRefUnsafe(ref, &tile, &poly)
+	pass := this.passFilter(ref, tile, poly)
+	if pass == (*label != DT_NULL_ISLAND) {
+		return
+	}
+	if pass {
+		// The polygon may join islands together.
+		this.flood(ref, this.m_nextId)
+		this.m_nextId++
+		this.mergeOffMeshLinks()
+	} else {
+		// The polygon may split its island.
+		dirty := map[uint32]bool{*label: true}
+		*label = DT_NULL_ISLAND
+		this.relabel(dirty)
+	}
+}
+
+/// Sets the island labeling used by #AreConnected.
+///  @param[in]	islands		The island labeling of the query's navigation mesh, or null.
+func (this *DtNavMeshQuery) SetIslands(islands *DtNavMeshIslands) {
+	this.m_islands = islands
+}
+
+/// Returns true if a path may exist between the polygons.
+///  @param[in]	a	The reference of the first polygon.
+///  @param[in]	b	The reference of the second polygon.
+/// @par
+///
+/// The check takes constant time. Without an island labeling (See: #SetIslands)
+/// any two valid polygons are considered connected.
+func (this *DtNavMeshQuery) AreConnected(a, b DtPolyRef) bool {
+	if this.m_islands == nil {
+		return this.m_nav.IsValidPolyRef(a) && this.m_nav.IsValidPolyRef(b)
+	}
+	return this.m_islands.AreConnected(a, b)
+}
@@ -24,6 +24,28 @@ type DtQueryFilter struct {
 	m_areaCost     [DT_MAX_AREAS]float32 ///< Cost per area type. (Used by default implementation.)
 	m_includeFlags uint16                ///< Flags for polygons that can be visited. (Used by default implementation.)
 	m_excludeFlags uint16                ///< Flags for polygons that should not be visted. (Used by default implementation.)
+	m_custom       DtQueryFilterCustom   ///< Replaces the default implementation. [opt]
+}
+
+/// Custom polygon query behavior, see #DtQueryFilter.SetCustom.
+/// The filter the methods are called for is passed along, so the default
+/// implementation stays available through #DtQueryFilter.DefaultPassFilter
+/// and #DtQueryFilter.DefaultGetCost.
+/// @ingroup detour
+type DtQueryFilterCustom interface {
+	/// Returns true if the polygon can be visited. (I.e. Is traversable.)
+	///  @param[in]		filter	The filter the custom implementation is set on.
+	///  @param[in]		ref		The reference id of the polygon test.
+	///  @param[in]		tile	The tile containing the polygon.
+	///  @param[in]		poly	The polygon to test.
+	PassFilter(filter *DtQueryFilter, ref DtPolyRef, tile *DtMeshTile, poly *DtPoly) bool
+
+	/// Returns cost to move from the beginning to the end of a line segment
+	/// that is fully contained within a polygon, see #DtQueryFilter.GetCost.
+	GetCost(filter *DtQueryFilter, pa, pb []float32,
+		prevRef DtPolyRef, prevTile *DtMeshTile, prevPoly *DtPoly,
+		curRef DtPolyRef, curTile *DtMeshTile, curPoly *DtPoly,
+		nextRef DtPolyRef, nextTile *DtMeshTile, nextPoly *DtPoly) float32
 }
 
 /// @name Getters and setters for the default implementation data.
@@ -57,6 +79,13 @@ func (this *DtQueryFilter) GetExcludeFlags() uint16 { return this.m_excludeFlags
 /// @param[in]		flags		The new flags.
 func (this *DtQueryFilter) SetExcludeFlags(flags uint16) { this.m_excludeFlags = flags }
 
+/// Returns the custom implementation of the filter, or null.
+func (this *DtQueryFilter) GetCustom() DtQueryFilterCustom { return this.m_custom }
+
+/// Sets the custom implementation of the filter.
+/// @param[in]		custom		The custom implementation, or null for the default one.
+func (this *DtQueryFilter) SetCustom(custom DtQueryFilterCustom) { this.m_custom = custom }
+
 ///@}
 
 func DtAllocDtQueryFilter() *DtQueryFilter {
@@ -118,6 +147,9 @@ type dtQueryData struct {
 	filter           *DtQueryFilter
 	options          DtFindPathOptions
 	raycastLimitSqr  float32
+	meetCost         float32 ///< Cost of the cheapest path found by the bidirectional search.
+	meetNode         *DtNode ///< Forward node of the cheapest bidirectional path.
+	meetBackNode     *DtNode ///< Backward node of the cheapest bidirectional path.
 }
 
 type DtNavMeshQuery struct {
@@ -126,6 +158,15 @@ type DtNavMeshQuery struct {
 	m_tinyNodePool *DtNodePool  ///< Pointer to small node pool.
 	m_nodePool     *DtNodePool  ///< Pointer to node pool.
 	m_openList     *DtNodeQueue ///< Pointer to open list queue.
+
+	m_backNodePool *DtNodePool  ///< Node pool of the backward search. [opt]
+	m_backOpenList *DtNodeQueue ///< Open list of the backward search. [opt]
+
+	m_islands     *DtNavMeshIslands    ///< Island labeling used by #AreConnected. [opt]
+	m_portalGraph *DtPortalGraph       ///< Portal graph used by #FindPathHierarchical. [opt]
+	m_corridor    map[*DtMeshTile]bool ///< The tiles #FindPath is restricted to while refining a hierarchical path. [opt]
+	m_pathCache   *DtPathCache         ///< Cache of the paths found by #FindPath. [opt]
+	m_nodeTimes   []dtNodeTime         ///< The times of the nodes of #FindTimedPath, by node index. [opt]
 }
 
 /// Gets the node pool.
@@ -40,10 +40,9 @@ import (
 ///
 /// <b>Custom Implementations</b>
 ///
-/// DT_VIRTUAL_QUERYFILTER must be defined in order to extend this class.
-///
-/// Implement a custom query filter by overriding the virtual passFilter()
-/// and getCost() functions. If this is done, both functions should be as
+/// Implement a custom query filter with the #DtQueryFilterCustom interface and
+/// set it with #SetCustom. Its passFilter() and getCost() functions are then
+/// called instead of the default ones. Both functions should be as
 /// fast as possible. Use cached local copies of data rather than accessing
 /// your own objects where possible.
 ///
@@ -67,15 +66,39 @@ func (this *DtQueryFilter) constructor() {
 func (this *DtQueryFilter) destructor() {
 }
 
-func (this *DtQueryFilter) PassFilter(_ DtPolyRef, _ *DtMeshTile, poly *DtPoly) bool {
-	return (poly.Flags&this.m_includeFlags) != 0 && (poly.Flags&this.m_excludeFlags) == 0
+func (this *DtQueryFilter) PassFilter(ref DtPolyRef, tile *DtMeshTile, poly *DtPoly) bool {
+	if this.m_custom != nil {
+		return this.m_custom.PassFilter(this, ref, tile, poly)
+	}
+	return this.DefaultPassFilter(ref, tile, poly)
 }
 
 func (this *DtQueryFilter) GetCost(pa, pb []float32,
+	prevRef DtPolyRef, prevTile *DtMeshTile, prevPoly *DtPoly,
+	curRef DtPolyRef, curTile *DtMeshTile, curPoly *DtPoly,
+	nextRef DtPolyRef, nextTile *DtMeshTile, nextPoly *DtPoly) float32 {
+	if this.m_custom != nil {
+		// The positions are often on the caller's stack, copy them so only this
+		// branch allocates when they escape into the custom implementation.
+		var a, b [3]float32
+		DtVcopy(a[:], pa)
+		DtVcopy(b[:], pb)
+		return this.m_custom.GetCost(this, a[:], b[:], prevRef, prevTile, prevPoly, curRef, curTile, curPoly, nextRef, nextTile, nextPoly)
+	}
+	return this.DefaultGetCost(pa, pb, prevRef, prevTile, prevPoly, curRef, curTile, curPoly, nextRef, nextTile, nextPoly)
+}
+
+/// The default implementation of #PassFilter, used when no custom one is set.
+func (this *DtQueryFilter) DefaultPassFilter(_ DtPolyRef, _ *DtMeshTile, poly *DtPoly) bool {
+	return (poly.Flags&this.m_includeFlags) != 0 && (poly.Flags&this.m_excludeFlags) == 0
+}
+
+/// The default implementation of #GetCost, used when no custom one is set.
+func (this *DtQueryFilter) DefaultGetCost(pa, pb []float32,
 	_ DtPolyRef, _ *DtMeshTile, _ *DtPoly,
 	_ DtPolyRef, _ *DtMeshTile, curPoly *DtPoly,
 	_ DtPolyRef, _ *DtMeshTile, _ *DtPoly) float32 {
-	return DtVdist(pa, pb) * this.m_areaCost[curPoly.GetArea()]
+	return DtMathMulf(DtVdist(pa, pb), this.m_areaCost[curPoly.GetArea()])
 }
 
 const H_SCALE float32 = 0.999 // Search heuristic scale.
@@ -115,6 +138,14 @@ func (this *DtNavMeshQuery) destructor() {
 		DtFreeNodeQueue(this.m_openList)
 		this.m_openList = nil
 	}
+	if this.m_backNodePool != nil {
+		DtFreeNodePool(this.m_backNodePool)
+		this.m_backNodePool = nil
+	}
+	if this.m_backOpenList != nil {
+		DtFreeNodeQueue(this.m_backOpenList)
+		this.m_backOpenList = nil
+	}
 }
 
 /// Initializes the query object.
@@ -631,7 +662,7 @@ func (this *DtNavMeshQuery) GetPolyHeight(ref DtPolyRef, pos []float32, height *
 		d1 := DtVdist2D(pos, v1)
 		u := d0 / (d0 + d1)
 		if height != nil {
-			*height = v0[1] + (v1[1]-v0[1])*u
+			*height = v0[1] + DtMathMulf(v1[1]-v0[1], u)
 		}
 		return DT_SUCCESS
 	} else {
@@ -775,12 +806,12 @@ func (this *DtNavMeshQuery) queryPolygonsInTile(tile *DtMeshTile, qmin, qmax []f
 		maxy := DtClampFloat32(qmax[1], tbmin[1], tbmax[1]) - tbmin[1]
 		maxz := DtClampFloat32(qmax[2], tbmin[2], tbmax[2]) - tbmin[2]
 		// Quantize
-		bmin[0] = (uint16)(qfac*minx) & 0xfffe
-		bmin[1] = (uint16)(qfac*miny) & 0xfffe
-		bmin[2] = (uint16)(qfac*minz) & 0xfffe
-		bmax[0] = (uint16)(qfac*maxx+1) | 1
-		bmax[1] = (uint16)(qfac*maxy+1) | 1
-		bmax[2] = (uint16)(qfac*maxz+1) | 1
+		bmin[0] = (uint16)(DtMathMulf(qfac, minx)) & 0xfffe
+		bmin[1] = (uint16)(DtMathMulf(qfac, miny)) & 0xfffe
+		bmin[2] = (uint16)(DtMathMulf(qfac, minz)) & 0xfffe
+		bmax[0] = (uint16)(DtMathMulf(qfac, maxx)+1) | 1
+		bmax[1] = (uint16)(DtMathMulf(qfac, maxy)+1) | 1
+		bmax[2] = (uint16)(DtMathMulf(qfac, maxz)+1) | 1
 
 		// Traverse tree
 		base := this.m_nav.GetPolyRefBase(tile)
@@ -984,6 +1015,10 @@ func (this *DtNavMeshQuery) QueryPolygons2(center, halfExtents []float32,
 /// The start and end positions are used to calculate traversal costs.
 /// (The y-values impact the result.)
 ///
+/// With a path cache (See: #SetPathCache) complete paths are reused for the
+/// same start and end polygons and filter settings, whatever the positions.
+/// A cache hit does not search, so the node pool is left empty. (See: #IsInClosedList)
+///
 func (this *DtNavMeshQuery) FindPath(startRef, endRef DtPolyRef,
 	startPos, endPos []float32,
 	filter *DtQueryFilter,
@@ -1005,15 +1040,25 @@ func (this *DtNavMeshQuery) FindPath(startRef, endRef DtPolyRef,
 		*pathCount = 1
 		return DT_SUCCESS
 	}
-
+	// The paths restricted to a corridor, or found with a custom filter, are not cached.
+	cache := this.m_pathCache
+	if this.m_corridor != nil || filter.m_custom != nil {
+		cache = nil
+	}
 	this.m_nodePool.Clear()
 	this.m_openList.Clear()
 
+	if cache != nil {
+		if status := cache.Get(startRef, endRef, filter, path, pathCount, maxPath); DtStatusSucceed(status) {
+			return status
+		}
+	}
+
 	startNode := this.m_nodePool.GetNode(startRef, 0)
 	DtVcopy(startNode.Pos[:], startPos)
 	startNode.Pidx = 0
 	startNode.Cost = 0
-	startNode.Total = DtVdist(startPos, endPos) * H_SCALE
+	startNode.Total = DtMathMulf(DtVdist(startPos, endPos), H_SCALE)
 	startNode.Id = startRef
 	startNode.Flags = DT_NODE_OPEN
 	this.m_openList.Push(startNode)
@@ -1069,6 +1114,10 @@ func (this *DtNavMeshQuery) FindPath(startRef, endRef DtPolyRef,
 			if !filter.PassFilter(neighbourRef, neighbourTile, neighbourPoly) {
 				continue
 			}
+			// Stay within the tiles of the hierarchical path being refined.
+			if this.m_corridor != nil && !this.m_corridor[neighbourTile] {
+				continue
+			}
 			// deal explicitly with crossing tile boundaries
 			var crossSide uint8
 			if bestTile.Links[i].Side != 0xff {
@@ -1113,7 +1162,7 @@ func (this *DtNavMeshQuery) FindPath(startRef, endRef DtPolyRef,
 					bestRef, bestTile, bestPoly,
 					neighbourRef, neighbourTile, neighbourPoly)
 				cost = bestNode.Cost + curCost
-				heuristic = DtVdist(neighbourNode.Pos[:], endPos) * H_SCALE
+				heuristic = DtMathMulf(DtVdist(neighbourNode.Pos[:], endPos), H_SCALE)
 			}
 
 			total := cost + heuristic
@@ -1158,6 +1207,9 @@ func (this *DtNavMeshQuery) FindPath(startRef, endRef DtPolyRef,
 	if outOfNodes {
 		status |= DT_OUT_OF_NODES
 	}
+	if cache != nil && status == DT_SUCCESS {
+		cache.Put(startRef, endRef, filter, path, *pathCount)
+	}
 	return status
 }
 
@@ -1212,6 +1264,10 @@ func (this *DtNavMeshQuery) getPathToNode(endNode *DtNode, path []DtPolyRef, pat
 /// The @p filter pointer is stored and used for the duration of the sliced
 /// path query.
 ///
+/// With #DT_FINDPATH_BIDIRECTIONAL a second search runs from the end polygon
+/// using its own node pool, sized like the one given to #Init. If the two
+/// searches do not meet, the partial path of the forward search is returned.
+///
 func (this *DtNavMeshQuery) InitSlicedFindPath(startRef, endRef DtPolyRef,
 	startPos, endPos []float32,
 	filter *DtQueryFilter, options DtFindPathOptions) DtStatus {
@@ -1258,7 +1314,7 @@ func (this *DtNavMeshQuery) InitSlicedFindPath(startRef, endRef DtPolyRef,
 	DtVcopy(startNode.Pos[:], startPos)
 	startNode.Pidx = 0
 	startNode.Cost = 0
-	startNode.Total = DtVdist(startPos, endPos) * H_SCALE
+	startNode.Total = DtMathMulf(DtVdist(startPos, endPos), H_SCALE)
 	startNode.Id = startRef
 	startNode.Flags = DT_NODE_OPEN
 	this.m_openList.Push(startNode)
@@ -1267,6 +1323,12 @@ func (this *DtNavMeshQuery) InitSlicedFindPath(startRef, endRef DtPolyRef,
 	this.m_query.lastBestNode = startNode
 	this.m_query.lastBestNodeCost = startNode.Total
 
+	if (options & DT_FINDPATH_BIDIRECTIONAL) != 0 {
+		if DtStatusFailed(this.initBackwardSearch()) {
+			this.m_query.status = DT_FAILURE | DT_OUT_OF_MEMORY
+		}
+	}
+
 	return this.m_query.status
 }
 
@@ -1283,6 +1345,9 @@ func (this *DtNavMeshQuery) UpdateSlicedFindPath(maxIter int, doneIters *int) Dt
 		this.m_query.status = DT_FAILURE
 		return DT_FAILURE
 	}
+	if (this.m_query.options & DT_FINDPATH_BIDIRECTIONAL) != 0 {
+		return this.updateSlicedFindPathBidirectional(maxIter, doneIters)
+	}
 
 	rayHit := DtRaycastHit{}
 	rayHit.MaxPath = 0
@@ -1423,7 +1488,7 @@ func (this *DtNavMeshQuery) UpdateSlicedFindPath(maxIter int, doneIters *int) Dt
 				cost = cost + endCost
 				heuristic = 0
 			} else {
-				heuristic = DtVdist(neighbourNode.Pos[:], this.m_query.endPos[:]) * H_SCALE
+				heuristic = DtMathMulf(DtVdist(neighbourNode.Pos[:], this.m_query.endPos[:]), H_SCALE)
 			}
 
 			total := cost + heuristic
@@ -1500,6 +1565,9 @@ func (this *DtNavMeshQuery) FinalizeSlicedFindPath(path []DtPolyRef, pathCount *
 		// Special case: the search starts and ends at same poly.
 		path[n] = this.m_query.startRef
 		n++
+	} else if this.m_query.meetNode != nil {
+		// The bidirectional search met, join the two halves.
+		this.m_query.status |= this.getBidirectionalPath(path, &n, maxPath)
 	} else {
 		// Reverse the path.
 		DtAssert(this.m_query.lastBestNode != nil)
@@ -2074,7 +2142,7 @@ func (this *DtNavMeshQuery) MoveAlongSurface(startRef DtPolyRef, startPos, endPo
 	var searchPos [3]float32
 	var searchRadSqr float32
 	DtVlerp(searchPos[:], startPos, endPos, 0.5)
-	searchRadSqr = DtSqrFloat32(DtVdist(startPos, endPos)/2.0 + 0.001)
+	searchRadSqr = DtSqrFloat32(DtMathMulf(DtVdist(startPos, endPos), 0.5) + 0.001)
 
 	var verts [DT_VERTS_PER_POLYGON * 3]float32
 
@@ -2582,14 +2650,14 @@ func (this *DtNavMeshQuery) Raycast2(startRef DtPolyRef, startPos, endPos []floa
 			if link.Side == 0 || link.Side == 4 {
 				// Calculate link size.
 				s := float32(1.0 / 255.0)
-				lmin := left[2] + (right[2]-left[2])*(float32(link.Bmin)*s)
-				lmax := left[2] + (right[2]-left[2])*(float32(link.Bmax)*s)
+				lmin := left[2] + DtMathMulf(right[2]-left[2], float32(link.Bmin)*s)
+				lmax := left[2] + DtMathMulf(right[2]-left[2], float32(link.Bmax)*s)
 				if lmin > lmax {
 					DtSwapFloat32(&lmin, &lmax)
 				}
 
 				// Find Z intersection.
-				z := startPos[2] + (endPos[2]-startPos[2])*tmax
+				z := startPos[2] + DtMathMulf(endPos[2]-startPos[2], tmax)
 				if z >= lmin && z <= lmax {
 					nextRef = link.Ref
 					break
@@ -2597,14 +2665,14 @@ func (this *DtNavMeshQuery) Raycast2(startRef DtPolyRef, startPos, endPos []floa
 			} else if link.Side == 2 || link.Side == 6 {
 				// Calculate link size.
 				s := float32(1.0 / 255.0)
-				lmin := left[0] + (right[0]-left[0])*(float32(link.Bmin)*s)
-				lmax := left[0] + (right[0]-left[0])*(float32(link.Bmax)*s)
+				lmin := left[0] + DtMathMulf(right[0]-left[0], float32(link.Bmin)*s)
+				lmax := left[0] + DtMathMulf(right[0]-left[0], float32(link.Bmax)*s)
 				if lmin > lmax {
 					DtSwapFloat32(&lmin, &lmax)
 				}
 
 				// Find X intersection.
-				x := startPos[0] + (endPos[0]-startPos[0])*tmax
+				x := startPos[0] + DtMathMulf(endPos[0]-startPos[0], tmax)
 				if x >= lmin && x <= lmax {
 					nextRef = link.Ref
 					break
@@ -2629,7 +2697,7 @@ func (this *DtNavMeshQuery) Raycast2(startRef DtPolyRef, startPos, endPos []floa
 			} else {
 				s = diff[2] / eDir[2]
 			}
-			curPos[1] = e1[1] + eDir[1]*s
+			curPos[1] = e1[1] + DtMathMulf(eDir[1], s)
 
 			hit.PathCost += filter.GetCost(lastPos[:], curPos[:], prevRef, prevTile, prevPoly, curRef, tile, poly, nextRef, nextTile, nextPoly)
 		}
@@ -3392,12 +3460,12 @@ func (this *DtNavMeshQuery) GetPolyWallSegments(ref DtPolyRef, filter *DtQueryFi
 		for k := 1; k < nints; k++ {
 			// Portal segment.
 			if storePortals && ints[k].ref != 0 {
-				tmin := ints[k].tmin / 255.0
-				tmax := ints[k].tmax / 255.0
+				tmin := float32(ints[k].tmin) / 255.0
+				tmax := float32(ints[k].tmax) / 255.0
 				if n < maxSegments {
 					seg := segmentVerts[n*6:]
-					DtVlerp(seg[0:], vj, vi, float32(tmin))
-					DtVlerp(seg[3:], vj, vi, float32(tmax))
+					DtVlerp(seg[0:], vj, vi, tmin)
+					DtVlerp(seg[3:], vj, vi, tmax)
 					if segmentRefs != nil {
 						segmentRefs[n] = ints[k].ref
 					}
@@ -3411,12 +3479,12 @@ func (this *DtNavMeshQuery) GetPolyWallSegments(ref DtPolyRef, filter *DtQueryFi
 			imin := ints[k-1].tmax
 			imax := ints[k].tmin
 			if imin != imax {
-				tmin := imin / 255.0
-				tmax := imax / 255.0
+				tmin := float32(imin) / 255.0
+				tmax := float32(imax) / 255.0
 				if n < maxSegments {
 					seg := segmentVerts[n*6:]
-					DtVlerp(seg[0:], vj, vi, float32(tmin))
-					DtVlerp(seg[3:], vj, vi, float32(tmax))
+					DtVlerp(seg[0:], vj, vi, tmin)
+					DtVlerp(seg[3:], vj, vi, tmax)
 					if segmentRefs != nil {
 						segmentRefs[n] = 0
 					}
@@ -3547,9 +3615,9 @@ func (this *DtNavMeshQuery) FindDistanceToWall(startRef DtPolyRef, centerPos []f
 			// Hit wall, update radius.
 			radiusSqr = distSqr
 			// Calculate hit pos.
-			hitPos[0] = vj[0] + (vi[0]-vj[0])*tseg
-			hitPos[1] = vj[1] + (vi[1]-vj[1])*tseg
-			hitPos[2] = vj[2] + (vi[2]-vj[2])*tseg
+			hitPos[0] = vj[0] + DtMathMulf(vi[0]-vj[0], tseg)
+			hitPos[1] = vj[1] + DtMathMulf(vi[1]-vj[1], tseg)
+			hitPos[2] = vj[2] + DtMathMulf(vi[2]-vj[2], tseg)
 		}
 
 		for i := bestPoly.FirstLink; i != DT_NULL_LINK; i = bestTile.Links[i].Next {
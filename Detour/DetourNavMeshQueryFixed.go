@@ -0,0 +1,1401 @@
+package detour
+
+import "math"
+
+// The search state of a node of the fixed-point query, by node index.
+type dtFixedNode struct {
+	pos   [3]DtFixed ///< Position of the node.
+	cost  int64      ///< Cost up to the node. (Q16.16)
+	total int64      ///< Cost up to the node plus the heuristic. (Q16.16)
+}
+
+type dtFixedOpenEntry struct {
+	idx   uint32
+	total int64
+}
+
+/// A binary heap of node indices ordered by total cost. A node is pushed
+/// again when its cost improves, the stale entries are skipped when popped.
+type dtFixedOpenList []dtFixedOpenEntry
+
+func (this *dtFixedOpenList) push(idx uint32, total int64) {
+	q := append(*this, dtFixedOpenEntry{idx, total})
+	i := len(q) - 1
+	for i > 0 {
+		parent := (i - 1) / 2
+		if q[parent].total <= q[i].total {
+			break
+		}
+		q[parent], q[i] = q[i], q[parent]
+		i = parent
+	}
+	*this = q
+}
+
+func (this *dtFixedOpenList) pop() dtFixedOpenEntry {
+	q := *this
+	top := q[0]
+	last := len(q) - 1
+	q[0] = q[last]
+	q = q[:last]
+	i := 0
+	for {
+		child := i*2 + 1
+		if child >= len(q) {
+			break
+		}
+		if child+1 < len(q) && q[child+1].total < q[child].total {
+			child++
+		}
+		if q[i].total <= q[child].total {
+			break
+		}
+		q[i], q[child] = q[child], q[i]
+		i = child
+	}
+	*this = q
+	return top
+}
+
+/// The search heuristic scale of the fixed-point query, #H_SCALE in Q16.16.
+var dtFixedHScale = int64(DtFixedFromFloat(H_SCALE))
+
+/// A fixed-point variant of the core queries of #DtNavMeshQuery, for a
+/// navigation runtime using integer arithmetic only.
+///
+/// The queries read the same tiles as the float query. The float vertices and
+/// filter costs are converted to Q16.16 (See #DtFixed) with integer operations
+/// when they are read, and all the geometry is then done in integers, so the
+/// results are the same on every platform and compiler.
+///
+/// The results match the ones of #DtNavMeshQuery within the rounding of the
+/// fixed-point values: the positions are within 1/1024 units of the float ones
+/// and the raycast hit parameters within 1/16384. Where the float results
+/// depend on that rounding, they can differ:
+/// - Two routes costing the same within the rounding can swap in #FindPath.
+/// - A straight path crossing a portal within the rounding of a corner can
+///   have an extra point next to the corner, or not.
+/// - A ray ending within the rounding of a wall can hit it, or reach its end.
+/// - A search running out of nodes can stop at other polygons.
+///
+/// The coordinates must lie within 8192 units of the origin.
+///
+/// The filter's flags, area costs and custom #DtQueryFilterCustom.PassFilter are
+/// used, but a custom #DtQueryFilterCustom.GetCost is not: the cost of a move is
+/// its length times the cost of the area moved through, as #DtQueryFilter.DefaultGetCost.
+///
+/// The path cache and the corridor of #DtNavMeshQuery are not used.
+/// @ingroup detour
+type DtNavMeshQueryFixed struct {
+	m_nav          *DtNavMesh      ///< Pointer to navmesh data.
+	m_nodePool     *DtNodePool     ///< Pointer to node pool.
+	m_nodes        []dtFixedNode   ///< The fixed-point state of the nodes of the node pool.
+	m_openList     dtFixedOpenList ///< The open list of the path searches.
+	m_tinyNodePool *DtNodePool     ///< Pointer to small node pool.
+}
+
+/// Allocates a fixed-point query object.
+/// @return A query object that is ready for initialization.
+/// @ingroup detour
+func DtAllocNavMeshQueryFixed() *DtNavMeshQueryFixed {
+	return &DtNavMeshQueryFixed{}
+}
+
+/// Frees the specified fixed-point query object.
+///  @param[in]		query		A query object allocated using #DtAllocNavMeshQueryFixed
+/// @ingroup detour
+func DtFreeNavMeshQueryFixed(query *DtNavMeshQueryFixed) {
+	if query == nil {
+		return
+	}
+	DtFreeNodePool(query.m_nodePool)
+	DtFreeNodePool(query.m_tinyNodePool)
+	query.m_nodePool = nil
+	query.m_tinyNodePool = nil
+	query.m_nodes = nil
+	query.m_openList = nil
+}
+
+/// Initializes the query object.
+///  @param[in]		nav			Pointer to the dtNavMesh object to use for all queries.
+///  @param[in]		maxNodes	Maximum number of search nodes. [Limits: 0 < value <= 65535]
+/// @returns The status flags for the query.
+func (this *DtNavMeshQueryFixed) Init(nav *DtNavMesh, maxNodes int) DtStatus {
+	if nav == nil || maxNodes <= 0 || maxNodes > int(DT_NULL_IDX) || maxNodes > int((1<<DT_NODE_PARENT_BITS)-1) {
+		return DT_FAILURE | DT_INVALID_PARAM
+	}
+	this.m_nav = nav
+
+	if this.m_nodePool == nil || this.m_nodePool.GetMaxNodes() < uint32(maxNodes) {
+		if this.m_nodePool != nil {
+			DtFreeNodePool(this.m_nodePool)
+		}
+		this.m_nodePool = DtAllocNodePool(uint32(maxNodes), DtNextPow2(uint32(maxNodes/4)))
+		this.m_nodes = make([]dtFixedNode, maxNodes)
+	} else {
+		this.m_nodePool.Clear()
+	}
+
+	if this.m_tinyNodePool == nil {
+		this.m_tinyNodePool = DtAllocNodePool(64, 32)
+	} else {
+		this.m_tinyNodePool.Clear()
+	}
+	return DT_SUCCESS
+}
+
+/// Gets the navigation mesh the query object is using.
+/// @return The navigation mesh the query object is using.
+func (this *DtNavMeshQueryFixed) GetAttachedNavMesh() *DtNavMesh {
+	return this.m_nav
+}
+
+// Returns the fixed-point state of a node of the node pool.
+func (this *DtNavMeshQueryFixed) fixedNode(node *DtNode) *dtFixedNode {
+	return &this.m_nodes[this.m_nodePool.GetNodeIdx(node)-1]
+}
+
+// Collects the fixed-point vertices of a polygon.
+func (this *DtNavMeshQueryFixed) getPolyVerts(tile *DtMeshTile, poly *DtPoly, verts []DtFixed) int {
+	nv := int(poly.VertCount)
+	for i := 0; i < nv; i++ {
+		DtVtoFixed(verts[i*3:], tile.Verts[poly.Verts[i]*3:])
+	}
+	return nv
+}
+
+// Returns the cost of moving from pa to pb through the polygon. (Q16.16)
+func (this *DtNavMeshQueryFixed) getCost(filter *DtQueryFilter, pa, pb []DtFixed, poly *DtPoly) int64 {
+	return dtFixedRound(dtFixedVdist(pa, pb) * int64(DtFixedFromFloat(filter.m_areaCost[poly.GetArea()])))
+}
+
+// Returns the heuristic cost from pa to pb. (Q16.16)
+func (this *DtNavMeshQueryFixed) getHeuristic(pa, pb []DtFixed) int64 {
+	return dtFixedRound(dtFixedVdist(pa, pb) * dtFixedHScale)
+}
+
+/// Finds the closest point on the specified polygon.
+///  @param[in]		ref			The reference id of the polygon.
+///  @param[in]		pos			The position to check. [(x, y, z)]
+///  @param[out]	closest		The closest point on the polygon. [(x, y, z)]
+///  @param[out]	posOverPoly	True of the position is over the polygon. [opt]
+/// @returns The status flags for the query.
+/// @see #DtNavMeshQuery.ClosestPointOnPoly
+func (this *DtNavMeshQueryFixed) ClosestPointOnPoly(ref DtPolyRef, pos, closest []DtFixed, posOverPoly *bool) DtStatus {
+	DtAssert(this.m_nav != nil)
+	var tile *DtMeshTile
+	var poly *DtPoly
+	if DtStatusFailed(this.m_nav.GetTileAndPolyByRef(ref, &tile, &poly)) {
+		return DT_FAILURE | DT_INVALID_PARAM
+	}
+	var verts [DT_VERTS_PER_POLYGON * 3]DtFixed
+	nv := this.getPolyVerts(tile, poly, verts[:])
+
+	// Off-mesh connections don't have detail polygons.
+	if poly.GetType() == DT_POLYTYPE_OFFMESH_CONNECTION {
+		d0 := dtFixedVdist(pos, verts[0:])
+		d1 := dtFixedVdist(pos, verts[3:])
+		u := DT_FIXED_ONE
+		if d0+d1 > 0 {
+			u = DtFixed(dtFixedMulDiv(d0, int64(DT_FIXED_ONE), d0+d1))
+		}
+		dtFixedVlerp(closest, verts[0:], verts[3:], u)
+		if posOverPoly != nil {
+			*posOverPoly = false
+		}
+		return DT_SUCCESS
+	}
+
+	// Clamp point to be inside the polygon.
+	var edged [DT_VERTS_PER_POLYGON]int64
+	var edget [DT_VERTS_PER_POLYGON]DtFixed
+	dtFixedVcopy(closest, pos)
+	if !dtFixedDistancePtPolyEdgesSqr(pos, verts[:], nv, edged[:], edget[:]) {
+		// Point is outside the polygon, clamp to nearest edge.
+		imin := 0
+		for i := 1; i < nv; i++ {
+			if edged[i] < edged[imin] {
+				imin = i
+			}
+		}
+		dtFixedVlerp(closest, verts[imin*3:], verts[((imin+1)%nv)*3:], edget[imin])
+		if posOverPoly != nil {
+			*posOverPoly = false
+		}
+	} else if posOverPoly != nil {
+		*posOverPoly = true
+	}
+
+	// Find height at the location.
+	var h DtFixed
+	if this.getDetailHeight(tile, poly, this.m_nav.DecodePolyIdPoly(ref), closest, &h) {
+		closest[1] = h
+	}
+	return DT_SUCCESS
+}
+
+// Finds the height of the detail mesh of a polygon at a position.
+func (this *DtNavMeshQueryFixed) getDetailHeight(tile *DtMeshTile, poly *DtPoly, ip uint32, pos []DtFixed, h *DtFixed) bool {
+	pd := &tile.DetailMeshes[ip]
+	var v [3][3]DtFixed
+	for j := 0; j < int(pd.TriCount); j++ {
+		t := tile.DetailTris[(int(pd.TriBase)+j)*4:]
+		for k := 0; k < 3; k++ {
+			if t[k] < poly.VertCount {
+				DtVtoFixed(v[k][:], tile.Verts[poly.Verts[t[k]]*3:])
+			} else {
+				DtVtoFixed(v[k][:], tile.DetailVerts[(pd.VertBase+uint32(t[k]-poly.VertCount))*3:])
+			}
+		}
+		if dtFixedClosestHeightPointTriangle(pos, v[0][:], v[1][:], v[2][:], h) {
+			return true
+		}
+	}
+	return false
+}
+
+/// Returns a point on the boundary closest to the source point if the source point is outside the
+/// polygon's xz-bounds.
+///  @param[in]		ref			The reference id to the polygon.
+///  @param[in]		pos			The position to check. [(x, y, z)]
+///  @param[out]	closest		The closest point. [(x, y, z)]
+/// @returns The status flags for the query.
+/// @see #DtNavMeshQuery.ClosestPointOnPolyBoundary
+func (this *DtNavMeshQueryFixed) ClosestPointOnPolyBoundary(ref DtPolyRef, pos, closest []DtFixed) DtStatus {
+	DtAssert(this.m_nav != nil)
+	var tile *DtMeshTile
+	var poly *DtPoly
+	if DtStatusFailed(this.m_nav.GetTileAndPolyByRef(ref, &tile, &poly)) {
+		return DT_FAILURE | DT_INVALID_PARAM
+	}
+	var verts [DT_VERTS_PER_POLYGON * 3]DtFixed
+	var edged [DT_VERTS_PER_POLYGON]int64
+	var edget [DT_VERTS_PER_POLYGON]DtFixed
+	nv := this.getPolyVerts(tile, poly, verts[:])
+
+	if dtFixedDistancePtPolyEdgesSqr(pos, verts[:], nv, edged[:], edget[:]) {
+		// Point is inside the polygon, return the point.
+		dtFixedVcopy(closest, pos)
+	} else {
+		// Point is outside the polygon, clamp to nearest edge.
+		imin := 0
+		for i := 1; i < nv; i++ {
+			if edged[i] < edged[imin] {
+				imin = i
+			}
+		}
+		dtFixedVlerp(closest, verts[imin*3:], verts[((imin+1)%nv)*3:], edget[imin])
+	}
+	return DT_SUCCESS
+}
+
+// Returns the tile location of a position, as DtNavMesh.CalcTileLoc.
+func (this *DtNavMeshQueryFixed) calcTileLoc(pos []DtFixed, tx, ty *int32) {
+	floorDiv := func(a, b int64) int32 {
+		q := a / b
+		if a%b != 0 && (a < 0) != (b < 0) {
+			q--
+		}
+		return int32(q)
+	}
+	*tx = floorDiv(int64(pos[0])-int64(DtFixedFromFloat(this.m_nav.m_orig[0])), int64(DtFixedFromFloat(this.m_nav.m_tileWidth)))
+	*ty = floorDiv(int64(pos[2])-int64(DtFixedFromFloat(this.m_nav.m_orig[2])), int64(DtFixedFromFloat(this.m_nav.m_tileHeight)))
+}
+
+// Calls process for the polygons of the tile overlapping the box and passing the filter.
+func (this *DtNavMeshQueryFixed) queryPolygonsInTile(tile *DtMeshTile, qmin, qmax []DtFixed,
+	filter *DtQueryFilter, process func(tile *DtMeshTile, ref DtPolyRef)) {
+	base := this.m_nav.GetPolyRefBase(tile)
+	if tile.BvTree != nil {
+		var tbmin, tbmax [3]DtFixed
+		DtVtoFixed(tbmin[:], tile.Header.Bmin[:])
+		DtVtoFixed(tbmax[:], tile.Header.Bmax[:])
+		qfac := int64(DtFixedFromFloat(tile.Header.BvQuantFactor))
+
+		// Calculate quantized box
+		var bmin, bmax [3]uint16
+		for k := 0; k < 3; k++ {
+			// Clamp query box to world box, and quantize.
+			mn := int64(qmin[k])
+			if mn < int64(tbmin[k]) {
+				mn = int64(tbmin[k])
+			} else if mn > int64(tbmax[k]) {
+				mn = int64(tbmax[k])
+			}
+			mx := int64(qmax[k])
+			if mx < int64(tbmin[k]) {
+				mx = int64(tbmin[k])
+			} else if mx > int64(tbmax[k]) {
+				mx = int64(tbmax[k])
+			}
+			bmin[k] = uint16((qfac*(mn-int64(tbmin[k])))>>(2*DT_FIXED_SHIFT)) & 0xfffe
+			bmax[k] = uint16((qfac*(mx-int64(tbmin[k])))>>(2*DT_FIXED_SHIFT)+1) | 1
+		}
+
+		// Traverse tree
+		nodeIndex := 0
+		endIndex := int(tile.Header.BvNodeCount)
+		for nodeIndex < endIndex {
+			node := &tile.BvTree[nodeIndex]
+			overlap := DtOverlapQuantBounds(bmin[:], bmax[:], node.Bmin[:], node.Bmax[:])
+			isLeafNode := (node.I >= 0)
+
+			if isLeafNode && overlap {
+				ref := base | (DtPolyRef)(node.I)
+				if filter.PassFilter(ref, tile, &tile.Polys[node.I]) {
+					process(tile, ref)
+				}
+			}
+
+			if overlap || isLeafNode {
+				nodeIndex++
+			} else {
+				escapeIndex := int(-node.I)
+				nodeIndex += escapeIndex
+			}
+		}
+		return
+	}
+
+	var bmin, bmax, v [3]DtFixed
+	for i := 0; i < int(tile.Header.PolyCount); i++ {
+		p := &tile.Polys[i]
+		// Do not return off-mesh connection polygons.
+		if p.GetType() == DT_POLYTYPE_OFFMESH_CONNECTION {
+			continue
+		}
+		// Must pass filter
+		ref := base | (DtPolyRef)(i)
+		if !filter.PassFilter(ref, tile, p) {
+			continue
+		}
+		// Calc polygon bounds.
+		DtVtoFixed(bmin[:], tile.Verts[p.Verts[0]*3:])
+		dtFixedVcopy(bmax[:], bmin[:])
+		for j := 1; j < int(p.VertCount); j++ {
+			DtVtoFixed(v[:], tile.Verts[p.Verts[j]*3:])
+			for k := 0; k < 3; k++ {
+				if v[k] < bmin[k] {
+					bmin[k] = v[k]
+				}
+				if v[k] > bmax[k] {
+					bmax[k] = v[k]
+				}
+			}
+		}
+		overlap := true
+		for k := 0; k < 3; k++ {
+			if qmin[k] > bmax[k] || qmax[k] < bmin[k] {
+				overlap = false
+			}
+		}
+		if overlap {
+			process(tile, ref)
+		}
+	}
+}
+
+/// Finds the polygon nearest to the specified center point.
+///  @param[in]		center		The center of the search box. [(x, y, z)]
+///  @param[in]		halfExtents	The search distance along each axis. [(x, y, z)]
+///  @param[in]		filter		The polygon filter to apply to the query.
+///  @param[out]	nearestRef	The reference id of the nearest polygon.
+///  @param[out]	nearestPt	The nearest point on the polygon. [opt] [(x, y, z)]
+/// @returns The status flags for the query.
+/// @see #DtNavMeshQuery.FindNearestPoly
+func (this *DtNavMeshQueryFixed) FindNearestPoly(center, halfExtents []DtFixed,
+	filter *DtQueryFilter,
+	nearestRef *DtPolyRef, nearestPt []DtFixed) DtStatus {
+	DtAssert(this.m_nav != nil)
+
+	if nearestRef == nil || center == nil || halfExtents == nil || filter == nil {
+		return DT_FAILURE | DT_INVALID_PARAM
+	}
+	var bmin, bmax [3]DtFixed
+	for k := 0; k < 3; k++ {
+		bmin[k] = center[k] - halfExtents[k]
+		bmax[k] = center[k] + halfExtents[k]
+	}
+
+	var nearest DtPolyRef
+	var nearestPoint [3]DtFixed
+	nearestDistanceSqr := int64(math.MaxInt64)
+	process := func(tile *DtMeshTile, ref DtPolyRef) {
+		var closest [3]DtFixed
+		posOverPoly := false
+		this.ClosestPointOnPoly(ref, center, closest[:], &posOverPoly)
+
+		// If a point is directly over a polygon and closer than
+		// climb height, favor that instead of straight line nearest point.
+		var d int64
+		if posOverPoly {
+			dy := int64(center[1]) - int64(closest[1])
+			if dy < 0 {
+				dy = -dy
+			}
+			dy -= int64(DtFixedFromFloat(tile.Header.WalkableClimb))
+			if dy > 0 {
+				d = dy * dy
+			}
+		} else {
+			d = dtFixedVdistSqr(center, closest[:])
+		}
+
+		if d < nearestDistanceSqr {
+			dtFixedVcopy(nearestPoint[:], closest[:])
+			nearestDistanceSqr = d
+			nearest = ref
+		}
+	}
+
+	// Find tiles the query touches.
+	var minx, miny, maxx, maxy int32
+	this.calcTileLoc(bmin[:], &minx, &miny)
+	this.calcTileLoc(bmax[:], &maxx, &maxy)
+
+	const MAX_NEIS int = 32
+	var neis [MAX_NEIS]*DtMeshTile
+	for y := miny; y <= maxy; y++ {
+		for x := minx; x <= maxx; x++ {
+			nneis := this.m_nav.GetTilesAt(x, y, neis[:], MAX_NEIS)
+			for j := 0; j < nneis; j++ {
+				this.queryPolygonsInTile(neis[j], bmin[:], bmax[:], filter, process)
+			}
+		}
+	}
+
+	*nearestRef = nearest
+	// Only override nearestPt if we actually found a poly so the nearest point
+	// is valid.
+	if nearestPt != nil && nearest != 0 {
+		dtFixedVcopy(nearestPt, nearestPoint[:])
+	}
+	return DT_SUCCESS
+}
+
+/// Finds a path from the start polygon to the end polygon.
+///  @param[in]		startRef	The refrence id of the start polygon.
+///  @param[in]		endRef		The reference id of the end polygon.
+///  @param[in]		startPos	A position within the start polygon. [(x, y, z)]
+///  @param[in]		endPos		A position within the end polygon. [(x, y, z)]
+///  @param[in]		filter		The polygon filter to apply to the query.
+///  @param[out]	path		An ordered list of polygon references representing the path. (Start to end.)
+///  							[(polyRef) * @p pathCount]
+///  @param[out]	pathCount	The number of polygons returned in the @p path array.
+///  @param[in]		maxPath		The maximum number of polygons the @p path array can hold. [Limit: >= 1]
+/// @returns The status flags for the query.
+/// @see #DtNavMeshQuery.FindPath
+func (this *DtNavMeshQueryFixed) FindPath(startRef, endRef DtPolyRef,
+	startPos, endPos []DtFixed,
+	filter *DtQueryFilter,
+	path []DtPolyRef, pathCount *int, maxPath int) DtStatus {
+	DtAssert(this.m_nav != nil)
+	DtAssert(this.m_nodePool != nil)
+
+	if pathCount != nil {
+		*pathCount = 0
+	}
+	// Validate input
+	if !this.m_nav.IsValidPolyRef(startRef) || !this.m_nav.IsValidPolyRef(endRef) ||
+		startPos == nil || endPos == nil || filter == nil || maxPath <= 0 || path == nil || pathCount == nil {
+		return DT_FAILURE | DT_INVALID_PARAM
+	}
+	if startRef == endRef {
+		path[0] = startRef
+		*pathCount = 1
+		return DT_SUCCESS
+	}
+
+	this.m_nodePool.Clear()
+	this.m_openList = this.m_openList[:0]
+
+	startNode := this.m_nodePool.GetNode(startRef, 0)
+	start := this.fixedNode(startNode)
+	dtFixedVcopy(start.pos[:], startPos)
+	start.cost = 0
+	start.total = this.getHeuristic(startPos, endPos)
+	startNode.Pidx = 0
+	startNode.Id = startRef
+	startNode.Flags = DT_NODE_OPEN
+	this.m_openList.push(this.m_nodePool.GetNodeIdx(startNode), start.total)
+
+	lastBestNode := startNode
+	lastBestNodeCost := start.total
+
+	outOfNodes := false
+
+	for len(this.m_openList) != 0 {
+		// Remove node from open list and put it in closed list.
+		top := this.m_openList.pop()
+		bestNode := this.m_nodePool.GetNodeAtIdx(top.idx)
+		best := this.fixedNode(bestNode)
+		if (bestNode.Flags&DT_NODE_OPEN) == 0 || best.total != top.total {
+			// Stale entry of a node whose cost has improved since.
+			continue
+		}
+		bestNode.Flags &= ^DT_NODE_OPEN
+		bestNode.Flags |= DT_NODE_CLOSED
+
+		// Reached the goal, stop searching.
+		if bestNode.Id == endRef {
+			lastBestNode = bestNode
+			break
+		}
+
+		// Get current poly and tile.
+		// The API input has been cheked already, skip checking internal data.
+		bestRef := bestNode.Id
+		var bestTile *DtMeshTile
+		var bestPoly *DtPoly
+		this.m_nav.GetTileAndPolyByRefUnsafe(bestRef, &bestTile, &bestPoly)
+
+		// Get parent ref.
+		var parentRef DtPolyRef
+		if bestNode.Pidx != 0 {
+			parentRef = this.m_nodePool.GetNodeAtIdx(bestNode.Pidx).Id
+		}
+
+		for i := bestPoly.FirstLink; i != DT_NULL_LINK; i = bestTile.Links[i].Next {
+			neighbourRef := bestTile.Links[i].Ref
+
+			// Skip invalid ids and do not expand back to where we came from.
+			if neighbourRef == 0 || neighbourRef == parentRef {
+				continue
+			}
+			// Get neighbour poly and tile.
+			// The API input has been cheked already, skip checking internal data.
+			var neighbourTile *DtMeshTile
+			var neighbourPoly *DtPoly
+			this.m_nav.GetTileAndPolyByRefUnsafe(neighbourRef, &neighbourTile, &neighbourPoly)
+
+			if !filter.PassFilter(neighbourRef, neighbourTile, neighbourPoly) {
+				continue
+			}
+			// deal explicitly with crossing tile boundaries
+			var crossSide uint8
+			if bestTile.Links[i].Side != 0xff {
+				crossSide = (bestTile.Links[i].Side >> 1)
+			}
+			// get the node
+			neighbourNode := this.m_nodePool.GetNode(neighbourRef, crossSide)
+			if neighbourNode == nil {
+				outOfNodes = true
+				continue
+			}
+			neighbour := this.fixedNode(neighbourNode)
+
+			// If the node is visited the first time, calculate node position.
+			if neighbourNode.Flags == 0 {
+				this.getEdgeMidPoint(bestRef, bestPoly, bestTile,
+					neighbourRef, neighbourPoly, neighbourTile,
+					neighbour.pos[:])
+			}
+
+			// Calculate cost and heuristic.
+			var cost int64
+			var heuristic int64
+
+			// Special case for last node.
+			if neighbourRef == endRef {
+				curCost := this.getCost(filter, best.pos[:], neighbour.pos[:], bestPoly)
+				endCost := this.getCost(filter, neighbour.pos[:], endPos, neighbourPoly)
+				cost = best.cost + curCost + endCost
+				heuristic = 0
+			} else {
+				curCost := this.getCost(filter, best.pos[:], neighbour.pos[:], bestPoly)
+				cost = best.cost + curCost
+				heuristic = this.getHeuristic(neighbour.pos[:], endPos)
+			}
+
+			total := cost + heuristic
+
+			// The node is already in open list and the new result is worse, skip.
+			if (neighbourNode.Flags&DT_NODE_OPEN) != 0 && total >= neighbour.total {
+				continue
+			}
+			// The node is already visited and process, and the new result is worse, skip.
+			if (neighbourNode.Flags&DT_NODE_CLOSED) != 0 && total >= neighbour.total {
+				continue
+			}
+			// Add or update the node.
+			neighbourNode.Pidx = this.m_nodePool.GetNodeIdx(bestNode)
+			neighbourNode.Id = neighbourRef
+			neighbourNode.Flags = (neighbourNode.Flags & ^DT_NODE_CLOSED) | DT_NODE_OPEN
+			neighbour.cost = cost
+			neighbour.total = total
+			this.m_openList.push(this.m_nodePool.GetNodeIdx(neighbourNode), total)
+
+			// Update nearest node to target so far.
+			if heuristic < lastBestNodeCost {
+				lastBestNodeCost = heuristic
+				lastBestNode = neighbourNode
+			}
+		}
+	}
+
+	status := this.getPathToNode(lastBestNode, path, pathCount, maxPath)
+
+	if lastBestNode.Id != endRef {
+		status |= DT_PARTIAL_RESULT
+	}
+	if outOfNodes {
+		status |= DT_OUT_OF_NODES
+	}
+	return status
+}
+
+// Gets the path leading to the specified end node.
+func (this *DtNavMeshQueryFixed) getPathToNode(endNode *DtNode, path []DtPolyRef, pathCount *int, maxPath int) DtStatus {
+	// Find the length of the entire path.
+	length := 0
+	for curNode := endNode; curNode != nil; curNode = this.m_nodePool.GetNodeAtIdx(curNode.Pidx) {
+		length++
+	}
+
+	// If the path cannot be fully stored then advance to the last node we will be able to store.
+	curNode := endNode
+	writeCount := length
+	for ; writeCount > maxPath; writeCount-- {
+		curNode = this.m_nodePool.GetNodeAtIdx(curNode.Pidx)
+	}
+
+	// Write path
+	for i := writeCount - 1; i >= 0; i-- {
+		path[i] = curNode.Id
+		curNode = this.m_nodePool.GetNodeAtIdx(curNode.Pidx)
+	}
+
+	*pathCount = writeCount
+	if length > maxPath {
+		return DT_SUCCESS | DT_BUFFER_TOO_SMALL
+	}
+	return DT_SUCCESS
+}
+
+// Returns portal points between two polygons.
+func (this *DtNavMeshQueryFixed) getPortalPoints(from DtPolyRef, fromPoly *DtPoly, fromTile *DtMeshTile,
+	to DtPolyRef, toPoly *DtPoly, toTile *DtMeshTile,
+	left, right []DtFixed) DtStatus {
+	// Find the link that points to the 'to' polygon.
+	var link *DtLink
+	for i := fromPoly.FirstLink; i != DT_NULL_LINK; i = fromTile.Links[i].Next {
+		if fromTile.Links[i].Ref == to {
+			link = &fromTile.Links[i]
+			break
+		}
+	}
+	if link == nil {
+		return DT_FAILURE | DT_INVALID_PARAM
+	}
+	// Handle off-mesh connections.
+	if fromPoly.GetType() == DT_POLYTYPE_OFFMESH_CONNECTION {
+		DtVtoFixed(left, fromTile.Verts[fromPoly.Verts[link.Edge]*3:])
+		dtFixedVcopy(right, left)
+		return DT_SUCCESS
+	}
+	if toPoly.GetType() == DT_POLYTYPE_OFFMESH_CONNECTION {
+		for i := toPoly.FirstLink; i != DT_NULL_LINK; i = toTile.Links[i].Next {
+			if toTile.Links[i].Ref == from {
+				DtVtoFixed(left, toTile.Verts[toPoly.Verts[toTile.Links[i].Edge]*3:])
+				dtFixedVcopy(right, left)
+				return DT_SUCCESS
+			}
+		}
+		return DT_FAILURE | DT_INVALID_PARAM
+	}
+
+	// Find portal vertices.
+	var v0, v1 [3]DtFixed
+	DtVtoFixed(v0[:], fromTile.Verts[fromPoly.Verts[link.Edge]*3:])
+	DtVtoFixed(v1[:], fromTile.Verts[fromPoly.Verts[int(link.Edge+1)%int(fromPoly.VertCount)]*3:])
+	dtFixedVcopy(left, v0[:])
+	dtFixedVcopy(right, v1[:])
+
+	// If the link is at tile boundary, clamp the vertices to
+	// the link width.
+	if link.Side != 0xff && (link.Bmin != 0 || link.Bmax != 255) {
+		dtFixedVlerp(left, v0[:], v1[:], dtFixedLinkLimit(link.Bmin))
+		dtFixedVlerp(right, v0[:], v1[:], dtFixedLinkLimit(link.Bmax))
+	}
+	return DT_SUCCESS
+}
+
+// Unpacks a portal limit of a link, limit/255 in fixed point.
+func dtFixedLinkLimit(limit uint8) DtFixed {
+	return DtFixed((int64(limit)*int64(DT_FIXED_ONE) + 127) / 255)
+}
+
+// Returns edge mid point between two polygons.
+func (this *DtNavMeshQueryFixed) getEdgeMidPoint(from DtPolyRef, fromPoly *DtPoly, fromTile *DtMeshTile,
+	to DtPolyRef, toPoly *DtPoly, toTile *DtMeshTile,
+	mid []DtFixed) DtStatus {
+	var left, right [3]DtFixed
+	if DtStatusFailed(this.getPortalPoints(from, fromPoly, fromTile, to, toPoly, toTile, left[:], right[:])) {
+		return DT_FAILURE | DT_INVALID_PARAM
+	}
+	for k := 0; k < 3; k++ {
+		mid[k] = DtFixed((int64(left[k]) + int64(right[k])) >> 1)
+	}
+	return DT_SUCCESS
+}
+
+// Appends vertex to a straight path
+func (this *DtNavMeshQueryFixed) appendVertex(pos []DtFixed, flags DtStraightPathFlags, ref DtPolyRef,
+	straightPath []DtFixed, straightPathFlags []DtStraightPathFlags, straightPathRefs []DtPolyRef,
+	straightPathCount *int, maxStraightPath int) DtStatus {
+	if (*straightPathCount) > 0 && dtFixedVequal(straightPath[((*straightPathCount)-1)*3:], pos) {
+		// The vertices are equal, update flags and poly.
+		if straightPathFlags != nil {
+			straightPathFlags[(*straightPathCount)-1] = flags
+		}
+		if straightPathRefs != nil {
+			straightPathRefs[(*straightPathCount)-1] = ref
+		}
+		return DT_IN_PROGRESS
+	}
+
+	// Append new vertex.
+	dtFixedVcopy(straightPath[(*straightPathCount)*3:], pos)
+	if straightPathFlags != nil {
+		straightPathFlags[(*straightPathCount)] = flags
+	}
+	if straightPathRefs != nil {
+		straightPathRefs[(*straightPathCount)] = ref
+	}
+	(*straightPathCount)++
+
+	// If there is no space to append more vertices, return.
+	if (*straightPathCount) >= maxStraightPath {
+		return DT_SUCCESS | DT_BUFFER_TOO_SMALL
+	}
+	// If reached end of path, return.
+	if flags == DT_STRAIGHTPATH_END {
+		return DT_SUCCESS
+	}
+	return DT_IN_PROGRESS
+}
+
+// Appends intermediate portal points to a straight path.
+func (this *DtNavMeshQueryFixed) appendPortals(startIdx, endIdx int, endPos []DtFixed, path []DtPolyRef,
+	straightPath []DtFixed, straightPathFlags []DtStraightPathFlags, straightPathRefs []DtPolyRef,
+	straightPathCount *int, maxStraightPath int, options DtStraightPathOptions) DtStatus {
+	startPos := straightPath[(*straightPathCount-1)*3:]
+	// Append or update last vertex
+	for i := startIdx; i < endIdx; i++ {
+		// Calculate portal
+		from := path[i]
+		var fromTile *DtMeshTile
+		var fromPoly *DtPoly
+		if DtStatusFailed(this.m_nav.GetTileAndPolyByRef(from, &fromTile, &fromPoly)) {
+			return DT_FAILURE | DT_INVALID_PARAM
+		}
+		to := path[i+1]
+		var toTile *DtMeshTile
+		var toPoly *DtPoly
+		if DtStatusFailed(this.m_nav.GetTileAndPolyByRef(to, &toTile, &toPoly)) {
+			return DT_FAILURE | DT_INVALID_PARAM
+		}
+		var left, right [3]DtFixed
+		if DtStatusFailed(this.getPortalPoints(from, fromPoly, fromTile, to, toPoly, toTile, left[:], right[:])) {
+			break
+		}
+		if (options & DT_STRAIGHTPATH_AREA_CROSSINGS) != 0 {
+			// Skip intersection if only area crossings are requested.
+			if fromPoly.GetArea() == toPoly.GetArea() {
+				continue
+			}
+		}
+
+		// Append intersection
+		var pt [3]DtFixed
+		if dtFixedIntersectSegSeg2D(startPos, endPos, left[:], right[:], pt[:]) {
+			stat := this.appendVertex(pt[:], 0, path[i+1],
+				straightPath, straightPathFlags, straightPathRefs,
+				straightPathCount, maxStraightPath)
+			if stat != DT_IN_PROGRESS {
+				return stat
+			}
+		}
+	}
+	return DT_IN_PROGRESS
+}
+
+/// Finds the straight path from the start to the end position within the polygon corridor.
+///  @param[in]		startPos			Path start position. [(x, y, z)]
+///  @param[in]		endPos				Path end position. [(x, y, z)]
+///  @param[in]		path				An array of polygon references that represent the path corridor.
+///  @param[in]		pathSize			The number of polygons in the @p path array.
+///  @param[out]	straightPath		Points describing the straight path. [(x, y, z) * @p straightPathCount].
+///  @param[out]	straightPathFlags	Flags describing each point. (See: #dtStraightPathFlags) [opt]
+///  @param[out]	straightPathRefs	The reference id of the polygon that is being entered at each point. [opt]
+///  @param[out]	straightPathCount	The number of points in the straight path.
+///  @param[in]		maxStraightPath		The maximum number of points the straight path arrays can hold.  [Limit: > 0]
+///  @param[in]		options				Query options. (see: #dtStraightPathOptions)
+/// @returns The status flags for the query.
+/// @see #DtNavMeshQuery.FindStraightPath
+func (this *DtNavMeshQueryFixed) FindStraightPath(startPos, endPos []DtFixed,
+	path []DtPolyRef, pathSize int,
+	straightPath []DtFixed, straightPathFlags []DtStraightPathFlags, straightPathRefs []DtPolyRef,
+	straightPathCount *int, maxStraightPath int, options DtStraightPathOptions) DtStatus {
+	DtAssert(this.m_nav != nil)
+
+	*straightPathCount = 0
+
+	if maxStraightPath == 0 || pathSize <= 0 || path[0] == 0 {
+		return DT_FAILURE | DT_INVALID_PARAM
+	}
+	var stat DtStatus
+
+	var closestStartPos [3]DtFixed
+	if DtStatusFailed(this.ClosestPointOnPolyBoundary(path[0], startPos, closestStartPos[:])) {
+		return DT_FAILURE | DT_INVALID_PARAM
+	}
+	var closestEndPos [3]DtFixed
+	if DtStatusFailed(this.ClosestPointOnPolyBoundary(path[pathSize-1], endPos, closestEndPos[:])) {
+		return DT_FAILURE | DT_INVALID_PARAM
+	}
+	// Add start point.
+	stat = this.appendVertex(closestStartPos[:], DT_STRAIGHTPATH_START, path[0],
+		straightPath, straightPathFlags, straightPathRefs,
+		straightPathCount, maxStraightPath)
+	if stat != DT_IN_PROGRESS {
+		return stat
+	}
+	crossings := (options & (DT_STRAIGHTPATH_AREA_CROSSINGS | DT_STRAIGHTPATH_ALL_CROSSINGS)) != 0
+	if pathSize > 1 {
+		var portalApex, portalLeft, portalRight [3]DtFixed
+		dtFixedVcopy(portalApex[:], closestStartPos[:])
+		dtFixedVcopy(portalLeft[:], portalApex[:])
+		dtFixedVcopy(portalRight[:], portalApex[:])
+		var apexIndex int
+		var leftIndex int
+		var rightIndex int
+
+		var leftPolyType DtPolyTypes
+		var rightPolyType DtPolyTypes
+
+		leftPolyRef := path[0]
+		rightPolyRef := path[0]
+
+		for i := 0; i < pathSize; i++ {
+			var left, right [3]DtFixed
+			var toType DtPolyTypes
+
+			if i+1 < pathSize {
+				// Next portal.
+				var fromTile, toTile *DtMeshTile
+				var fromPoly, toPoly *DtPoly
+				if DtStatusFailed(this.m_nav.GetTileAndPolyByRef(path[i], &fromTile, &fromPoly)) ||
+					DtStatusFailed(this.m_nav.GetTileAndPolyByRef(path[i+1], &toTile, &toPoly)) ||
+					DtStatusFailed(this.getPortalPoints(path[i], fromPoly, fromTile, path[i+1], toPoly, toTile, left[:], right[:])) {
+					// Failed to get portal points, in practice this means that path[i+1] is invalid polygon.
+					// Clamp the end point to path[i], and return the path so far.
+
+					if DtStatusFailed(this.ClosestPointOnPolyBoundary(path[i], endPos, closestEndPos[:])) {
+						// This should only happen when the first polygon is invalid.
+						return DT_FAILURE | DT_INVALID_PARAM
+					}
+
+					// Apeend portals along the current straight path segment.
+					if crossings {
+						// Ignore status return value as we're just about to return anyway.
+						this.appendPortals(apexIndex, i, closestEndPos[:], path,
+							straightPath, straightPathFlags, straightPathRefs,
+							straightPathCount, maxStraightPath, options)
+					}
+
+					// Ignore status return value as we're just about to return anyway.
+					this.appendVertex(closestEndPos[:], 0, path[i],
+						straightPath, straightPathFlags, straightPathRefs,
+						straightPathCount, maxStraightPath)
+
+					if *straightPathCount >= maxStraightPath {
+						return DT_SUCCESS | DT_PARTIAL_RESULT | DT_BUFFER_TOO_SMALL
+					}
+					return DT_SUCCESS | DT_PARTIAL_RESULT
+				}
+				toType = toPoly.GetType()
+
+				// If starting really close the portal, advance. (0.001^2 in Q32.32)
+				if i == 0 {
+					var t DtFixed
+					if dtFixedDistancePtSegSqr2D(portalApex[:], left[:], right[:], &t) < 4295 {
+						continue
+					}
+				}
+			} else {
+				// End of the path.
+				dtFixedVcopy(left[:], closestEndPos[:])
+				dtFixedVcopy(right[:], closestEndPos[:])
+
+				toType = DT_POLYTYPE_GROUND
+			}
+
+			// Right vertex.
+			if dtFixedTriArea2D(portalApex[:], portalRight[:], right[:]) <= 0 {
+				if dtFixedVequal(portalApex[:], portalRight[:]) || dtFixedTriArea2D(portalApex[:], portalLeft[:], right[:]) > 0 {
+					dtFixedVcopy(portalRight[:], right[:])
+					if i+1 < pathSize {
+						rightPolyRef = path[i+1]
+					} else {
+						rightPolyRef = 0
+					}
+					rightPolyType = toType
+					rightIndex = i
+				} else {
+					// Append portals along the current straight path segment.
+					if crossings {
+						stat = this.appendPortals(apexIndex, leftIndex, portalLeft[:], path,
+							straightPath, straightPathFlags, straightPathRefs,
+							straightPathCount, maxStraightPath, options)
+						if stat != DT_IN_PROGRESS {
+							return stat
+						}
+					}
+
+					dtFixedVcopy(portalApex[:], portalLeft[:])
+					apexIndex = leftIndex
+
+					var flags DtStraightPathFlags
+					if leftPolyRef == 0 {
+						flags = DT_STRAIGHTPATH_END
+					} else if leftPolyType == DT_POLYTYPE_OFFMESH_CONNECTION {
+						flags = DT_STRAIGHTPATH_OFFMESH_CONNECTION
+					}
+
+					// Append or update vertex
+					stat = this.appendVertex(portalApex[:], flags, leftPolyRef,
+						straightPath, straightPathFlags, straightPathRefs,
+						straightPathCount, maxStraightPath)
+					if stat != DT_IN_PROGRESS {
+						return stat
+					}
+					dtFixedVcopy(portalLeft[:], portalApex[:])
+					dtFixedVcopy(portalRight[:], portalApex[:])
+					leftIndex = apexIndex
+					rightIndex = apexIndex
+
+					// Restart
+					i = apexIndex
+
+					continue
+				}
+			}
+
+			// Left vertex.
+			if dtFixedTriArea2D(portalApex[:], portalLeft[:], left[:]) >= 0 {
+				if dtFixedVequal(portalApex[:], portalLeft[:]) || dtFixedTriArea2D(portalApex[:], portalRight[:], left[:]) < 0 {
+					dtFixedVcopy(portalLeft[:], left[:])
+					if i+1 < pathSize {
+						leftPolyRef = path[i+1]
+					} else {
+						leftPolyRef = 0
+					}
+					leftPolyType = toType
+					leftIndex = i
+				} else {
+					// Append portals along the current straight path segment.
+					if crossings {
+						stat = this.appendPortals(apexIndex, rightIndex, portalRight[:], path,
+							straightPath, straightPathFlags, straightPathRefs,
+							straightPathCount, maxStraightPath, options)
+						if stat != DT_IN_PROGRESS {
+							return stat
+						}
+					}
+
+					dtFixedVcopy(portalApex[:], portalRight[:])
+					apexIndex = rightIndex
+
+					var flags DtStraightPathFlags
+					if rightPolyRef == 0 {
+						flags = DT_STRAIGHTPATH_END
+					} else if rightPolyType == DT_POLYTYPE_OFFMESH_CONNECTION {
+						flags = DT_STRAIGHTPATH_OFFMESH_CONNECTION
+					}
+
+					// Append or update vertex
+					stat = this.appendVertex(portalApex[:], flags, rightPolyRef,
+						straightPath, straightPathFlags, straightPathRefs,
+						straightPathCount, maxStraightPath)
+					if stat != DT_IN_PROGRESS {
+						return stat
+					}
+					dtFixedVcopy(portalLeft[:], portalApex[:])
+					dtFixedVcopy(portalRight[:], portalApex[:])
+					leftIndex = apexIndex
+					rightIndex = apexIndex
+
+					// Restart
+					i = apexIndex
+
+					continue
+				}
+			}
+		}
+
+		// Append portals along the current straight path segment.
+		if crossings {
+			stat = this.appendPortals(apexIndex, pathSize-1, closestEndPos[:], path,
+				straightPath, straightPathFlags, straightPathRefs,
+				straightPathCount, maxStraightPath, options)
+			if stat != DT_IN_PROGRESS {
+				return stat
+			}
+		}
+	}
+
+	// Ignore status return value as we're just about to return anyway.
+	this.appendVertex(closestEndPos[:], DT_STRAIGHTPATH_END, 0,
+		straightPath, straightPathFlags, straightPathRefs,
+		straightPathCount, maxStraightPath)
+
+	if *straightPathCount >= maxStraightPath {
+		return DT_SUCCESS | DT_BUFFER_TOO_SMALL
+	}
+	return DT_SUCCESS
+}
+
+/// Moves from the start to the end position constrained to the navigation mesh.
+///  @param[in]		startRef		The reference id of the start polygon.
+///  @param[in]		startPos		A position of the mover within the start polygon. [(x, y, x)]
+///  @param[in]		endPos			The desired end position of the mover. [(x, y, z)]
+///  @param[in]		filter			The polygon filter to apply to the query.
+///  @param[out]	resultPos		The result position of the mover. [(x, y, z)]
+///  @param[out]	visited			The reference ids of the polygons visited during the move.
+///  @param[out]	visitedCount	The number of polygons visited during the move.
+///  @param[in]		maxVisitedSize	The maximum number of polygons the @p visited array can hold.
+///  @param[out]	bHit			True if the move was stopped by a wall.
+/// @returns The status flags for the query.
+/// @see #DtNavMeshQuery.MoveAlongSurface
+func (this *DtNavMeshQueryFixed) MoveAlongSurface(startRef DtPolyRef, startPos, endPos []DtFixed,
+	filter *DtQueryFilter,
+	resultPos []DtFixed, visited []DtPolyRef, visitedCount *int, maxVisitedSize int,
+	bHit *bool) DtStatus {
+	DtAssert(this.m_nav != nil)
+	DtAssert(this.m_tinyNodePool != nil)
+
+	*visitedCount = 0
+
+	// Validate input
+	if startRef == 0 || !this.m_nav.IsValidPolyRef(startRef) {
+		return DT_FAILURE | DT_INVALID_PARAM
+	}
+	status := DT_SUCCESS
+
+	const MAX_STACK int = 48
+	var stack [MAX_STACK]*DtNode
+	var nstack int
+
+	this.m_tinyNodePool.Clear()
+
+	startNode := this.m_tinyNodePool.GetNode(startRef, 0)
+	startNode.Pidx = 0
+	startNode.Id = startRef
+	startNode.Flags = DT_NODE_CLOSED
+	stack[nstack] = startNode
+	nstack++
+
+	var bestPos [3]DtFixed
+	bestDist := int64(math.MaxInt64)
+	var bestNode *DtNode
+	dtFixedVcopy(bestPos[:], startPos)
+
+	// Search constraints, the radius is padded by 0.001 as the float query.
+	var searchPos [3]DtFixed
+	for k := 0; k < 3; k++ {
+		searchPos[k] = DtFixed((int64(startPos[k]) + int64(endPos[k])) >> 1)
+	}
+	searchRad := dtFixedVdist(startPos, endPos)/2 + 66
+	searchRadSqr := searchRad * searchRad
+
+	var verts [DT_VERTS_PER_POLYGON * 3]DtFixed
+
+	var wallNode *DtNode
+	for nstack != 0 {
+		// Pop front.
+		curNode := stack[0]
+		for i := 0; i < nstack-1; i++ {
+			stack[i] = stack[i+1]
+		}
+		nstack--
+
+		// Get poly and tile.
+		// The API input has been cheked already, skip checking internal data.
+		curRef := curNode.Id
+		var curTile *DtMeshTile
+		var curPoly *DtPoly
+		this.m_nav.GetTileAndPolyByRefUnsafe(curRef, &curTile, &curPoly)
+
+		// Collect vertices.
+		nverts := this.getPolyVerts(curTile, curPoly, verts[:])
+
+		// If target is inside the poly, stop search.
+		if dtFixedPointInPolygon(endPos, verts[:], nverts) {
+			bestNode = curNode
+			dtFixedVcopy(bestPos[:], endPos)
+			break
+		}
+
+		// Find wall edges and find nearest point inside the walls.
+		for i, j := 0, nverts-1; i < nverts; j, i = i, i+1 {
+			// Find links to neighbours.
+			const MAX_NEIS int = 8
+			nneis := 0
+			var neis [MAX_NEIS]DtPolyRef
+
+			if (curPoly.Neis[j] & DT_EXT_LINK) != 0 {
+				// Tile border.
+				for k := curPoly.FirstLink; k != DT_NULL_LINK; k = curTile.Links[k].Next {
+					link := &curTile.Links[k]
+					if link.Edge == uint8(j) && link.Ref != 0 {
+						var neiTile *DtMeshTile
+						var neiPoly *DtPoly
+						this.m_nav.GetTileAndPolyByRefUnsafe(link.Ref, &neiTile, &neiPoly)
+						if filter.PassFilter(link.Ref, neiTile, neiPoly) && nneis < MAX_NEIS {
+							neis[nneis] = link.Ref
+							nneis++
+						}
+					}
+				}
+			} else if curPoly.Neis[j] != 0 {
+				idx := (uint32)(curPoly.Neis[j] - 1)
+				ref := this.m_nav.GetPolyRefBase(curTile) | DtPolyRef(idx)
+				if filter.PassFilter(ref, curTile, &curTile.Polys[idx]) {
+					// Internal edge, encode id.
+					neis[nneis] = ref
+					nneis++
+				}
+			}
+
+			vj := verts[j*3:]
+			vi := verts[i*3:]
+			if nneis == 0 {
+				// Wall edge, calc distance.
+				var tseg DtFixed
+				distSqr := dtFixedDistancePtSegSqr2D(endPos, vj, vi, &tseg)
+				if distSqr < bestDist {
+					// Update nearest distance.
+					dtFixedVlerp(bestPos[:], vj, vi, tseg)
+					bestDist = distSqr
+					bestNode = curNode
+					wallNode = curNode
+				}
+				continue
+			}
+			for k := 0; k < nneis; k++ {
+				// Skip if no node can be allocated.
+				neighbourNode := this.m_tinyNodePool.GetNode(neis[k], 0)
+				if neighbourNode == nil {
+					continue
+				}
+				// Skip if already visited.
+				if (neighbourNode.Flags & DT_NODE_CLOSED) != 0 {
+					continue
+				}
+				// Skip the link if it is too far from search constraint.
+				var tseg DtFixed
+				if dtFixedDistancePtSegSqr2D(searchPos[:], vj, vi, &tseg) > searchRadSqr {
+					continue
+				}
+				// Mark as the node as visited and push to queue.
+				if nstack < MAX_STACK {
+					neighbourNode.Pidx = this.m_tinyNodePool.GetNodeIdx(curNode)
+					neighbourNode.Flags |= DT_NODE_CLOSED
+					stack[nstack] = neighbourNode
+					nstack++
+				}
+			}
+		}
+	}
+
+	var n int
+	if bestNode != nil {
+		// Reverse the path.
+		var prev *DtNode
+		node := bestNode
+		for node != nil {
+			next := this.m_tinyNodePool.GetNodeAtIdx(node.Pidx)
+			node.Pidx = this.m_tinyNodePool.GetNodeIdx(prev)
+			prev = node
+			node = next
+		}
+
+		// Store result
+		for node = prev; node != nil; node = this.m_tinyNodePool.GetNodeAtIdx(node.Pidx) {
+			visited[n] = node.Id
+			n++
+			if n >= maxVisitedSize {
+				status |= DT_BUFFER_TOO_SMALL
+				break
+			}
+		}
+	}
+
+	*bHit = (wallNode != nil && wallNode == bestNode)
+
+	dtFixedVcopy(resultPos, bestPos[:])
+
+	*visitedCount = n
+
+	return status
+}
+
+/// Casts a 'walkability' ray along the surface of the navigation mesh from
+/// the start position toward the end position.
+///  @param[in]		startRef	The reference id of the start polygon.
+///  @param[in]		startPos	A position within the start polygon representing
+///  							the start of the ray. [(x, y, z)]
+///  @param[in]		endPos		The position to cast the ray toward. [(x, y, z)]
+///  @param[in]		filter		The polygon filter to apply to the query.
+///  @param[out]	t			The hit parameter. (#DT_FIXED_MAX if no wall hit.)
+///  @param[out]	hitNormal	The normal of the nearest wall hit. [opt] [(x, y, z)]
+///  @param[out]	path		The reference ids of the visited polygons. [opt]
+///  @param[out]	pathCount	The number of visited polygons. [opt]
+///  @param[in]		maxPath		The maximum number of polygons the @p path array can hold.
+/// @returns The status flags for the query.
+/// @see #DtNavMeshQuery.Raycast
+func (this *DtNavMeshQueryFixed) Raycast(startRef DtPolyRef, startPos, endPos []DtFixed,
+	filter *DtQueryFilter,
+	t *DtFixed, hitNormal []DtFixed, path []DtPolyRef, pathCount *int, maxPath int) DtStatus {
+	DtAssert(this.m_nav != nil)
+
+	*t = 0
+	if pathCount != nil {
+		*pathCount = 0
+	}
+	if hitNormal != nil {
+		dtFixedVset(hitNormal, 0, 0, 0)
+	}
+
+	// Validate input
+	if startRef == 0 || !this.m_nav.IsValidPolyRef(startRef) {
+		return DT_FAILURE | DT_INVALID_PARAM
+	}
+	var verts [DT_VERTS_PER_POLYGON*3 + 3]DtFixed
+	n := 0
+	status := DT_SUCCESS
+	defer func() {
+		if pathCount != nil {
+			*pathCount = n
+		}
+	}()
+
+	var tile, nextTile *DtMeshTile
+	var poly, nextPoly *DtPoly
+	curRef := startRef
+	this.m_nav.GetTileAndPolyByRefUnsafe(curRef, &tile, &poly)
+	for curRef != 0 {
+		// Cast ray against current polygon.
+		nv := this.getPolyVerts(tile, poly, verts[:])
+
+		var tmin, tmax DtFixed
+		var segMin, segMax int
+		if !dtFixedIntersectSegmentPoly2D(startPos, endPos, verts[:], nv, &tmin, &tmax, &segMin, &segMax) {
+			// Could not hit the polygon, keep the old t and report hit.
+			return status
+		}
+
+		// Keep track of furthest t so far.
+		if tmax > *t {
+			*t = tmax
+		}
+		// Store visited polygons.
+		if n < maxPath {
+			path[n] = curRef
+			n++
+		} else {
+			status |= DT_BUFFER_TOO_SMALL
+		}
+		// Ray end is completely inside the polygon.
+		if segMax == -1 {
+			*t = DT_FIXED_MAX
+			return status
+		}
+
+		// Follow neighbours.
+		var nextRef DtPolyRef
+
+		for i := poly.FirstLink; i != DT_NULL_LINK; i = tile.Links[i].Next {
+			link := &tile.Links[i]
+
+			// Find link which contains this edge.
+			if (int)(link.Edge) != segMax {
+				continue
+			}
+			// Get pointer to the next polygon.
+			this.m_nav.GetTileAndPolyByRefUnsafe(link.Ref, &nextTile, &nextPoly)
+
+			// Skip off-mesh connections.
+			if nextPoly.GetType() == DT_POLYTYPE_OFFMESH_CONNECTION {
+				continue
+			}
+			// Skip links based on filter.
+			if !filter.PassFilter(link.Ref, nextTile, nextPoly) {
+				continue
+			}
+			// If the link is internal, just return the ref.
+			if link.Side == 0xff {
+				nextRef = link.Ref
+				break
+			}
+
+			// If the link is at tile boundary,
+
+			// Check if the link spans the whole edge, and accept.
+			if link.Bmin == 0 && link.Bmax == 255 {
+				nextRef = link.Ref
+				break
+			}
+
+			// Check for partial edge links.
+			left := verts[int(link.Edge)*3:]
+			right := verts[((int(link.Edge)+1)%nv)*3:]
+
+			// Check that the intersection lies inside the link portal.
+			var k int
+			if link.Side == 0 || link.Side == 4 {
+				k = 2
+			} else if link.Side == 2 || link.Side == 6 {
+				k = 0
+			} else {
+				continue
+			}
+			// Calculate link size.
+			lmin := left[k] + DtFixedMul(right[k]-left[k], dtFixedLinkLimit(link.Bmin))
+			lmax := left[k] + DtFixedMul(right[k]-left[k], dtFixedLinkLimit(link.Bmax))
+			if lmin > lmax {
+				lmin, lmax = lmax, lmin
+			}
+			// Find the intersection along the side.
+			x := startPos[k] + DtFixedMul(endPos[k]-startPos[k], tmax)
+			if x >= lmin && x <= lmax {
+				nextRef = link.Ref
+				break
+			}
+		}
+
+		if nextRef == 0 {
+			// No neighbour, we hit a wall.
+
+			// Calculate hit normal.
+			if hitNormal != nil {
+				va := verts[segMax*3:]
+				vb := verts[((segMax+1)%nv)*3:]
+				dx := int64(vb[0]) - int64(va[0])
+				dz := int64(vb[2]) - int64(va[2])
+				if d := int64(dtIsqrt(uint64(dx*dx + dz*dz))); d > 0 {
+					hitNormal[0] = DtFixed(dtFixedMulDiv(dz, int64(DT_FIXED_ONE), d))
+					hitNormal[2] = DtFixed(dtFixedMulDiv(-dx, int64(DT_FIXED_ONE), d))
+				}
+			}
+			return status
+		}
+
+		// No hit, advance to neighbour polygon.
+		curRef = nextRef
+		tile = nextTile
+		poly = nextPoly
+	}
+	return status
+}
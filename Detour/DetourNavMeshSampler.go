@@ -0,0 +1,246 @@
+package detour
+
+import "sort"
+
+type dtTileSamples struct {
+	polys []int32   ///< Indices of the polygons which can be sampled, in ascending order.
+	cum   []float32 ///< Cumulative area of the polygons. [Size: len(polys)]
+}
+
+func (this *dtTileSamples) area() float32 {
+	if len(this.cum) == 0 {
+		return 0
+	}
+	return this.cum[len(this.cum)-1]
+}
+
+/// Samples random locations uniformly over the area of a navigation mesh.
+///
+/// Unlike #DtNavMeshQuery.FindRandomPoint, which assumes that all tiles cover
+/// the same area, the sampler keeps a cumulative area index over all the
+/// polygons passing the filter, so every location is equally likely to be
+/// picked. The area is measured on the xz-plane.
+///
+/// The index of a tile is rebuilt when the tile is added or removed or when
+/// the flags of one of its polygons change. Only the include and
+/// exclude flags of the filter are used; call #Rebuild after changing them.
+/// @ingroup detour
+type DtNavMeshSampler struct {
+	m_nav     *DtNavMesh      ///< The navigation mesh being sampled.
+	m_filter  DtQueryFilter   ///< The filter polygons must pass to be sampled.
+	m_tiles   []dtTileSamples ///< Polygon area index per tile index.
+	m_tileIdx []int32         ///< Indices of the tiles with a non-zero area.
+	m_tileCum []float32       ///< Cumulative area of the tiles. [Size: len(m_tileIdx)]
+	m_dirty   bool            ///< True if the tile index must be rebuilt.
+}
+
+/// Allocates a sampler for the navigation mesh, indexes all its tiles and
+/// starts listening to its changes.
+///  @param[in]	nav		The navigation mesh to sample.
+///  @param[in]	filter	The filter polygons must pass to be sampled.
+/// @return The sampler.
+func DtAllocNavMeshSampler(nav *DtNavMesh, filter *DtQueryFilter) *DtNavMeshSampler {
+	sampler := &DtNavMeshSampler{
+		m_nav:    nav,
+		m_filter: *filter,
+	}
+	sampler.Rebuild()
+	nav.AddListener(sampler)
+	return sampler
+}
+
+/// Stops listening to the navigation mesh and frees the index.
+///  @param[in]	sampler		A sampler allocated using #DtAllocNavMeshSampler
+func DtFreeNavMeshSampler(sampler *DtNavMeshSampler) {
+	if sampler == nil {
+		return
+	}
+	sampler.m_nav.RemoveListener(sampler)
+	sampler.m_tiles = nil
+	sampler.m_tileIdx = nil
+	sampler.m_tileCum = nil
+}
+
+/// Gets the filter used to select the polygons.
+func (this *DtNavMeshSampler) GetFilter() *DtQueryFilter { return &this.m_filter }
+
+/// Rebuilds the index of all the tiles.
+func (this *DtNavMeshSampler) Rebuild() {
+	this.m_tiles = make([]dtTileSamples, this.m_nav.GetMaxTiles())
+	for i := range this.m_tiles {
+		if tile := this.m_nav.GetTile(i); tile.Header != nil {
+			this.buildTile(tile)
+		}
+	}
+	this.m_dirty = true
+}
+
+/// Returns the total area of the polygons which can be sampled.
+func (this *DtNavMeshSampler) GetTotalArea() float32 {
+	this.update()
+	if len(this.m_tileCum) == 0 {
+		return 0
+	}
+	return this.m_tileCum[len(this.m_tileCum)-1]
+}
+
+func (this *DtNavMeshSampler) tileIndex(tile *DtMeshTile) uint32 {
+	return this.m_nav.DecodePolyIdTile(DtPolyRef(this.m_nav.GetTileRef(tile)))
+}
+
+func (this *DtNavMeshSampler) buildTile(tile *DtMeshTile) {
+	s := &this.m_tiles[this.tileIndex(tile)]
+	s.polys = s.polys[:0]
+	s.cum = s.cum[:0]
+
+	base := this.m_nav.GetPolyRefBase(tile)
+	var areaSum float32
+	for i := 0; i < int(tile.Header.PolyCount); i++ {
+		p := &tile.Polys[i]
+		// Do not return off-mesh connection polygons.
+		if p.GetType() != DT_POLYTYPE_GROUND {
+			continue
+		}
+		if !this.m_filter.PassFilter(base|DtPolyRef(i), tile, p) {
+			continue
+		}
+		var polyArea float32
+		for j := 2; j < int(p.VertCount); j++ {
+			va := tile.Verts[p.Verts[0]*3:]
+			vb := tile.Verts[p.Verts[j-1]*3:]
+			vc := tile.Verts[p.Verts[j]*3:]
+			polyArea += DtTriArea2D(va, vb, vc)
+		}
+		if polyArea <= 0 {
+			continue
+		}
+		areaSum += polyArea
+		s.polys = append(s.polys, int32(i))
+		s.cum = append(s.cum, areaSum)
+	}
+}
+
+/// Rebuilds the cumulative area over the tiles if needed.
+func (this *DtNavMeshSampler) update() {
+	if !this.m_dirty {
+		return
+	}
+	this.m_tileIdx = this.m_tileIdx[:0]
+	this.m_tileCum = this.m_tileCum[:0]
+	var areaSum float32
+	for i := range this.m_tiles {
+		area := this.m_tiles[i].area()
+		if area <= 0 {
+			continue
+		}
+		areaSum += area
+		this.m_tileIdx = append(this.m_tileIdx, int32(i))
+		this.m_tileCum = append(this.m_tileCum, areaSum)
+	}
+	this.m_dirty = false
+}
+
+/// Returns a random location on the navigation mesh.
+///  @param[in]		rng				The random number generator.
+///  @param[out]	randomRef		The reference id of the random location.
+///  @param[out]	randomPt		The random location. [(x, y, z)]
+/// @returns The status flags for the query.
+/// @par
+///
+/// The polygon is found with a binary search over the area index, so the
+/// sampling runs in logarithmic time related to the number of polygons.
+func (this *DtNavMeshSampler) SampleUniform(rng DtRNG, randomRef *DtPolyRef, randomPt []float32) DtStatus {
+	this.update()
+	if len(this.m_tileCum) == 0 {
+		return DT_FAILURE
+	}
+
+	// Pick the tile and then the polygon weighted by area.
+	u := DtMathMulf(rng.Float32(), this.m_tileCum[len(this.m_tileCum)-1])
+	ti := sort.Search(len(this.m_tileCum)-1, func(i int) bool { return this.m_tileCum[i] > u })
+	if ti > 0 {
+		u -= this.m_tileCum[ti-1]
+	}
+	tile := this.m_nav.GetTile(int(this.m_tileIdx[ti]))
+	s := &this.m_tiles[this.m_tileIdx[ti]]
+	pi := sort.Search(len(s.cum)-1, func(i int) bool { return s.cum[i] > u })
+	ip := s.polys[pi]
+	poly := &tile.Polys[ip]
+	polyRef := this.m_nav.GetPolyRefBase(tile) | DtPolyRef(ip)
+
+	// Randomly pick point on polygon.
+	var verts [3 * DT_VERTS_PER_POLYGON]float32
+	var areas [DT_VERTS_PER_POLYGON]float32
+	for j := 0; j < int(poly.VertCount); j++ {
+		DtVcopy(verts[j*3:], tile.Verts[poly.Verts[j]*3:])
+	}
+
+	sr := rng.Float32()
+	tr := rng.Float32()
+
+	var pt [3]float32
+	DtRandomPointInConvexPoly(verts[:], int(poly.VertCount), areas[:], sr, tr, pt[:])
+
+	// Find the height on the detail mesh.
+	var closest [3]float32
+	this.m_nav.closestPointOnPoly(polyRef, pt[:], closest[:], nil)
+
+	DtVcopy(randomPt, closest[:])
+	*randomRef = polyRef
+
+	return DT_SUCCESS
+}
+
+/// Returns a batch of random locations on the navigation mesh.
+///  @param[in]		rng				The random number generator.
+///  @param[out]	randomRefs		The reference ids of the random locations. [(polyRef) * @p pointCount]
+///  @param[out]	randomPts		The random locations. [(x, y, z) * @p pointCount]
+///  @param[out]	pointCount		The number of locations returned.
+///  @param[in]		maxPoints		The number of locations to sample.
+/// @returns The status flags for the query.
+func (this *DtNavMeshSampler) SampleUniformN(rng DtRNG, randomRefs []DtPolyRef, randomPts []float32,
+	pointCount *int, maxPoints int) DtStatus {
+	*pointCount = 0
+	if maxPoints < 0 || len(randomRefs) < maxPoints || len(randomPts) < maxPoints*3 {
+		return DT_FAILURE | DT_INVALID_PARAM
+	}
+	for i := 0; i < maxPoints; i++ {
+		status := this.SampleUniform(rng, &randomRefs[i], randomPts[i*3:])
+		if DtStatusFailed(status) {
+			return status
+		}
+		*pointCount = i + 1
+	}
+	return DT_SUCCESS
+}
+
+func (this *DtNavMeshSampler) OnTileAdded(mesh *DtNavMesh, tile *DtMeshTile) {
+	this.buildTile(tile)
+	this.m_dirty = true
+}
+
+func (this *DtNavMeshSampler) OnTileRemoved(mesh *DtNavMesh, tile *DtMeshTile) {
+	s := &this.m_tiles[this.tileIndex(tile)]
+	s.polys = s.polys[:0]
+	s.cum = s.cum[:0]
+	this.m_dirty = true
+}
+
+func (this *DtNavMeshSampler) OnPolyChanged(mesh *DtNavMesh, ref DtPolyRef) {
+	var tile *DtMeshTile
+	var poly *DtPoly
+	if DtStatusFailed(mesh.GetTileAndPolyByRef(ref, &tile, &poly)) {
+		return
+	}
+	// Only the flags matter, rebuild the tile if the polygon was added or removed.
+	s := &this.m_tiles[this.tileIndex(tile)]
+	ip := int32(mesh.DecodePolyIdPoly(ref))
+	i := sort.Search(len(s.polys), func(i int) bool { return s.polys[i] >= ip })
+	indexed := i < len(s.polys) && s.polys[i] == ip
+	pass := poly.GetType() == DT_POLYTYPE_GROUND && this.m_filter.PassFilter(ref, tile, poly)
+	if indexed == pass {
+		return
+	}
+	this.buildTile(tile)
+	this.m_dirty = true
+}
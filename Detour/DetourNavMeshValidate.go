@@ -0,0 +1,468 @@
+package detour
+
+import "fmt"
+
+/// The kinds of problems reported by #DtValidateNavMesh.
+type DtNavMeshIssueType int
+
+const (
+	DT_ISSUE_DANGLING_LINK       DtNavMeshIssueType = iota ///< A link references a polygon that does not exist.
+	DT_ISSUE_BROKEN_LINK_LIST                              ///< A polygon's link list is out of range or cyclic.
+	DT_ISSUE_ASYMMETRIC_LINK                               ///< A polygon links to a neighbour which does not link back.
+	DT_ISSUE_NEIS_MISMATCH                                 ///< A link or internal neighbour does not agree with dtPoly::neis.
+	DT_ISSUE_UNCONNECTED_PORTAL                            ///< A portal edge has a loaded neighbour tile but no links.
+	DT_ISSUE_BVTREE                                        ///< The BV tree is malformed or a node does not contain its polygon.
+	DT_ISSUE_OFFMESH_UNCONNECTED                           ///< An off-mesh connection end point does not land on a polygon.
+	DT_ISSUE_DEGENERATE_POLY                               ///< A polygon has too few vertices, bad indices or zero area.
+	DT_ISSUE_NONCONVEX_POLY                                ///< A polygon is not convex.
+	DT_ISSUE_DETAIL_MESH                                   ///< A detail sub-mesh is out of range or references missing vertices.
+)
+
+var dtNavMeshIssueNames = [...]string{
+	"dangling link",
+	"broken link list",
+	"asymmetric link",
+	"neis mismatch",
+	"unconnected portal",
+	"bvtree",
+	"off-mesh unconnected",
+	"degenerate poly",
+	"non-convex poly",
+	"detail mesh",
+}
+
+func (this DtNavMeshIssueType) String() string {
+	if int(this) < 0 || int(this) >= len(dtNavMeshIssueNames) {
+		return fmt.Sprintf("issue(%d)", int(this))
+	}
+	return dtNavMeshIssueNames[this]
+}
+
+/// Describes a single problem found by #DtValidateNavMesh.
+type DtNavMeshIssue struct {
+	Type    DtNavMeshIssueType ///< The kind of problem.
+	Warning bool               ///< The problem may be legitimate. (E.g. The neighbour is only partially loaded.)
+	Tile    DtTileRef          ///< The tile where the problem was found.
+	Ref     DtPolyRef          ///< The polygon involved, or zero.
+	Other   DtPolyRef          ///< The other polygon involved, or zero.
+	Edge    int                ///< The polygon edge involved, or -1.
+	Index   int                ///< The BV node, off-mesh connection or detail triangle involved, or -1.
+	Message string             ///< Human readable description.
+}
+
+func (this *DtNavMeshIssue) String() string {
+	level := "error"
+	if this.Warning {
+		level = "warning"
+	}
+	return fmt.Sprintf("%s: %s: tile %d, poly %d: %s", level, this.Type, this.Tile, this.Ref, this.Message)
+}
+
+type dtNavMeshValidator struct {
+	mesh    *DtNavMesh
+	tile    *DtMeshTile
+	tileRef DtTileRef
+	base    DtPolyRef
+	broken  []bool ///< The polygons whose vertices cannot be read, by index.
+	issues  []DtNavMeshIssue
+}
+
+func (this *dtNavMeshValidator) report(t DtNavMeshIssueType, warning bool, ref, other DtPolyRef, edge, index int,
+	format string, args ...interface{}) {
+	this.issues = append(this.issues, DtNavMeshIssue{
+		Type:    t,
+		Warning: warning,
+		Tile:    this.tileRef,
+		Ref:     ref,
+		Other:   other,
+		Edge:    edge,
+		Index:   index,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+/// Returns true if the poly has a link to @p ref.
+func dtHasLinkTo(tile *DtMeshTile, poly *DtPoly, ref DtPolyRef) bool {
+	n := 0
+	for k := poly.FirstLink; k != DT_NULL_LINK && k < uint32(len(tile.Links)) && n < len(tile.Links); k = tile.Links[k].Next {
+		if tile.Links[k].Ref == ref {
+			return true
+		}
+		n++
+	}
+	return false
+}
+
+/// Returns true if the poly has a link leaving through the edge.
+func dtHasLinkOnEdge(tile *DtMeshTile, poly *DtPoly, edge uint8) bool {
+	n := 0
+	for k := poly.FirstLink; k != DT_NULL_LINK && k < uint32(len(tile.Links)) && n < len(tile.Links); k = tile.Links[k].Next {
+		if tile.Links[k].Edge == edge {
+			return true
+		}
+		n++
+	}
+	return false
+}
+
+func (this *dtNavMeshValidator) validatePolyGeometry(ip int) bool {
+	tile := this.tile
+	poly := &tile.Polys[ip]
+	ref := this.base | DtPolyRef(ip)
+	nv := int(poly.VertCount)
+
+	minVerts := 3
+	if poly.GetType() == DT_POLYTYPE_OFFMESH_CONNECTION {
+		minVerts = 2
+	}
+	if nv < minVerts || nv > int(DT_VERTS_PER_POLYGON) {
+		this.report(DT_ISSUE_DEGENERATE_POLY, false, ref, 0, -1, -1, "invalid vertex count %d", nv)
+		return false
+	}
+	for j := 0; j < nv; j++ {
+		if int32(poly.Verts[j]) >= tile.Header.VertCount {
+			this.report(DT_ISSUE_DEGENERATE_POLY, false, ref, 0, j, -1,
+				"vertex index %d out of range (%d verts)", poly.Verts[j], tile.Header.VertCount)
+			return false
+		}
+	}
+	if poly.GetType() == DT_POLYTYPE_OFFMESH_CONNECTION {
+		return true
+	}
+
+	// Area and convexity. All the corners must turn the same way as the polygon.
+	// A self-intersecting polygon may have no area, then the corners are checked
+	// against the first corner which turns.
+	const eps float32 = 1e-6
+	v0 := tile.Verts[poly.Verts[0]*3:]
+	var area float32
+	for j := 2; j < nv; j++ {
+		area += DtTriArea2D(v0, tile.Verts[poly.Verts[j-1]*3:], tile.Verts[poly.Verts[j]*3:])
+	}
+	if DtMathFabsf(area) < eps {
+		this.report(DT_ISSUE_DEGENERATE_POLY, false, ref, 0, -1, -1, "zero area")
+		area = 0
+	}
+	for j := 0; j < nv; j++ {
+		va := tile.Verts[poly.Verts[(j+nv-1)%nv]*3:]
+		vb := tile.Verts[poly.Verts[j]*3:]
+		vc := tile.Verts[poly.Verts[(j+1)%nv]*3:]
+		turn := DtTriArea2D(va, vb, vc)
+		if area == 0 {
+			if DtMathFabsf(turn) >= eps {
+				area = turn
+			}
+			continue
+		}
+		if turn*area < -eps {
+			this.report(DT_ISSUE_NONCONVEX_POLY, false, ref, 0, j, -1, "reflex corner at vertex %d", j)
+			break
+		}
+	}
+	return true
+}
+
+func (this *dtNavMeshValidator) validatePolyLinks(ip int) {
+	mesh := this.mesh
+	tile := this.tile
+	poly := &tile.Polys[ip]
+	ref := this.base | DtPolyRef(ip)
+	ground := poly.GetType() == DT_POLYTYPE_GROUND
+
+	n := 0
+	for k := poly.FirstLink; k != DT_NULL_LINK; k = tile.Links[k].Next {
+		if k >= uint32(len(tile.Links)) {
+			this.report(DT_ISSUE_BROKEN_LINK_LIST, false, ref, 0, -1, -1, "link index %d out of range", k)
+			return
+		}
+		if n++; n > len(tile.Links) {
+			this.report(DT_ISSUE_BROKEN_LINK_LIST, false, ref, 0, -1, -1, "cyclic link list")
+			return
+		}
+		link := &tile.Links[k]
+		if !mesh.IsValidPolyRef(link.Ref) {
+			this.report(DT_ISSUE_DANGLING_LINK, false, ref, link.Ref, int(link.Edge), -1, "link to invalid poly %d", link.Ref)
+			continue
+		}
+		var neiTile *DtMeshTile
+		var neiPoly *DtPoly
+		mesh.GetTileAndPolyByRefUnsafe(link.Ref, &neiTile, &neiPoly)
+
+		// Links to and from off-mesh connections are allowed to be one-way.
+		if !ground || neiPoly.GetType() != DT_POLYTYPE_GROUND || link.Edge == 0xff {
+			continue
+		}
+		if int(link.Edge) >= int(poly.VertCount) {
+			this.report(DT_ISSUE_NEIS_MISMATCH, false, ref, link.Ref, int(link.Edge), -1, "link edge %d out of range", link.Edge)
+			continue
+		}
+		nei := poly.Neis[link.Edge]
+		if link.Side == 0xff {
+			if neiTile != tile || nei&DT_EXT_LINK != 0 || DtPolyRef(nei) != (link.Ref&^this.base)+1 {
+				this.report(DT_ISSUE_NEIS_MISMATCH, false, ref, link.Ref, int(link.Edge), -1,
+					"internal link does not match neis 0x%04x", nei)
+			}
+		} else {
+			if nei&DT_EXT_LINK == 0 || uint8(nei&0xff) != link.Side {
+				this.report(DT_ISSUE_NEIS_MISMATCH, false, ref, link.Ref, int(link.Edge), -1,
+					"external link on side %d does not match neis 0x%04x", link.Side, nei)
+			}
+		}
+		if !dtHasLinkTo(neiTile, neiPoly, ref) {
+			this.report(DT_ISSUE_ASYMMETRIC_LINK, false, ref, link.Ref, int(link.Edge), -1,
+				"poly %d does not link back", link.Ref)
+		}
+	}
+
+	if !ground {
+		return
+	}
+
+	// Every neighbour in neis must be backed by a link.
+	const MAX_NEIS int = 32
+	var neis [MAX_NEIS]*DtMeshTile
+	for j := 0; j < int(poly.VertCount); j++ {
+		nei := poly.Neis[j]
+		if nei == 0 {
+			continue
+		}
+		if nei&DT_EXT_LINK == 0 {
+			idx := int32(nei) - 1
+			if idx >= tile.Header.PolyCount {
+				this.report(DT_ISSUE_NEIS_MISMATCH, false, ref, 0, j, -1, "neighbour index %d out of range", idx)
+				continue
+			}
+			if !dtHasLinkTo(tile, poly, this.base|DtPolyRef(idx)) {
+				this.report(DT_ISSUE_NEIS_MISMATCH, false, ref, this.base|DtPolyRef(idx), j, -1,
+					"no link to internal neighbour %d", idx)
+			}
+			continue
+		}
+		side := int(nei & 0xff)
+		if side > 7 {
+			this.report(DT_ISSUE_NEIS_MISMATCH, false, ref, 0, j, -1, "invalid portal side %d", side)
+			continue
+		}
+		if dtHasLinkOnEdge(tile, poly, uint8(j)) {
+			continue
+		}
+		if mesh.GetNeighbourTilesAt(tile.Header.X, tile.Header.Y, side, neis[:], MAX_NEIS) > 0 {
+			this.report(DT_ISSUE_UNCONNECTED_PORTAL, true, ref, 0, j, -1,
+				"portal edge on side %d has a neighbour tile but no links", side)
+		}
+	}
+}
+
+func (this *dtNavMeshValidator) validateOffMeshCons() {
+	tile := this.tile
+	for i := 0; i < int(tile.Header.OffMeshConCount); i++ {
+		con := &tile.OffMeshCons[i]
+		ip := int(tile.Header.OffMeshBase) + i
+		if int(con.Poly) != ip || ip >= int(tile.Header.PolyCount) {
+			this.report(DT_ISSUE_OFFMESH_UNCONNECTED, false, 0, 0, -1, i,
+				"connection poly %d, expected %d", con.Poly, ip)
+			continue
+		}
+		ref := this.base | DtPolyRef(ip)
+		poly := &tile.Polys[ip]
+		if poly.GetType() != DT_POLYTYPE_OFFMESH_CONNECTION {
+			this.report(DT_ISSUE_OFFMESH_UNCONNECTED, false, ref, 0, -1, i, "connection poly is not an off-mesh polygon")
+			continue
+		}
+		if this.broken[ip] {
+			continue
+		}
+		if !dtHasLinkOnEdge(tile, poly, 0) {
+			this.report(DT_ISSUE_OFFMESH_UNCONNECTED, false, ref, 0, 0, i,
+				"start point (%.2f, %.2f, %.2f) does not land on a polygon", con.Pos[0], con.Pos[1], con.Pos[2])
+		} else {
+			this.validateOffMeshLanding(ref, con, poly, 0, i)
+		}
+		if !dtHasLinkOnEdge(tile, poly, 1) {
+			// The end point may be in a tile which is not loaded.
+			this.report(DT_ISSUE_OFFMESH_UNCONNECTED, true, ref, 0, 1, i,
+				"end point (%.2f, %.2f, %.2f) does not land on a polygon", con.Pos[3], con.Pos[4], con.Pos[5])
+		} else {
+			this.validateOffMeshLanding(ref, con, poly, 1, i)
+		}
+	}
+}
+
+// validateOffMeshLanding checks that a linked end point of an off-mesh connection is still
+// within the connection radius of the point it was snapped to when the tile was connected.
+func (this *dtNavMeshValidator) validateOffMeshLanding(ref DtPolyRef, con *DtOffMeshConnection, poly *DtPoly, end int, i int) {
+	// Allow a little slack for the snapping.
+	const eps float32 = 0.01
+	p := con.Pos[end*3:]
+	v := this.tile.Verts[poly.Verts[end]*3:]
+	d := DtSqrFloat32(v[0]-p[0]) + DtSqrFloat32(v[2]-p[2])
+	if d > DtSqrFloat32(con.Rad+eps) || DtMathFabsf(v[1]-p[1]) > this.tile.Header.WalkableClimb+eps {
+		this.report(DT_ISSUE_OFFMESH_UNCONNECTED, false, ref, 0, end, i,
+			"end point %d (%.2f, %.2f, %.2f) is away from its landing point (%.2f, %.2f, %.2f)",
+			end, p[0], p[1], p[2], v[0], v[1], v[2])
+	}
+}
+
+func (this *dtNavMeshValidator) validateBVTree() {
+	tile := this.tile
+	if tile.BvTree == nil {
+		return
+	}
+	header := tile.Header
+	count := int(header.BvNodeCount)
+	qfac := header.BvQuantFactor
+	// The builder reserves two nodes per poly, the nodes past the root's escape index are unused.
+	if count > 0 && tile.BvTree[0].I < 0 && int(-tile.BvTree[0].I) <= count {
+		count = int(-tile.BvTree[0].I)
+	}
+	seen := make([]bool, header.PolyCount)
+
+	for i := 0; i < count; i++ {
+		node := &tile.BvTree[i]
+		if node.I < 0 {
+			if i-int(node.I) > count {
+				this.report(DT_ISSUE_BVTREE, false, 0, 0, -1, i, "escape index %d out of range", -node.I)
+				return
+			}
+			continue
+		}
+		ip := int(node.I)
+		if ip >= int(header.PolyCount) {
+			this.report(DT_ISSUE_BVTREE, false, 0, 0, -1, i, "leaf poly %d out of range", ip)
+			continue
+		}
+		ref := this.base | DtPolyRef(ip)
+		if seen[ip] {
+			this.report(DT_ISSUE_BVTREE, false, ref, 0, -1, i, "poly appears in several leaves")
+		}
+		seen[ip] = true
+		if this.broken[ip] {
+			continue
+		}
+
+		poly := &tile.Polys[ip]
+		var bmin, bmax [3]float32
+		DtVcopy(bmin[:], tile.Verts[poly.Verts[0]*3:])
+		DtVcopy(bmax[:], tile.Verts[poly.Verts[0]*3:])
+		for j := 1; j < int(poly.VertCount); j++ {
+			DtVmin(bmin[:], tile.Verts[poly.Verts[j]*3:])
+			DtVmax(bmax[:], tile.Verts[poly.Verts[j]*3:])
+		}
+		if tile.DetailMeshes != nil && ip < len(tile.DetailMeshes) {
+			pd := &tile.DetailMeshes[ip]
+			for j := 0; j < int(pd.VertCount) && int(pd.VertBase)+j < int(header.DetailVertCount); j++ {
+				DtVmin(bmin[:], tile.DetailVerts[(int(pd.VertBase)+j)*3:])
+				DtVmax(bmax[:], tile.DetailVerts[(int(pd.VertBase)+j)*3:])
+			}
+		}
+		// Allow one unit of quantization error.
+		for k := 0; k < 3; k++ {
+			qmin := DtMathMulf(bmin[k]-header.Bmin[k], qfac)
+			qmax := DtMathMulf(bmax[k]-header.Bmin[k], qfac)
+			if float32(node.Bmin[k]) > qmin+1 || float32(node.Bmax[k]) < qmax-1 {
+				this.report(DT_ISSUE_BVTREE, false, ref, 0, -1, i,
+					"node bounds [%d, %d] do not contain poly bounds [%.1f, %.1f] on axis %d",
+					node.Bmin[k], node.Bmax[k], qmin, qmax, k)
+				break
+			}
+		}
+	}
+
+	for ip := 0; ip < int(header.PolyCount); ip++ {
+		if !seen[ip] && tile.Polys[ip].GetType() == DT_POLYTYPE_GROUND {
+			this.report(DT_ISSUE_BVTREE, false, this.base|DtPolyRef(ip), 0, -1, -1, "poly is missing from the BV tree")
+		}
+	}
+}
+
+func (this *dtNavMeshValidator) validateDetailMesh(ip int) {
+	tile := this.tile
+	header := tile.Header
+	poly := &tile.Polys[ip]
+	ref := this.base | DtPolyRef(ip)
+	if poly.GetType() != DT_POLYTYPE_GROUND || tile.DetailMeshes == nil {
+		return
+	}
+	if ip >= len(tile.DetailMeshes) {
+		this.report(DT_ISSUE_DETAIL_MESH, false, ref, 0, -1, -1, "poly has no detail sub-mesh")
+		return
+	}
+	pd := &tile.DetailMeshes[ip]
+	if int(pd.VertBase)+int(pd.VertCount) > int(header.DetailVertCount) {
+		this.report(DT_ISSUE_DETAIL_MESH, false, ref, 0, -1, -1,
+			"vertices [%d, %d) out of range (%d detail verts)", pd.VertBase, int(pd.VertBase)+int(pd.VertCount), header.DetailVertCount)
+		return
+	}
+	if int(pd.TriBase)+int(pd.TriCount) > int(header.DetailTriCount) {
+		this.report(DT_ISSUE_DETAIL_MESH, false, ref, 0, -1, -1,
+			"triangles [%d, %d) out of range (%d detail tris)", pd.TriBase, int(pd.TriBase)+int(pd.TriCount), header.DetailTriCount)
+		return
+	}
+	if pd.TriCount == 0 {
+		this.report(DT_ISSUE_DETAIL_MESH, false, ref, 0, -1, -1, "detail sub-mesh has no triangles")
+		return
+	}
+	nverts := int(poly.VertCount) + int(pd.VertCount)
+	for j := 0; j < int(pd.TriCount); j++ {
+		t := tile.DetailTris[(int(pd.TriBase)+j)*4:]
+		for k := 0; k < 3; k++ {
+			if int(t[k]) >= nverts {
+				this.report(DT_ISSUE_DETAIL_MESH, false, ref, 0, -1, int(pd.TriBase)+j,
+					"triangle vertex index %d out of range (%d verts)", t[k], nverts)
+				break
+			}
+		}
+	}
+}
+
+/// Checks the integrity of a single tile of the navigation mesh.
+///  @param[in]		mesh	The navigation mesh the tile belongs to.
+///  @param[in]		tile	The tile to check.
+/// @return The problems found, or nil if the tile is valid.
+func DtValidateMeshTile(mesh *DtNavMesh, tile *DtMeshTile) []DtNavMeshIssue {
+	if tile == nil || tile.Header == nil {
+		return nil
+	}
+	v := &dtNavMeshValidator{
+		mesh:    mesh,
+		tile:    tile,
+		tileRef: mesh.GetTileRef(tile),
+		base:    mesh.GetPolyRefBase(tile),
+		broken:  make([]bool, tile.Header.PolyCount),
+	}
+	for i := 0; i < int(tile.Header.PolyCount); i++ {
+		if !v.validatePolyGeometry(i) {
+			v.broken[i] = true
+			continue
+		}
+		v.validatePolyLinks(i)
+		v.validateDetailMesh(i)
+	}
+	v.validateOffMeshCons()
+	v.validateBVTree()
+	return v.issues
+}
+
+/// Checks the integrity of all the tiles of the navigation mesh.
+///
+/// The following is checked:
+/// - Links reference valid polygons and links between ground polygons are symmetric.
+/// - Links agree with dtPoly::neis, and portal edges facing a loaded tile have links.
+/// - BV tree leaves contain the bounds of their polygons and cover all ground polygons.
+/// - Off-mesh connection end points are linked to polygons within their radius.
+/// - Polygons are not degenerate and are convex.
+/// - Detail meshes and their triangle indices are in range.
+///
+///  @param[in]		mesh	The navigation mesh to check.
+/// @return The problems found, or nil if the navigation mesh is valid.
+func DtValidateNavMesh(mesh *DtNavMesh) []DtNavMeshIssue {
+	var issues []DtNavMeshIssue
+	for i := 0; i < int(mesh.GetMaxTiles()); i++ {
+		tile := mesh.GetTile(i)
+		if tile == nil || tile.Header == nil {
+			continue
+		}
+		issues = append(issues, DtValidateMeshTile(mesh, tile)...)
+	}
+	return issues
+}
@@ -35,7 +35,7 @@ type DtNodeIndex uint16
 const DT_NULL_IDX DtNodeIndex = ^DtNodeIndex(0)
 
 const DT_NODE_PARENT_BITS uint32 = 24
-const DT_NODE_STATE_BITS uint32 = 2
+const DT_NODE_STATE_BITS uint32 = 4 // 2 bits for the side a polygon is entered from, 2 for the time labels of FindTimedPath.
 const DT_NODE_FLAGS_BITS uint32 = 3
 
 type DtNode struct {
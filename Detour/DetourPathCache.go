@@ -0,0 +1,272 @@
+package detour
+
+/// Counters of a path cache.
+/// @see #DtPathCache.GetStats
+type DtPathCacheStats struct {
+	Hits          int ///< The number of paths found in the cache.
+	Misses        int ///< The number of paths which were not in the cache.
+	Evictions     int ///< The number of paths dropped to make room for new ones.
+	Invalidations int ///< The number of paths dropped because their tiles changed.
+}
+
+/// The settings of the default filter implementation, filters with the same
+/// settings find the same paths.
+type dtPathCacheFilter struct {
+	includeFlags uint16
+	excludeFlags uint16
+	areaCost     [DT_MAX_AREAS]float32
+}
+
+type dtPathCacheKey struct {
+	startRef DtPolyRef
+	endRef   DtPolyRef
+	filter   dtPathCacheFilter
+}
+
+type dtPathCacheEntry struct {
+	key   dtPathCacheKey
+	path  []DtPolyRef       ///< The cached path. (Start to end.)
+	tiles []DtTileRef       ///< The tiles the path crosses, with the salts they had when it was found.
+	prev  *dtPathCacheEntry ///< The more recently used entry.
+	next  *dtPathCacheEntry ///< The less recently used entry.
+}
+
+/// A least recently used cache of the paths found by #DtNavMeshQuery.FindPath.
+///
+/// Paths are keyed by their start and end polygons and by the include flags,
+/// exclude flags and area costs of the filter they were found with, the start and end positions are ignored.
+/// Only complete paths are cached, and never those found with a custom filter.
+///
+/// A path is dropped when a tile it crosses is removed, when a tile is added
+/// next to or on top of a tile it crosses, and when the flags of a polygon of
+/// a tile it crosses change. The salts of the crossed tiles are also checked
+/// when the path is looked up, so paths crossing tiles which were replaced are
+/// never returned.
+/// @see #DtNavMeshQuery.SetPathCache
+/// @ingroup detour
+type DtPathCache struct {
+	m_nav         *DtNavMesh                                ///< The navigation mesh the paths were found on.
+	m_maxEntries  int                                       ///< The maximum number of cached paths.
+	m_entries     map[dtPathCacheKey]*dtPathCacheEntry      ///< The cached paths.
+	m_tileEntries map[uint32]map[*dtPathCacheEntry]struct{} ///< The cached paths crossing each tile index.
+	m_lru         dtPathCacheEntry                          ///< Sentinel of the entries, most recently used first.
+	m_stats       DtPathCacheStats                          ///< The cache counters.
+}
+
+/// Allocates a path cache for the navigation mesh and starts listening to its changes.
+///  @param[in]	nav			The navigation mesh the paths are found on.
+///  @param[in]	maxEntries	The maximum number of cached paths. [Limit: > 0]
+/// @return The path cache, or null if @p maxEntries is not valid.
+func DtAllocPathCache(nav *DtNavMesh, maxEntries int) *DtPathCache {
+	if maxEntries <= 0 {
+		return nil
+	}
+	cache := &DtPathCache{
+		m_nav:        nav,
+		m_maxEntries: maxEntries,
+	}
+	cache.Clear()
+	nav.AddListener(cache)
+	return cache
+}
+
+/// Stops listening to the navigation mesh and frees the cached paths.
+///  @param[in]	cache	A path cache allocated using #DtAllocPathCache
+func DtFreePathCache(cache *DtPathCache) {
+	if cache == nil {
+		return
+	}
+	cache.m_nav.RemoveListener(cache)
+	cache.m_entries = nil
+	cache.m_tileEntries = nil
+	cache.m_lru.prev = nil
+	cache.m_lru.next = nil
+}
+
+/// Drops all the cached paths. The counters are kept.
+func (this *DtPathCache) Clear() {
+	this.m_entries = make(map[dtPathCacheKey]*dtPathCacheEntry)
+	this.m_tileEntries = make(map[uint32]map[*dtPathCacheEntry]struct{})
+	this.m_lru.prev = &this.m_lru
+	this.m_lru.next = &this.m_lru
+}
+
+/// Gets the number of cached paths.
+func (this *DtPathCache) GetEntryCount() int { return len(this.m_entries) }
+
+/// Gets the maximum number of cached paths.
+func (this *DtPathCache) GetMaxEntries() int { return this.m_maxEntries }
+
+/// Gets the cache counters.
+func (this *DtPathCache) GetStats() DtPathCacheStats { return this.m_stats }
+
+/// Resets the cache counters.
+func (this *DtPathCache) ResetStats() { this.m_stats = DtPathCacheStats{} }
+
+/// Looks up a path.
+///  @param[in]		startRef	The reference id of the start polygon.
+///  @param[in]		endRef		The reference id of the end polygon.
+///  @param[in]		filter		The polygon filter the path was found with.
+///  @param[out]	path		The cached path. (Start to end.) [(polyRef) * @p pathCount]
+///  @param[out]	pathCount	The number of polygons returned in the @p path array.
+///  @param[in]		maxPath		The maximum number of polygons the @p path array can hold. [Limit: >= 1]
+/// @returns The status flags of the cached path, or #DT_FAILURE if the path is not cached.
+/// @par
+///
+/// If the path array is too small to hold the cached path, it is filled from
+/// the start polygon and #DT_BUFFER_TOO_SMALL is set, as in #DtNavMeshQuery.FindPath.
+func (this *DtPathCache) Get(startRef, endRef DtPolyRef, filter *DtQueryFilter,
+	path []DtPolyRef, pathCount *int, maxPath int) DtStatus {
+	key := dtPathCacheKey{startRef, endRef, filter.pathCacheSettings()}
+	entry := this.m_entries[key]
+	if entry != nil && !this.isValid(entry) {
+		this.remove(entry)
+		this.m_stats.Invalidations++
+		entry = nil
+	}
+	if entry == nil {
+		this.m_stats.Misses++
+		return DT_FAILURE
+	}
+	this.m_stats.Hits++
+
+	// Move to the front of the recently used list.
+	this.unlink(entry)
+	this.pushFront(entry)
+
+	n := copy(path[:maxPath], entry.path)
+	*pathCount = n
+	if n < len(entry.path) {
+		return DT_SUCCESS | DT_BUFFER_TOO_SMALL
+	}
+	return DT_SUCCESS
+}
+
+/// Stores a complete path, replacing the least recently used path if the cache is full.
+///  @param[in]	startRef	The reference id of the start polygon.
+///  @param[in]	endRef		The reference id of the end polygon.
+///  @param[in]	filter		The polygon filter the path was found with.
+///  @param[in]	path		The path to store. (Start to end.) [(polyRef) * @p pathCount]
+///  @param[in]	pathCount	The number of polygons in the @p path array.
+func (this *DtPathCache) Put(startRef, endRef DtPolyRef, filter *DtQueryFilter,
+	path []DtPolyRef, pathCount int) {
+	if pathCount <= 0 {
+		return
+	}
+	key := dtPathCacheKey{startRef, endRef, filter.pathCacheSettings()}
+	if entry := this.m_entries[key]; entry != nil {
+		this.remove(entry)
+	}
+	for len(this.m_entries) >= this.m_maxEntries {
+		this.remove(this.m_lru.prev)
+		this.m_stats.Evictions++
+	}
+
+	entry := &dtPathCacheEntry{key: key}
+	entry.path = append([]DtPolyRef(nil), path[:pathCount]...)
+	for _, ref := range entry.path {
+		var salt, it, ip uint32
+		this.m_nav.DecodePolyId(ref, &salt, &it, &ip)
+		tileRef := DtTileRef(this.m_nav.EncodePolyId(salt, it, 0))
+		if n := len(entry.tiles); n > 0 && entry.tiles[n-1] == tileRef {
+			continue
+		}
+		entry.tiles = append(entry.tiles, tileRef)
+		entries := this.m_tileEntries[it]
+		if entries == nil {
+			entries = make(map[*dtPathCacheEntry]struct{})
+			this.m_tileEntries[it] = entries
+		}
+		entries[entry] = struct{}{}
+	}
+	this.m_entries[key] = entry
+	this.pushFront(entry)
+}
+
+/// Returns true if all the tiles crossed by the path are still the same.
+func (this *DtPathCache) isValid(entry *dtPathCacheEntry) bool {
+	for _, ref := range entry.tiles {
+		if this.m_nav.GetTileByRef(ref) == nil {
+			return false
+		}
+	}
+	return true
+}
+
+func (this *DtPathCache) pushFront(entry *dtPathCacheEntry) {
+	entry.prev = &this.m_lru
+	entry.next = this.m_lru.next
+	entry.next.prev = entry
+	this.m_lru.next = entry
+}
+
+func (this *DtPathCache) unlink(entry *dtPathCacheEntry) {
+	entry.prev.next = entry.next
+	entry.next.prev = entry.prev
+	entry.prev = nil
+	entry.next = nil
+}
+
+func (this *DtPathCache) remove(entry *dtPathCacheEntry) {
+	this.unlink(entry)
+	delete(this.m_entries, entry.key)
+	for _, ref := range entry.tiles {
+		it := this.m_nav.DecodePolyIdTile(DtPolyRef(ref))
+		if entries := this.m_tileEntries[it]; entries != nil {
+			delete(entries, entry)
+			if len(entries) == 0 {
+				delete(this.m_tileEntries, it)
+			}
+		}
+	}
+}
+
+/// Drops the paths crossing the tile index.
+func (this *DtPathCache) invalidateTile(it uint32) {
+	for entry := range this.m_tileEntries[it] {
+		this.remove(entry)
+		this.m_stats.Invalidations++
+	}
+}
+
+/// Implements #DtNavMeshListener. The links of the neighbour tiles changed.
+func (this *DtPathCache) OnTileAdded(mesh *DtNavMesh, tile *DtMeshTile) {
+	this.invalidateTile(mesh.DecodePolyIdTile(DtPolyRef(mesh.GetTileRef(tile))))
+
+	const MAX_NEIS = 32
+	var neis [MAX_NEIS]*DtMeshTile
+	for side := -1; side < 8; side++ {
+		var nneis int
+		if side < 0 {
+			nneis = mesh.GetTilesAt(tile.Header.X, tile.Header.Y, neis[:], MAX_NEIS)
+		} else {
+			nneis = mesh.GetNeighbourTilesAt(tile.Header.X, tile.Header.Y, side, neis[:], MAX_NEIS)
+		}
+		for j := 0; j < nneis; j++ {
+			if neis[j] != tile {
+				this.invalidateTile(mesh.DecodePolyIdTile(DtPolyRef(mesh.GetTileRef(neis[j]))))
+			}
+		}
+	}
+}
+
+/// Implements #DtNavMeshListener.
+func (this *DtPathCache) OnTileRemoved(mesh *DtNavMesh, tile *DtMeshTile) {
+	this.invalidateTile(mesh.DecodePolyIdTile(DtPolyRef(mesh.GetTileRef(tile))))
+}
+
+/// Implements #DtNavMeshListener.
+func (this *DtPathCache) OnPolyChanged(mesh *DtNavMesh, ref DtPolyRef) {
+	this.invalidateTile(mesh.DecodePolyIdTile(ref))
+}
+
+/// Returns the filter settings the cached paths are keyed by.
+func (this *DtQueryFilter) pathCacheSettings() dtPathCacheFilter {
+	return dtPathCacheFilter{this.m_includeFlags, this.m_excludeFlags, this.m_areaCost}
+}
+
+/// Sets the path cache used by #FindPath.
+///  @param[in]	cache	The path cache of the query's navigation mesh, or null.
+func (this *DtNavMeshQuery) SetPathCache(cache *DtPathCache) {
+	this.m_pathCache = cache
+}
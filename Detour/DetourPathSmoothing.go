@@ -0,0 +1,334 @@
+package detour
+
+import "math"
+
+/// Curve types used by #DtNavMeshQuery.SmoothPath
+type DtSmoothPathType int
+
+const (
+	DT_SMOOTH_CATMULL_ROM DtSmoothPathType = 0 ///< Catmull-Rom spline through the corners of the path.
+	DT_SMOOTH_BEZIER      DtSmoothPathType = 1 ///< Quadratic Bézier curves cutting the corners of the path.
+)
+
+/// The maximum number of polygons a smoothed segment is checked against.
+const dtSmoothMaxRayPolys = 256
+
+/// The distance a segment can reach past the boundary it hit.
+const dtSmoothHitTolerance = 0.01
+
+/// Smooths a straight path with curves.
+///  @param[in]		straightPath		The points of the straight path. [(x, y, z) * @p straightPathCount]
+///  @param[in]		straightPathRefs	The reference id of the polygon that is being entered at each point.
+///  									[(polyRef) * @p straightPathCount]
+///  @param[in]		straightPathCount	The number of points in the straight path.
+///  @param[in]		filter				The polygon filter to apply to the query.
+///  @param[in]		smoothType			The curve type. (see: #DtSmoothPathType)
+///  @param[in]		subdivisions		The number of segments each curve is made of. [Limit: > 0]
+///  @param[out]	smoothPath			The points of the smoothed path. [(x, y, z) * @p smoothPathCount]
+///  @param[out]	smoothPathCount		The number of points in the smoothed path.
+///  @param[in]		maxSmoothPath		The maximum number of points the smoothed path array can hold. [Limit: > 0]
+/// @returns The status flags for the query.
+/// @par
+///
+/// The straight path and its polygon references are the output of #FindStraightPath.
+///
+/// #DT_SMOOTH_CATMULL_ROM passes through all the corners, replacing each
+/// segment with a curve. #DT_SMOOTH_BEZIER replaces each corner with a curve
+/// from the middle of its previous segment to the middle of its next one, and
+/// does not pass through the corners.
+///
+/// Each curve is checked with #Raycast, and curves which leave the navigation
+/// mesh are replaced by the straight path they stand for. Segments crossing
+/// off-mesh connections are never smoothed. The heights of the curve points
+/// are set on the polygons they lie on.
+///
+/// If the smoothed path array is too small for the entire result, it is filled
+/// as far as possible from the start toward the end position.
+func (this *DtNavMeshQuery) SmoothPath(straightPath []float32, straightPathRefs []DtPolyRef, straightPathCount int,
+	filter *DtQueryFilter, smoothType DtSmoothPathType, subdivisions int,
+	smoothPath []float32, smoothPathCount *int, maxSmoothPath int) DtStatus {
+	DtAssert(this.m_nav != nil)
+
+	*smoothPathCount = 0
+	if straightPathCount <= 0 || len(straightPath) < straightPathCount*3 || len(straightPathRefs) < straightPathCount ||
+		filter == nil || subdivisions <= 0 || maxSmoothPath <= 0 ||
+		(smoothType != DT_SMOOTH_CATMULL_ROM && smoothType != DT_SMOOTH_BEZIER) {
+		return DT_FAILURE | DT_INVALID_PARAM
+	}
+
+	out := dtSmoothPathWriter{path: smoothPath, max: maxSmoothPath}
+	out.append(straightPath[0:3])
+
+	// The chain of points checked for a curve, starting at a point of the straight path.
+	pts := make([]float32, (subdivisions+3)*3)
+	corner := func(i int) []float32 { return straightPath[i*3 : i*3+3] }
+
+	if smoothType == DT_SMOOTH_CATMULL_ROM {
+		for i := 0; i+1 < straightPathCount; i++ {
+			p0 := corner(int(DtMaxInt32(int32(i-1), 0)))
+			p1 := corner(i)
+			p2 := corner(i + 1)
+			p3 := corner(int(DtMinInt32(int32(i+2), int32(straightPathCount-1))))
+
+			DtVcopy(pts[0:], p1)
+			for k := 1; k <= subdivisions; k++ {
+				dtCatmullRom(pts[k*3:], p0, p1, p2, p3, float32(k)/float32(subdivisions))
+			}
+			DtVcopy(pts[subdivisions*3:], p2)
+
+			if _, ok := this.raycastChain(straightPathRefs[i], pts, subdivisions+1, filter); ok {
+				out.appendPoints(pts[3:], subdivisions)
+			} else {
+				out.append(p2)
+			}
+		}
+	} else {
+		// The curve around each corner goes from the middle of its previous
+		// segment to the middle of the next one.
+		var start, end [3]float32
+		DtVcopy(start[:], corner(0))
+		for i := 1; i+1 < straightPathCount; i++ {
+			p0 := corner(i - 1)
+			p1 := corner(i)
+			p2 := corner(i + 1)
+			DtVlerp(end[:], p1, p2, 0.5)
+			if i+2 == straightPathCount {
+				DtVcopy(end[:], p2)
+			}
+
+			// The chain starts on the previous corner, whose polygon is known.
+			DtVcopy(pts[0:], p0)
+			DtVcopy(pts[3:], start[:])
+			for k := 1; k <= subdivisions; k++ {
+				dtQuadraticBezier(pts[(k+1)*3:], start[:], p1, end[:], float32(k)/float32(subdivisions))
+			}
+			DtVcopy(pts[(subdivisions+1)*3:], end[:])
+
+			ok := !this.isOffMeshConnection(straightPathRefs[i])
+			if ok {
+				_, ok = this.raycastChain(straightPathRefs[i-1], pts, subdivisions+2, filter)
+			}
+			if ok {
+				out.appendPoints(pts[3:], subdivisions+1)
+			} else {
+				out.append(start[:])
+				out.append(p1)
+				out.append(end[:])
+			}
+			DtVlerp(start[:], p1, p2, 0.5)
+		}
+		out.append(corner(straightPathCount - 1))
+	}
+
+	*smoothPathCount = out.count
+	if out.overflow {
+		return DT_SUCCESS | DT_BUFFER_TOO_SMALL
+	}
+	return DT_SUCCESS
+}
+
+/// Rounds the corners of a straight path with arcs around them.
+///  @param[in]		straightPath		The points of the straight path. [(x, y, z) * @p straightPathCount]
+///  @param[in]		straightPathRefs	The reference id of the polygon that is being entered at each point.
+///  									[(polyRef) * @p straightPathCount]
+///  @param[in]		straightPathCount	The number of points in the straight path.
+///  @param[in]		filter				The polygon filter to apply to the query.
+///  @param[in]		radius				The radius of the arcs, usually the agent radius. [Limit: > 0]
+///  @param[in]		subdivisions		The number of segments each arc is made of. [Limit: > 0]
+///  @param[out]	smoothPath			The points of the rounded path. [(x, y, z) * @p smoothPathCount]
+///  @param[out]	smoothPathCount		The number of points in the rounded path.
+///  @param[in]		maxSmoothPath		The maximum number of points the rounded path array can hold. [Limit: > 0]
+/// @returns The status flags for the query.
+/// @par
+///
+/// The corners of a straight path are the vertices the path bends around.
+/// Each corner is replaced with an arc centered on it, on the outer side of
+/// the turn, so the path keeps @p radius away from the corner. The radius is
+/// reduced on short segments so consecutive arcs do not overlap.
+///
+/// Each arc is checked with #Raycast, and corners whose arc leaves the
+/// navigation mesh are kept sharp. Corners at off-mesh connections are never
+/// rounded. The heights of the arc points are set on the polygons they lie on.
+///
+/// If the rounded path array is too small for the entire result, it is filled
+/// as far as possible from the start toward the end position.
+func (this *DtNavMeshQuery) RoundPathCorners(straightPath []float32, straightPathRefs []DtPolyRef, straightPathCount int,
+	filter *DtQueryFilter, radius float32, subdivisions int,
+	smoothPath []float32, smoothPathCount *int, maxSmoothPath int) DtStatus {
+	DtAssert(this.m_nav != nil)
+
+	*smoothPathCount = 0
+	if straightPathCount <= 0 || len(straightPath) < straightPathCount*3 || len(straightPathRefs) < straightPathCount ||
+		filter == nil || !(radius > 0) || subdivisions <= 0 || maxSmoothPath <= 0 {
+		return DT_FAILURE | DT_INVALID_PARAM
+	}
+
+	out := dtSmoothPathWriter{path: smoothPath, max: maxSmoothPath}
+	out.append(straightPath[0:3])
+
+	pts := make([]float32, (subdivisions+3)*3)
+	corner := func(i int) []float32 { return straightPath[i*3 : i*3+3] }
+
+	// The point the path left the previous corner from, and its polygon.
+	var last [3]float32
+	DtVcopy(last[:], corner(0))
+	lastRef := straightPathRefs[0]
+
+	for i := 1; i+1 < straightPathCount; i++ {
+		p0 := corner(i - 1)
+		p1 := corner(i)
+		p2 := corner(i + 1)
+
+		var d0, d1, turn [3]float32
+		DtVsub(d0[:], p1, p0)
+		DtVsub(d1[:], p2, p1)
+		d0[1], d1[1] = 0, 0
+		len0 := DtVlen(d0[:])
+		len1 := DtVlen(d1[:])
+		rounded := false
+		if len0 > 1e-6 && len1 > 1e-6 &&
+			!this.isOffMeshConnection(straightPathRefs[i-1]) && !this.isOffMeshConnection(straightPathRefs[i]) {
+			DtVscale(d0[:], d0[:], 1/len0)
+			DtVscale(d1[:], d1[:], 1/len1)
+			DtVsub(turn[:], d1[:], d0[:])
+
+			// Keep half of each segment for the neighbour corners.
+			r := DtMinFloat32(radius, DtMinFloat32(len0, len1)*0.5)
+
+			// The normals of both segments on the outer side of the turn.
+			var n0, n1 [3]float32
+			DtVset(n0[:], d0[2], 0, -d0[0])
+			if DtVdot2D(n0[:], turn[:]) > 0 {
+				DtVscale(n0[:], n0[:], -1)
+			}
+			DtVset(n1[:], d1[2], 0, -d1[0])
+			if DtVdot2D(n1[:], turn[:]) > 0 {
+				DtVscale(n1[:], n1[:], -1)
+			}
+			a0 := math.Atan2(float64(n0[2]), float64(n0[0]))
+			a1 := math.Atan2(float64(n1[2]), float64(n1[0]))
+			da := a1 - a0
+			for da > math.Pi {
+				da -= 2 * math.Pi
+			}
+			for da < -math.Pi {
+				da += 2 * math.Pi
+			}
+
+			DtVcopy(pts[0:], last[:])
+			for k := 0; k <= subdivisions; k++ {
+				a := a0 + da*float64(k)/float64(subdivisions)
+				v := pts[(k+1)*3:]
+				DtVset(v, p1[0]+DtMathMulf(r, float32(math.Cos(a))), p1[1], p1[2]+DtMathMulf(r, float32(math.Sin(a))))
+			}
+			// The segment leaving the arc is checked too, in case the next corner is kept sharp.
+			var ref DtPolyRef
+			if ref, rounded = this.raycastChain(lastRef, pts, subdivisions+2, filter); rounded {
+				DtVcopy(pts[(subdivisions+2)*3:], p2)
+				_, rounded = this.raycastChain(ref, pts[(subdivisions+1)*3:], 2, filter)
+			}
+			if rounded {
+				out.appendPoints(pts[3:], subdivisions+1)
+				DtVcopy(last[:], pts[(subdivisions+1)*3:])
+				lastRef = ref
+			}
+		}
+		if !rounded {
+			out.append(p1)
+			DtVcopy(last[:], p1)
+			lastRef = straightPathRefs[i]
+		}
+	}
+	if straightPathCount > 1 {
+		out.append(corner(straightPathCount - 1))
+	}
+
+	*smoothPathCount = out.count
+	if out.overflow {
+		return DT_SUCCESS | DT_BUFFER_TOO_SMALL
+	}
+	return DT_SUCCESS
+}
+
+/// Checks that the segments between consecutive points are on the navigation
+/// mesh, starting from the polygon of the first point, and sets the heights of
+/// the other points on the polygons they lie on. Returns the polygon of the
+/// last point, and false if a segment leaves the navigation mesh.
+func (this *DtNavMeshQuery) raycastChain(ref DtPolyRef, pts []float32, npts int, filter *DtQueryFilter) (DtPolyRef, bool) {
+	var path [dtSmoothMaxRayPolys]DtPolyRef
+	for i := 1; i < npts; i++ {
+		if !this.m_nav.IsValidPolyRef(ref) || this.isOffMeshConnection(ref) {
+			return 0, false
+		}
+		if DtVdist2DSqr(pts[(i-1)*3:], pts[i*3:]) < 1e-12 {
+			pts[i*3+1] = pts[(i-1)*3+1]
+			continue
+		}
+		// A hit at the end means the point is on the boundary of the mesh.
+		var t float32
+		var npath int
+		status := this.Raycast(ref, pts[(i-1)*3:], pts[i*3:], filter, &t, nil, path[:], &npath, dtSmoothMaxRayPolys)
+		if DtStatusFailed(status) || DtStatusDetail(status, DT_BUFFER_TOO_SMALL) || npath == 0 ||
+			(1-t)*DtVdist2D(pts[(i-1)*3:], pts[i*3:]) > dtSmoothHitTolerance {
+			return 0, false
+		}
+		ref = path[npath-1]
+		var h float32
+		if DtStatusSucceed(this.GetPolyHeight(ref, pts[i*3:], &h)) {
+			pts[i*3+1] = h
+		}
+	}
+	return ref, true
+}
+
+/// Returns true if the polygon is an off-mesh connection.
+func (this *DtNavMeshQuery) isOffMeshConnection(ref DtPolyRef) bool {
+	var tile *DtMeshTile
+	var poly *DtPoly
+	return DtStatusSucceed(this.m_nav.GetTileAndPolyByRef(ref, &tile, &poly)) && poly.GetType() == DT_POLYTYPE_OFFMESH_CONNECTION
+}
+
+type dtSmoothPathWriter struct {
+	path     []float32
+	count    int
+	max      int
+	overflow bool
+}
+
+func (this *dtSmoothPathWriter) append(pos []float32) {
+	// Skip duplicate points, their heights may differ when they were set on the polygons.
+	if this.count > 0 && DtVdist2DSqr(this.path[(this.count-1)*3:], pos) < 1e-12 {
+		return
+	}
+	if this.count >= this.max {
+		this.overflow = true
+		return
+	}
+	DtVcopy(this.path[this.count*3:], pos)
+	this.count++
+}
+
+func (this *dtSmoothPathWriter) appendPoints(pts []float32, npts int) {
+	for i := 0; i < npts; i++ {
+		this.append(pts[i*3:])
+	}
+}
+
+/// Evaluates a uniform Catmull-Rom spline between @p p1 and @p p2.
+func dtCatmullRom(dest, p0, p1, p2, p3 []float32, t float32) {
+	t2 := DtMathMulf(t, t)
+	t3 := DtMathMulf(t2, t)
+	for i := 0; i < 3; i++ {
+		dest[i] = 0.5 * (DtMathMulf(2, p1[i]) + DtMathMulf(p2[i]-p0[i], t) +
+			DtMathMulf(DtMathMulf(2, p0[i])-DtMathMulf(5, p1[i])+DtMathMulf(4, p2[i])-p3[i], t2) +
+			DtMathMulf(DtMathMulf(3, p1[i])-p0[i]-DtMathMulf(3, p2[i])+p3[i], t3))
+	}
+}
+
+/// Evaluates a quadratic Bézier curve from @p p0 to @p p2 with control point @p p1.
+func dtQuadraticBezier(dest, p0, p1, p2 []float32, t float32) {
+	u := 1 - t
+	for i := 0; i < 3; i++ {
+		dest[i] = DtMathMulf(u*u, p0[i]) + DtMathMulf(2*u*t, p1[i]) + DtMathMulf(t*t, p2[i])
+	}
+}
@@ -0,0 +1,302 @@
+package detour
+
+import (
+	"math"
+	"sort"
+)
+
+/// The shape limiting the points of #DtNavMeshQuery.FindPoissonDiskPoints.
+type DtPoissonDiskRegionType int
+
+const (
+	DT_POISSON_REGION_NONE    DtPoissonDiskRegionType = iota ///< The whole navigation mesh.
+	DT_POISSON_REGION_CIRCLE                                 ///< A circle around the start polygon. (See: #FindPolysAroundCircle)
+	DT_POISSON_REGION_POLYGON                                ///< A convex polygon around the start polygon. (See: #FindPolysAroundShape)
+)
+
+/// Parameters of #DtNavMeshQuery.FindPoissonDiskPoints.
+type DtPoissonDiskParams struct {
+	MinDist     float32    ///< The minimum distance between two points.
+	HalfExtents [3]float32 ///< The search distance used to snap candidates on the navigation mesh. [(x, y, z)]
+	MaxAttempts int        ///< The number of candidates tried around a point before giving up on it. [Default: 30]
+
+	RegionType DtPoissonDiskRegionType ///< The shape limiting the points.
+	StartRef   DtPolyRef               ///< The polygon where the region search starts. (Circle and polygon only.)
+	Center     [3]float32              ///< The center of the circle. [(x, y, z)] (Circle only.)
+	Radius     float32                 ///< The radius of the circle. (Circle only.)
+	Verts      []float32               ///< The vertices of the convex polygon. [(x, y, z) * @p NVerts] (Polygon only.)
+	NVerts     int                     ///< The number of vertices of the polygon. (Polygon only.)
+	MaxPolys   int                     ///< The maximum number of polygons in the region. [Default: the node pool size] (Circle and polygon only.)
+}
+
+/// Background grid of the Poisson-disk sampler, with cells of MinDist/sqrt(2) on the xz-plane.
+/// Several points can share a cell when they are on different floors.
+type dtPoissonGrid struct {
+	cellSize float32
+	cells    map[[2]int32][]int
+}
+
+func (this *dtPoissonGrid) cell(pos []float32) [2]int32 {
+	return [2]int32{int32(DtMathFloorf(pos[0] / this.cellSize)), int32(DtMathFloorf(pos[2] / this.cellSize))}
+}
+
+type dtPoissonSampler struct {
+	query   *DtNavMeshQuery
+	params  *DtPoissonDiskParams
+	filter  *DtQueryFilter
+	rng     DtRNG
+	grid    dtPoissonGrid
+	region  map[DtPolyRef]bool ///< Polygons of the region, or nil for the whole mesh.
+	polys   []DtPolyRef        ///< Polygons used to seed new points.
+	cum     []float32          ///< Cumulative area of the seed polygons.
+	refs    []DtPolyRef
+	points  []float32
+	count   int
+	maxPts  int
+	minDist float32
+}
+
+func (this *dtPoissonSampler) addSeedPoly(ref DtPolyRef, tile *DtMeshTile, poly *DtPoly) {
+	if poly.GetType() != DT_POLYTYPE_GROUND {
+		return
+	}
+	var area float32
+	for j := 2; j < int(poly.VertCount); j++ {
+		area += DtTriArea2D(tile.Verts[poly.Verts[0]*3:], tile.Verts[poly.Verts[j-1]*3:], tile.Verts[poly.Verts[j]*3:])
+	}
+	if area <= 0 {
+		return
+	}
+	var sum float32
+	if len(this.cum) != 0 {
+		sum = this.cum[len(this.cum)-1]
+	}
+	this.polys = append(this.polys, ref)
+	this.cum = append(this.cum, sum+area)
+}
+
+/// Returns true if the point is inside the region shape.
+func (this *dtPoissonSampler) inRegion(pos []float32) bool {
+	p := this.params
+	switch p.RegionType {
+	case DT_POISSON_REGION_CIRCLE:
+		dx := pos[0] - p.Center[0]
+		dz := pos[2] - p.Center[2]
+		return DtMathMulf(dx, dx)+DtMathMulf(dz, dz) <= p.Radius*p.Radius
+	case DT_POISSON_REGION_POLYGON:
+		return DtPointInPolygon(pos, p.Verts, p.NVerts)
+	}
+	return true
+}
+
+/// Returns true if no accepted point is closer than the minimum distance.
+func (this *dtPoissonSampler) isFarEnough(pos []float32) bool {
+	c := this.grid.cell(pos)
+	minDistSqr := this.minDist * this.minDist
+	for y := c[1] - 2; y <= c[1]+2; y++ {
+		for x := c[0] - 2; x <= c[0]+2; x++ {
+			for _, i := range this.grid.cells[[2]int32{x, y}] {
+				if DtVdistSqr(pos, this.points[i*3:]) < minDistSqr {
+					return false
+				}
+			}
+		}
+	}
+	return true
+}
+
+/// Validates the candidate and adds it to the result.
+func (this *dtPoissonSampler) accept(ref DtPolyRef, pos []float32) bool {
+	if ref == 0 || !this.inRegion(pos) || !this.isFarEnough(pos) {
+		return false
+	}
+	if this.region != nil && !this.region[ref] {
+		return false
+	}
+	i := this.count
+	DtVcopy(this.points[i*3:], pos)
+	this.refs[i] = ref
+	this.count++
+	c := this.grid.cell(pos)
+	this.grid.cells[c] = append(this.grid.cells[c], i)
+	return true
+}
+
+/// Picks a random point weighted by area from the seed polygons.
+func (this *dtPoissonSampler) seed() bool {
+	if len(this.cum) == 0 {
+		return false
+	}
+	nav := this.query.m_nav
+	u := this.rng.Float32() * this.cum[len(this.cum)-1]
+	i := sort.Search(len(this.cum)-1, func(i int) bool { return this.cum[i] > u })
+	ref := this.polys[i]
+	var tile *DtMeshTile
+	var poly *DtPoly
+	nav.GetTileAndPolyByRefUnsafe(ref, &tile, &poly)
+
+	var verts [3 * DT_VERTS_PER_POLYGON]float32
+	var areas [DT_VERTS_PER_POLYGON]float32
+	for j := 0; j < int(poly.VertCount); j++ {
+		DtVcopy(verts[j*3:], tile.Verts[poly.Verts[j]*3:])
+	}
+	s := this.rng.Float32()
+	t := this.rng.Float32()
+	var pt, closest [3]float32
+	DtRandomPointInConvexPoly(verts[:], int(poly.VertCount), areas[:], s, t, pt[:])
+	nav.closestPointOnPoly(ref, pt[:], closest[:], nil)
+	return this.accept(ref, closest[:])
+}
+
+/// Tries to place a point in the annulus [MinDist, 2*MinDist) around the point.
+func (this *dtPoissonSampler) spawn(i int) bool {
+	center := this.points[i*3:]
+	for k := 0; k < this.params.MaxAttempts; k++ {
+		a := this.rng.Float32() * 2 * math.Pi
+		d := this.minDist * (1 + this.rng.Float32())
+		cand := [3]float32{center[0] + DtMathMulf(DtMathCosf(a), d), center[1], center[2] + DtMathMulf(DtMathSinf(a), d)}
+		var ref DtPolyRef
+		var pt [3]float32
+		if DtStatusFailed(this.query.FindNearestPoly(cand[:], this.params.HalfExtents[:], this.filter, &ref, pt[:])) {
+			continue
+		}
+		if this.accept(ref, pt[:]) {
+			return true
+		}
+	}
+	return false
+}
+
+/// Finds well spaced random points on the navigation mesh.
+///  @param[in]		params		The sampling parameters.
+///  @param[in]		filter		The polygon filter to apply to the query.
+///  @param[in]		rng			The random number generator.
+///  @param[out]	resultRef	The reference ids of the points. [(polyRef) * @p resultCount]
+///  @param[out]	resultPos	The points. [(x, y, z) * @p resultCount]
+///  @param[out]	resultCount	The number of points found.
+///  @param[in]		maxResult	The maximum number of points the result arrays can hold.
+/// @returns The status flags for the query.
+/// @par
+///
+/// The points are distributed using Bridson's Poisson-disk sampling: new
+/// points are tried in the annulus between @p MinDist and twice @p MinDist
+/// around the existing points, snapped on the navigation mesh using
+/// #FindNearestPoly and rejected if they are closer than @p MinDist to any
+/// other point. Once no point can be spawned anymore, new seeds are picked
+/// randomly weighted by polygon area, so disconnected parts of the mesh are
+/// covered too.
+///
+/// Distances are measured in 3D, so points on different floors do not
+/// reject each other.
+///
+/// The region is found with #FindPolysAroundCircle or #FindPolysAroundShape,
+/// so only the polygons connected to @p StartRef are considered.
+///
+/// If the result arrays are too small to hold all the points, they will be
+/// filled to capacity and #DT_BUFFER_TOO_SMALL is returned.
+func (this *DtNavMeshQuery) FindPoissonDiskPoints(params *DtPoissonDiskParams, filter *DtQueryFilter, rng DtRNG,
+	resultRef []DtPolyRef, resultPos []float32, resultCount *int, maxResult int) DtStatus {
+	DtAssert(this.m_nav != nil)
+
+	*resultCount = 0
+	if params == nil || filter == nil || params.MinDist <= 0 || maxResult < 0 ||
+		len(resultRef) < maxResult || len(resultPos) < maxResult*3 {
+		return DT_FAILURE | DT_INVALID_PARAM
+	}
+
+	p := *params
+	if p.MaxAttempts <= 0 {
+		p.MaxAttempts = 30
+	}
+	s := &dtPoissonSampler{
+		query:   this,
+		params:  &p,
+		filter:  filter,
+		rng:     rng,
+		grid:    dtPoissonGrid{cellSize: p.MinDist / float32(math.Sqrt2), cells: make(map[[2]int32][]int)},
+		refs:    resultRef,
+		points:  resultPos,
+		maxPts:  maxResult,
+		minDist: p.MinDist,
+	}
+
+	// Gather the polygons where points can be placed.
+	var status DtStatus = DT_SUCCESS
+	if p.RegionType == DT_POISSON_REGION_NONE {
+		for i := 0; i < int(this.m_nav.GetMaxTiles()); i++ {
+			tile := this.m_nav.GetTile(i)
+			if tile == nil || tile.Header == nil {
+				continue
+			}
+			base := this.m_nav.GetPolyRefBase(tile)
+			for j := 0; j < int(tile.Header.PolyCount); j++ {
+				ref := base | DtPolyRef(j)
+				if filter.PassFilter(ref, tile, &tile.Polys[j]) {
+					s.addSeedPoly(ref, tile, &tile.Polys[j])
+				}
+			}
+		}
+	} else {
+		maxPolys := p.MaxPolys
+		if maxPolys <= 0 {
+			maxPolys = int(this.m_nodePool.GetMaxNodes())
+		}
+		polys := make([]DtPolyRef, maxPolys)
+		var npolys int
+		switch p.RegionType {
+		case DT_POISSON_REGION_CIRCLE:
+			status = this.FindPolysAroundCircle(p.StartRef, p.Center[:], p.Radius, filter, polys, nil, nil, &npolys, maxPolys)
+		case DT_POISSON_REGION_POLYGON:
+			if p.NVerts < 3 || len(p.Verts) < p.NVerts*3 {
+				return DT_FAILURE | DT_INVALID_PARAM
+			}
+			status = this.FindPolysAroundShape(p.StartRef, p.Verts, p.NVerts, filter, polys, nil, nil, &npolys, maxPolys)
+		default:
+			return DT_FAILURE | DT_INVALID_PARAM
+		}
+		if DtStatusFailed(status) {
+			return status
+		}
+		s.region = make(map[DtPolyRef]bool, npolys)
+		for i := 0; i < npolys; i++ {
+			var tile *DtMeshTile
+			var poly *DtPoly
+			this.m_nav.GetTileAndPolyByRefUnsafe(polys[i], &tile, &poly)
+			s.region[polys[i]] = true
+			s.addSeedPoly(polys[i], tile, poly)
+		}
+	}
+
+	// Bridson's algorithm, restarted from a new seed when all points are retired.
+	var active []int
+	for s.count < s.maxPts {
+		if len(active) == 0 {
+			seeded := false
+			for k := 0; k < p.MaxAttempts && !seeded; k++ {
+				seeded = s.seed()
+			}
+			if !seeded {
+				break
+			}
+			active = append(active, s.count-1)
+			continue
+		}
+		ai := int(rng.Float32() * float32(len(active)))
+		if ai >= len(active) {
+			ai = len(active) - 1
+		}
+		if s.spawn(active[ai]) {
+			active = append(active, s.count-1)
+		} else {
+			active[ai] = active[len(active)-1]
+			active = active[:len(active)-1]
+		}
+	}
+
+	*resultCount = s.count
+	if len(active) != 0 {
+		// Stopped with points left to spawn from.
+		status |= DT_BUFFER_TOO_SMALL
+	}
+	return status
+}
@@ -0,0 +1,239 @@
+package detour
+
+import (
+	"encoding/binary"
+	"sort"
+)
+
+/// A magic number used to detect the compatibility of stored polygon user data.
+const DT_POLYUSERDATA_MAGIC int32 = 'D'<<24 | 'P'<<16 | 'U'<<8 | 'D'
+
+/// A version number used to detect the compatibility of stored polygon user data.
+const DT_POLYUSERDATA_VERSION int32 = 1
+
+// Polygons are keyed by the location of their tile and their index in it, so
+// the keys stay the same when a tile is removed and added again.
+type dtPolyUserDataKey struct {
+	x, y, layer int32
+	poly        uint32
+}
+
+/// A side table of gameplay data attached to the polygons of a navigation mesh.
+///
+/// The data of a polygon is an arbitrary byte string. (E.g. A zone id, a sound
+/// material, an owning faction.) It is keyed by the tile location and the index
+/// of the polygon in its tile rather than by #DtPolyRef, so it survives the tile
+/// being reloaded with a new salt. The data follows the polygon index, if a
+/// tile is rebuilt with a different polygon layout its data should be cleared
+/// with #ClearTile.
+///
+/// Custom filters can read the data of the polygons they are asked about, see
+/// #DtQueryFilterCustom.
+///
+/// The table can be stored with #Store, e.g. after the tiles of a navigation
+/// mesh set, and restored with #Restore.
+/// @ingroup detour
+type DtPolyUserData struct {
+	m_nav  *DtNavMesh                   ///< The navigation mesh the polygons belong to.
+	m_data map[dtPolyUserDataKey][]byte ///< The data of each polygon.
+}
+
+/// Allocates an empty user data table for the navigation mesh.
+///  @param[in]	nav		The navigation mesh the polygons belong to.
+/// @return The user data table, or null if @p nav is null.
+func DtAllocPolyUserData(nav *DtNavMesh) *DtPolyUserData {
+	if nav == nil {
+		return nil
+	}
+	return &DtPolyUserData{
+		m_nav:  nav,
+		m_data: make(map[dtPolyUserDataKey][]byte),
+	}
+}
+
+/// Frees the data of the table.
+///  @param[in]	userData	A user data table allocated using #DtAllocPolyUserData
+func DtFreePolyUserData(userData *DtPolyUserData) {
+	if userData == nil {
+		return
+	}
+	userData.m_data = nil
+}
+
+func (this *DtPolyUserData) keyOf(ref DtPolyRef, key *dtPolyUserDataKey) bool {
+	var tile *DtMeshTile
+	var poly *DtPoly
+	if DtStatusFailed(this.m_nav.GetTileAndPolyByRef(ref, &tile, &poly)) {
+		return false
+	}
+	key.x = tile.Header.X
+	key.y = tile.Header.Y
+	key.layer = tile.Header.Layer
+	key.poly = this.m_nav.DecodePolyIdPoly(ref)
+	return true
+}
+
+/// Returns the data of the polygon, or null if it has none or @p ref is not valid.
+///  @param[in]	ref		The reference id of the polygon.
+/// @note The returned slice is owned by the table and must not be modified.
+func (this *DtPolyUserData) Get(ref DtPolyRef) []byte {
+	var key dtPolyUserDataKey
+	if !this.keyOf(ref, &key) {
+		return nil
+	}
+	return this.m_data[key]
+}
+
+/// Sets the data of the polygon.
+///  @param[in]	ref		The reference id of the polygon.
+///  @param[in]	data	The new data, it is copied. Empty data removes the data of the polygon.
+/// @return The status flags for the operation.
+func (this *DtPolyUserData) Set(ref DtPolyRef, data []byte) DtStatus {
+	var key dtPolyUserDataKey
+	if !this.keyOf(ref, &key) {
+		return DT_FAILURE | DT_INVALID_PARAM
+	}
+	if len(data) == 0 {
+		delete(this.m_data, key)
+	} else {
+		this.m_data[key] = append([]byte(nil), data...)
+	}
+	return DT_SUCCESS
+}
+
+/// Removes the data of the polygon.
+///  @param[in]	ref		The reference id of the polygon.
+/// @return The status flags for the operation.
+func (this *DtPolyUserData) Remove(ref DtPolyRef) DtStatus {
+	return this.Set(ref, nil)
+}
+
+/// Removes the data of the polygons of the tile at the location, whether the tile is loaded or not.
+///  @param[in]	x		The tile's x-location. (x, y, layer)
+///  @param[in]	y		The tile's y-location. (x, y, layer)
+///  @param[in]	layer	The tile's layer. (x, y, layer)
+func (this *DtPolyUserData) ClearTile(x, y, layer int32) {
+	for key := range this.m_data {
+		if key.x == x && key.y == y && key.layer == layer {
+			delete(this.m_data, key)
+		}
+	}
+}
+
+/// Removes the data of all the polygons.
+func (this *DtPolyUserData) Clear() {
+	this.m_data = make(map[dtPolyUserDataKey][]byte)
+}
+
+/// The number of polygons with data.
+func (this *DtPolyUserData) GetEntryCount() int {
+	return len(this.m_data)
+}
+
+/// Gets the size of the buffer required by #Store to store the table.
+/// @return The size of the buffer required to store the table.
+func (this *DtPolyUserData) GetDataSize() int {
+	size := 12
+	for _, data := range this.m_data {
+		size += 20 + len(data)
+	}
+	return size
+}
+
+/// Stores the table in a buffer.
+///  @param[out]	data			The buffer to store the table in.
+///  @param[in]		maxDataSize		The size of the data buffer. [Limit: >= #GetDataSize]
+/// @return The status flags for the operation.
+/// @par
+///
+/// The data is little-endian and the polygons are stored in tile and index
+/// order, so the same table is always stored the same way.
+func (this *DtPolyUserData) Store(data []byte, maxDataSize int) DtStatus {
+	if maxDataSize < this.GetDataSize() || len(data) < maxDataSize {
+		return DT_FAILURE | DT_BUFFER_TOO_SMALL
+	}
+
+	keys := make([]dtPolyUserDataKey, 0, len(this.m_data))
+	for key := range this.m_data {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		a, b := &keys[i], &keys[j]
+		if a.x != b.x {
+			return a.x < b.x
+		}
+		if a.y != b.y {
+			return a.y < b.y
+		}
+		if a.layer != b.layer {
+			return a.layer < b.layer
+		}
+		return a.poly < b.poly
+	})
+
+	le := binary.LittleEndian
+	le.PutUint32(data[0:], uint32(DT_POLYUSERDATA_MAGIC))
+	le.PutUint32(data[4:], uint32(DT_POLYUSERDATA_VERSION))
+	le.PutUint32(data[8:], uint32(len(keys)))
+	d := 12
+	for _, key := range keys {
+		value := this.m_data[key]
+		le.PutUint32(data[d:], uint32(key.x))
+		le.PutUint32(data[d+4:], uint32(key.y))
+		le.PutUint32(data[d+8:], uint32(key.layer))
+		le.PutUint32(data[d+12:], key.poly)
+		le.PutUint32(data[d+16:], uint32(len(value)))
+		d += 20
+		d += copy(data[d:], value)
+	}
+	return DT_SUCCESS
+}
+
+/// Replaces the table with one stored by #Store.
+///  @param[in]	data		The stored table.
+///  @param[in]	dataSize	The size of the stored table within the data buffer.
+/// @return The status flags for the operation.
+/// @par
+///
+/// The table does not need to be restored on the navigation mesh it was stored
+/// from, only the tile locations and polygon indices must match. On failure the
+/// table is left unchanged.
+func (this *DtPolyUserData) Restore(data []byte, dataSize int) DtStatus {
+	if dataSize < 12 || len(data) < dataSize {
+		return DT_FAILURE | DT_INVALID_PARAM
+	}
+	data = data[:dataSize]
+
+	le := binary.LittleEndian
+	if int32(le.Uint32(data[0:])) != DT_POLYUSERDATA_MAGIC {
+		return DT_FAILURE | DT_WRONG_MAGIC
+	}
+	if int32(le.Uint32(data[4:])) != DT_POLYUSERDATA_VERSION {
+		return DT_FAILURE | DT_WRONG_VERSION
+	}
+	count := int(le.Uint32(data[8:]))
+	if count > (dataSize-12)/21 {
+		return DT_FAILURE | DT_INVALID_PARAM
+	}
+	entries := make(map[dtPolyUserDataKey][]byte, count)
+	d := 12
+	for i := 0; i < count; i++ {
+		if len(data)-d < 20 {
+			return DT_FAILURE | DT_INVALID_PARAM
+		}
+		var key dtPolyUserDataKey
+		key.x = int32(le.Uint32(data[d:]))
+		key.y = int32(le.Uint32(data[d+4:]))
+		key.layer = int32(le.Uint32(data[d+8:]))
+		key.poly = le.Uint32(data[d+12:])
+		size := int(le.Uint32(data[d+16:]))
+		d += 20
+		if size <= 0 || len(data)-d < size {
+			return DT_FAILURE | DT_INVALID_PARAM
+		}
+		entries[key] = append([]byte(nil), data[d:d+size]...)
+		d += size
+	}
+	this.m_data = entries
+	return DT_SUCCESS
+}
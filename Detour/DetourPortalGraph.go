@@ -0,0 +1,645 @@
+package detour
+
+import "math"
+
+const (
+	dtPortalExit  uint8 = 1 << 0 ///< The polygon inside the tile links to the neighbour polygon.
+	dtPortalEnter uint8 = 1 << 1 ///< The neighbour polygon links to the polygon inside the tile.
+)
+
+/// The number of tile crossings refined by a single #DtNavMeshQuery.FindPath call.
+const dtPortalGraphRefineCrossings int = 16
+
+type dtTilePortal struct {
+	poly  DtPolyRef  ///< The polygon inside the tile.
+	other DtPolyRef  ///< The polygon in the neighbour tile.
+	pos   [3]float32 ///< The middle of the portal.
+	flags uint8      ///< The directions the portal can be crossed in.
+}
+
+type dtTilePortals struct {
+	salt    uint32         ///< The salt of the tile the portals were computed for.
+	portals []dtTilePortal ///< The portals on the borders of the tile.
+	costs   []float32      ///< Cost between the portals through the tile. (FLT_MAX if not connected.) [Size: len(portals)^2]
+	areas   []float32      ///< The area cost of each polygon the costs were computed with, or -1 if it did not pass the filter.
+	dirty   bool           ///< The passability or the area cost of a polygon changed, the costs are rebuilt on next use.
+}
+
+type dtPortalState struct {
+	tile   uint32  ///< The tile index.
+	portal int32   ///< The portal index within the tile.
+	cost   float32 ///< The cost from the start.
+	total  float32 ///< The cost plus the heuristic.
+	parent int32   ///< The previous state, or -1.
+	closed bool
+}
+
+/// A crossing between two tiles of a hierarchical path.
+type dtPortalCrossing struct {
+	to  DtPolyRef  ///< The polygon entered in the next tile.
+	pos [3]float32 ///< The middle of the portal.
+}
+
+/// An abstraction of a navigation mesh for hierarchical path finding.
+///
+/// Every tile stores its portals, the pairs of polygons linked across its
+/// borders, and the cost between each pair of portals through the tile.
+/// #DtNavMeshQuery.FindPathHierarchical searches this graph to find the
+/// tiles to cross, and then runs #DtNavMeshQuery.FindPath restricted to
+/// these tiles. The number of nodes used is related to the size of the tile
+/// corridor instead of the size of the searched area, so paths across the
+/// whole mesh can be found with a small node pool.
+///
+/// The portals of a tile and its neighbours are rebuilt when the tile is
+/// added or removed. When the flags or area of a polygon change so that it
+/// passes the filter of the graph differently or costs differently, the
+/// tile is rebuilt the next time the graph uses it, so restoring a tile
+/// state rebuilds it once. The costs are computed with the filter of the
+/// graph; call #Rebuild after changing it.
+/// @ingroup detour
+type DtPortalGraph struct {
+	m_nav      *DtNavMesh
+	m_filter   DtQueryFilter
+	m_query    DtNavMeshQuery  ///< Only used for the portal helpers, no node pool is allocated.
+	m_tiles    []dtTilePortals ///< Portals per tile index.
+	m_removing *DtMeshTile     ///< The tile being removed, which must not be linked to anymore.
+
+	// Scratch buffers of the tile searches.
+	m_cost   []float32
+	m_pos    []float32
+	m_parent []int32
+	m_open   dtCostQueue
+	m_states []dtPortalState
+	m_lookup map[uint64]int32
+}
+
+/// Allocates the portal graph of the navigation mesh, builds all its tiles
+/// and starts listening to its changes.
+///  @param[in]	nav		The navigation mesh.
+///  @param[in]	filter	The filter used to compute the costs through the tiles.
+/// @return The portal graph.
+func DtAllocPortalGraph(nav *DtNavMesh, filter *DtQueryFilter) *DtPortalGraph {
+	graph := &DtPortalGraph{
+		m_nav:    nav,
+		m_filter: *filter,
+		m_lookup: make(map[uint64]int32),
+	}
+	graph.m_query.m_nav = nav
+	graph.Rebuild()
+	nav.AddListener(graph)
+	return graph
+}
+
+/// Stops listening to the navigation mesh and frees the graph.
+///  @param[in]	graph	A portal graph allocated using #DtAllocPortalGraph
+func DtFreePortalGraph(graph *DtPortalGraph) {
+	if graph == nil {
+		return
+	}
+	graph.m_nav.RemoveListener(graph)
+	graph.m_tiles = nil
+	graph.m_states = nil
+	graph.m_lookup = nil
+}
+
+/// Gets the filter used to compute the costs.
+func (this *DtPortalGraph) GetFilter() *DtQueryFilter { return &this.m_filter }
+
+/// Rebuilds the portals of all the tiles.
+func (this *DtPortalGraph) Rebuild() {
+	this.m_tiles = make([]dtTilePortals, this.m_nav.GetMaxTiles())
+	for i := range this.m_tiles {
+		if tile := this.m_nav.GetTile(i); tile.Header != nil {
+			this.buildTile(tile)
+		}
+	}
+}
+
+/// Returns the number of portals of the tile.
+///  @param[in]	tile	The tile.
+func (this *DtPortalGraph) GetPortalCount(tile *DtMeshTile) int {
+	if t := this.portals(this.tileIndex(tile), tile.Salt); t != nil {
+		return len(t.portals)
+	}
+	return 0
+}
+
+func (this *DtPortalGraph) tileIndex(tile *DtMeshTile) uint32 {
+	return this.m_nav.DecodePolyIdTile(DtPolyRef(this.m_nav.GetTileRef(tile)))
+}
+
+func (this *DtPortalGraph) portals(it, salt uint32) *dtTilePortals {
+	if it >= uint32(len(this.m_tiles)) {
+		return nil
+	}
+	t := &this.m_tiles[it]
+	if t.portals == nil || t.salt != salt {
+		return nil
+	}
+	if t.dirty {
+		this.buildTile(this.m_nav.GetTile(int(it)))
+	}
+	return t
+}
+
+/// Returns the area cost of the polygon, or -1 if it does not pass the filter of the graph.
+func (this *DtPortalGraph) areaCost(ref DtPolyRef, tile *DtMeshTile, poly *DtPoly) float32 {
+	if !this.m_filter.PassFilter(ref, tile, poly) {
+		return -1
+	}
+	return this.m_filter.GetAreaCost(int(poly.GetArea()))
+}
+
+/// Adds the direction to the portal between the polygons, adding the portal if needed.
+func (this *DtPortalGraph) addPortal(t *dtTilePortals, poly, other DtPolyRef, flags uint8,
+	from DtPolyRef, fromPoly *DtPoly, fromTile *DtMeshTile, to DtPolyRef, toPoly *DtPoly, toTile *DtMeshTile) {
+	for i := range t.portals {
+		if t.portals[i].poly == poly && t.portals[i].other == other {
+			t.portals[i].flags |= flags
+			return
+		}
+	}
+	p := dtTilePortal{poly: poly, other: other, flags: flags}
+	if DtStatusFailed(this.m_query.getEdgeMidPoint2(from, fromPoly, fromTile, to, toPoly, toTile, p.pos[:])) {
+		return
+	}
+	t.portals = append(t.portals, p)
+}
+
+/// Builds the portals of the tile and the costs between them.
+func (this *DtPortalGraph) buildTile(tile *DtMeshTile) {
+	it := this.tileIndex(tile)
+	t := &this.m_tiles[it]
+	base := this.m_nav.GetPolyRefBase(tile)
+	t.salt = tile.Salt
+	t.dirty = false
+	t.portals = t.portals[:0]
+	if t.portals == nil {
+		t.portals = make([]dtTilePortal, 0, 8)
+	}
+	t.areas = t.areas[:0]
+	for i := 0; i < int(tile.Header.PolyCount); i++ {
+		t.areas = append(t.areas, this.areaCost(base|DtPolyRef(i), tile, &tile.Polys[i]))
+	}
+
+	// Links leaving the tile.
+	for i := 0; i < int(tile.Header.PolyCount); i++ {
+		poly := &tile.Polys[i]
+		for k := poly.FirstLink; k != DT_NULL_LINK; k = tile.Links[k].Next {
+			link := &tile.Links[k]
+			if link.Ref == 0 || this.m_nav.DecodePolyIdTile(link.Ref) == it {
+				continue
+			}
+			var neiTile *DtMeshTile
+			var neiPoly *DtPoly
+			this.m_nav.GetTileAndPolyByRefUnsafe(link.Ref, &neiTile, &neiPoly)
+			if neiTile == this.m_removing {
+				continue
+			}
+			this.addPortal(t, base|DtPolyRef(i), link.Ref, dtPortalExit,
+				base|DtPolyRef(i), poly, tile, link.Ref, neiPoly, neiTile)
+		}
+	}
+
+	// Links entering the tile. One-way off-mesh connections have no link back,
+	// so the neighbour tiles are searched for them.
+	const MAX_NEIS int = 32
+	var neis [MAX_NEIS]*DtMeshTile
+	for side := -1; side < 8; side++ {
+		var nneis int
+		if side == -1 {
+			nneis = this.m_nav.GetTilesAt(tile.Header.X, tile.Header.Y, neis[:], MAX_NEIS)
+		} else {
+			nneis = this.m_nav.GetNeighbourTilesAt(tile.Header.X, tile.Header.Y, side, neis[:], MAX_NEIS)
+		}
+		for n := 0; n < nneis; n++ {
+			nei := neis[n]
+			if nei == tile || nei == this.m_removing {
+				continue
+			}
+			neiBase := this.m_nav.GetPolyRefBase(nei)
+			for i := 0; i < int(nei.Header.PolyCount); i++ {
+				poly := &nei.Polys[i]
+				for k := poly.FirstLink; k != DT_NULL_LINK; k = nei.Links[k].Next {
+					link := &nei.Links[k]
+					if link.Ref == 0 || this.m_nav.DecodePolyIdTile(link.Ref) != it {
+						continue
+					}
+					var ownTile *DtMeshTile
+					var ownPoly *DtPoly
+					this.m_nav.GetTileAndPolyByRefUnsafe(link.Ref, &ownTile, &ownPoly)
+					this.addPortal(t, link.Ref, neiBase|DtPolyRef(i), dtPortalEnter,
+						neiBase|DtPolyRef(i), poly, nei, link.Ref, ownPoly, ownTile)
+				}
+			}
+		}
+	}
+
+	// Costs between the portals.
+	np := len(t.portals)
+	if cap(t.costs) >= np*np {
+		t.costs = t.costs[:np*np]
+	} else {
+		t.costs = make([]float32, np*np)
+	}
+	for i := 0; i < np; i++ {
+		this.searchTile(tile, t.portals[i].poly, t.portals[i].pos[:])
+		for j := 0; j < np; j++ {
+			t.costs[i*np+j] = this.costTo(tile, t.portals[j].poly, t.portals[j].pos[:])
+		}
+	}
+}
+
+/// Runs a Dijkstra search restricted to the tile, from the position within the polygon.
+func (this *DtPortalGraph) searchTile(tile *DtMeshTile, startRef DtPolyRef, startPos []float32) {
+	it := this.tileIndex(tile)
+	n := int(tile.Header.PolyCount)
+	if cap(this.m_cost) < n {
+		this.m_cost = make([]float32, n)
+		this.m_pos = make([]float32, n*3)
+		this.m_parent = make([]int32, n)
+	}
+	this.m_cost = this.m_cost[:n]
+	for i := range this.m_cost {
+		this.m_cost[i] = math.MaxFloat32
+		this.m_parent[i] = -1
+	}
+	start := this.m_nav.DecodePolyIdPoly(startRef)
+	if !this.m_filter.PassFilter(startRef, tile, &tile.Polys[start]) {
+		return
+	}
+	base := this.m_nav.GetPolyRefBase(tile)
+	this.m_cost[start] = 0
+	DtVcopy(this.m_pos[start*3:], startPos)
+	this.m_open = this.m_open[:0]
+	this.m_open.push(start, 0)
+
+	for len(this.m_open) != 0 {
+		e := this.m_open.pop()
+		cur := e.id
+		if e.cost > this.m_cost[cur] {
+			continue
+		}
+		curRef := base | DtPolyRef(cur)
+		curPoly := &tile.Polys[cur]
+		var parentRef DtPolyRef
+		var parentPoly *DtPoly
+		if this.m_parent[cur] >= 0 {
+			parentRef = base | DtPolyRef(this.m_parent[cur])
+			parentPoly = &tile.Polys[this.m_parent[cur]]
+		}
+		for k := curPoly.FirstLink; k != DT_NULL_LINK; k = tile.Links[k].Next {
+			neiRef := tile.Links[k].Ref
+			if neiRef == 0 || neiRef == parentRef || this.m_nav.DecodePolyIdTile(neiRef) != it {
+				continue
+			}
+			nei := this.m_nav.DecodePolyIdPoly(neiRef)
+			neiPoly := &tile.Polys[nei]
+			if !this.m_filter.PassFilter(neiRef, tile, neiPoly) {
+				continue
+			}
+			var pos [3]float32
+			if this.m_cost[nei] == math.MaxFloat32 {
+				if DtStatusFailed(this.m_query.getEdgeMidPoint2(curRef, curPoly, tile, neiRef, neiPoly, tile, pos[:])) {
+					continue
+				}
+			} else {
+				DtVcopy(pos[:], this.m_pos[nei*3:])
+			}
+			cost := this.m_cost[cur] + this.m_filter.GetCost(this.m_pos[cur*3:], pos[:],
+				parentRef, tile, parentPoly,
+				curRef, tile, curPoly,
+				neiRef, tile, neiPoly)
+			if cost >= this.m_cost[nei] {
+				continue
+			}
+			this.m_cost[nei] = cost
+			this.m_parent[nei] = int32(cur)
+			DtVcopy(this.m_pos[nei*3:], pos[:])
+			this.m_open.push(nei, cost)
+		}
+	}
+}
+
+/// Returns the cost to the position within the polygon after #searchTile.
+func (this *DtPortalGraph) costTo(tile *DtMeshTile, ref DtPolyRef, pos []float32) float32 {
+	ip := this.m_nav.DecodePolyIdPoly(ref)
+	if this.m_cost[ip] == math.MaxFloat32 {
+		return math.MaxFloat32
+	}
+	var parentRef DtPolyRef
+	var parentPoly *DtPoly
+	if this.m_parent[ip] >= 0 {
+		parentRef = this.m_nav.GetPolyRefBase(tile) | DtPolyRef(this.m_parent[ip])
+		parentPoly = &tile.Polys[this.m_parent[ip]]
+	}
+	return this.m_cost[ip] + this.m_filter.GetCost(this.m_pos[ip*3:], pos,
+		parentRef, tile, parentPoly,
+		ref, tile, &tile.Polys[ip],
+		0, nil, nil)
+}
+
+/// Returns the state of the portal, adding it if needed.
+func (this *DtPortalGraph) state(it uint32, portal int32) (int32, *dtPortalState) {
+	key := uint64(it)<<32 | uint64(uint32(portal))
+	if idx, ok := this.m_lookup[key]; ok {
+		return idx, &this.m_states[idx]
+	}
+	idx := int32(len(this.m_states))
+	this.m_states = append(this.m_states, dtPortalState{tile: it, portal: portal, cost: math.MaxFloat32, parent: -1})
+	this.m_lookup[key] = idx
+	return idx, &this.m_states[idx]
+}
+
+/// Finds the cheapest sequence of tile crossings from the start to the end.
+/// Returns false if the end cannot be reached through the portals.
+func (this *DtPortalGraph) findRoute(startRef, endRef DtPolyRef, startPos, endPos []float32) ([]dtPortalCrossing, bool) {
+	var startTile, endTile *DtMeshTile
+	var startPoly, endPoly *DtPoly
+	this.m_nav.GetTileAndPolyByRefUnsafe(startRef, &startTile, &startPoly)
+	this.m_nav.GetTileAndPolyByRefUnsafe(endRef, &endTile, &endPoly)
+	startIt, endIt := this.tileIndex(startTile), this.tileIndex(endTile)
+	st := this.portals(startIt, startTile.Salt)
+	et := this.portals(endIt, endTile.Salt)
+	if st == nil || et == nil {
+		return nil, false
+	}
+
+	for k := range this.m_lookup {
+		delete(this.m_lookup, k)
+	}
+	this.m_states = this.m_states[:0]
+	this.m_open = this.m_open[:0]
+
+	// The best route found so far, -1 for the route within the start tile.
+	bestCost := float32(math.MaxFloat32)
+	bestState := int32(-1)
+
+	// Cost from the portals of the end tile to the end.
+	endCosts := make([]float32, len(et.portals))
+	for i := range et.portals {
+		this.searchTile(endTile, et.portals[i].poly, et.portals[i].pos[:])
+		endCosts[i] = this.costTo(endTile, endRef, endPos)
+	}
+
+	this.searchTile(startTile, startRef, startPos)
+	if startIt == endIt {
+		bestCost = this.costTo(startTile, endRef, endPos)
+	}
+	for i := range st.portals {
+		if (st.portals[i].flags & dtPortalExit) == 0 {
+			continue
+		}
+		cost := this.costTo(startTile, st.portals[i].poly, st.portals[i].pos[:])
+		if cost == math.MaxFloat32 {
+			continue
+		}
+		idx, s := this.state(startIt, int32(i))
+		s.cost = cost
+		s.total = cost + DtMathMulf(DtVdist(st.portals[i].pos[:], endPos), H_SCALE)
+		this.m_open.push(uint32(idx), s.total)
+	}
+
+	for len(this.m_open) != 0 {
+		e := this.m_open.pop()
+		idx := int32(e.id)
+		cur := &this.m_states[idx]
+		if cur.closed || e.cost > cur.total {
+			continue
+		}
+		if cur.total >= bestCost {
+			break
+		}
+		cur.closed = true
+		it, ip, cost := cur.tile, cur.portal, cur.cost
+		t := &this.m_tiles[it]
+		p := &t.portals[ip]
+
+		visit := func(nit uint32, nip int32, ncost float32, pos []float32) {
+			nidx, n := this.state(nit, nip)
+			if n.closed || ncost >= n.cost {
+				return
+			}
+			n.cost = ncost
+			n.total = ncost + DtMathMulf(DtVdist(pos, endPos), H_SCALE)
+			n.parent = idx
+			this.m_open.push(uint32(nidx), n.total)
+		}
+
+		// Arrived in the end tile.
+		if it == endIt && (p.flags&dtPortalEnter) != 0 && endCosts[ip] != math.MaxFloat32 {
+			if c := cost + endCosts[ip]; c < bestCost {
+				bestCost = c
+				bestState = idx
+			}
+		}
+
+		// Move to the other portals of the tile.
+		if (p.flags & dtPortalEnter) != 0 {
+			np := len(t.portals)
+			for j := 0; j < np; j++ {
+				c := t.costs[int(ip)*np+j]
+				if j == int(ip) || (t.portals[j].flags&dtPortalExit) == 0 || c == math.MaxFloat32 {
+					continue
+				}
+				visit(it, int32(j), cost+c, t.portals[j].pos[:])
+			}
+		}
+
+		// Cross to the neighbour tile.
+		if (p.flags & dtPortalExit) != 0 {
+			var neiTile *DtMeshTile
+			var neiPoly *DtPoly
+			if DtStatusFailed(this.m_nav.GetTileAndPolyByRef(p.other, &neiTile, &neiPoly)) ||
+				!this.m_filter.PassFilter(p.other, neiTile, neiPoly) {
+				continue
+			}
+			nit := this.tileIndex(neiTile)
+			if nt := this.portals(nit, neiTile.Salt); nt != nil {
+				for j := range nt.portals {
+					if nt.portals[j].poly == p.other && nt.portals[j].other == p.poly {
+						visit(nit, int32(j), cost, nt.portals[j].pos[:])
+						break
+					}
+				}
+			}
+		}
+	}
+
+	if bestCost == math.MaxFloat32 {
+		return nil, false
+	}
+
+	// The crossings are the steps between two tiles.
+	var crossings []dtPortalCrossing
+	for idx := bestState; idx != -1; idx = this.m_states[idx].parent {
+		s := &this.m_states[idx]
+		if parent := s.parent; parent != -1 && this.m_states[parent].tile != s.tile {
+			p := &this.m_tiles[s.tile].portals[s.portal]
+			crossings = append(crossings, dtPortalCrossing{to: p.poly, pos: p.pos})
+		}
+	}
+	for i, j := 0, len(crossings)-1; i < j; i, j = i+1, j-1 {
+		crossings[i], crossings[j] = crossings[j], crossings[i]
+	}
+	return crossings, true
+}
+
+func (this *DtPortalGraph) rebuildNeighbours(tile *DtMeshTile) {
+	const MAX_NEIS int = 32
+	var neis [MAX_NEIS]*DtMeshTile
+	for side := -1; side < 8; side++ {
+		var nneis int
+		if side == -1 {
+			nneis = this.m_nav.GetTilesAt(tile.Header.X, tile.Header.Y, neis[:], MAX_NEIS)
+		} else {
+			nneis = this.m_nav.GetNeighbourTilesAt(tile.Header.X, tile.Header.Y, side, neis[:], MAX_NEIS)
+		}
+		for n := 0; n < nneis; n++ {
+			if neis[n] != tile {
+				this.buildTile(neis[n])
+			}
+		}
+	}
+}
+
+func (this *DtPortalGraph) OnTileAdded(mesh *DtNavMesh, tile *DtMeshTile) {
+	this.buildTile(tile)
+	this.rebuildNeighbours(tile)
+}
+
+func (this *DtPortalGraph) OnTileRemoved(mesh *DtNavMesh, tile *DtMeshTile) {
+	t := &this.m_tiles[this.tileIndex(tile)]
+	t.portals = nil
+	t.costs = nil
+	t.areas = nil
+	this.m_removing = tile
+	this.rebuildNeighbours(tile)
+	this.m_removing = nil
+}
+
+func (this *DtPortalGraph) OnPolyChanged(mesh *DtNavMesh, ref DtPolyRef) {
+	var tile *DtMeshTile
+	var poly *DtPoly
+	if DtStatusFailed(mesh.GetTileAndPolyByRef(ref, &tile, &poly)) {
+		return
+	}
+	t := &this.m_tiles[this.tileIndex(tile)]
+	if t.portals == nil || t.salt != tile.Salt {
+		return
+	}
+	ip := mesh.DecodePolyIdPoly(ref)
+	if int(ip) < len(t.areas) && t.areas[ip] != this.areaCost(ref, tile, poly) {
+		t.dirty = true
+	}
+}
+
+/// Sets the portal graph used by #FindPathHierarchical.
+///  @param[in]	graph	The portal graph of the query's navigation mesh, or null.
+func (this *DtNavMeshQuery) SetPortalGraph(graph *DtPortalGraph) {
+	this.m_portalGraph = graph
+}
+
+/// Finds a path from the start polygon to the end polygon using the portal graph.
+///  @param[in]		startRef	The reference id of the start polygon.
+///  @param[in]		endRef		The reference id of the end polygon.
+///  @param[in]		startPos	A position within the start polygon. [(x, y, z)]
+///  @param[in]		endPos		A position within the end polygon. [(x, y, z)]
+///  @param[in]		filter		The polygon filter to apply to the query.
+///  @param[out]	path		An ordered list of polygon references representing the path. (Start to end.)
+///  							[(polyRef) * @p pathCount]
+///  @param[out]	pathCount	The number of polygons returned in the @p path array.
+///  @param[in]		maxPath		The maximum number of polygons the @p path array can hold. [Limit: >= 1]
+/// @returns The status flags for the query.
+/// @par
+///
+/// The tiles to cross are found by searching the portal graph (See: #SetPortalGraph).
+/// The path is then refined with #FindPath restricted to these tiles, a few
+/// tiles at a time, so the node pool only needs to hold the polygons of the
+/// tiles refined together.
+///
+/// The path may be slightly longer than the one found by #FindPath, since the
+/// tiles are crossed at the middle of the portals by the graph search.
+/// The filter should match the filter of the graph.
+///
+/// If the end cannot be reached through the graph, or without a portal
+/// graph, this is the same as #FindPath.
+func (this *DtNavMeshQuery) FindPathHierarchical(startRef, endRef DtPolyRef,
+	startPos, endPos []float32,
+	filter *DtQueryFilter,
+	path []DtPolyRef, pathCount *int, maxPath int) DtStatus {
+	DtAssert(this.m_nav != nil)
+
+	if pathCount != nil {
+		*pathCount = 0
+	}
+	// Validate input
+	if !this.m_nav.IsValidPolyRef(startRef) || !this.m_nav.IsValidPolyRef(endRef) ||
+		startPos == nil || endPos == nil || filter == nil || maxPath <= 0 || path == nil || pathCount == nil {
+		return DT_FAILURE | DT_INVALID_PARAM
+	}
+	graph := this.m_portalGraph
+	if graph == nil {
+		return this.FindPath(startRef, endRef, startPos, endPos, filter, path, pathCount, maxPath)
+	}
+	crossings, ok := graph.findRoute(startRef, endRef, startPos, endPos)
+	if !ok {
+		return this.FindPath(startRef, endRef, startPos, endPos, filter, path, pathCount, maxPath)
+	}
+
+	defer func() { this.m_corridor = nil }()
+	this.m_corridor = make(map[*DtMeshTile]bool)
+
+	var tile *DtMeshTile
+	var poly *DtPoly
+	curRef := startRef
+	var curPos [3]float32
+	DtVcopy(curPos[:], startPos)
+	n := 0
+	var status DtStatus
+	for k := 0; ; {
+		// Refine up to the crossing at the end of the window, or to the end.
+		e := k + dtPortalGraphRefineCrossings
+		if e > len(crossings) {
+			e = len(crossings)
+		}
+		targetRef := endRef
+		targetPos := endPos
+		if e < len(crossings) {
+			targetRef = crossings[e-1].to
+			targetPos = crossings[e-1].pos[:]
+		}
+		for key := range this.m_corridor {
+			delete(this.m_corridor, key)
+		}
+		this.m_nav.GetTileAndPolyByRefUnsafe(curRef, &tile, &poly)
+		this.m_corridor[tile] = true
+		for i := k; i < e; i++ {
+			this.m_nav.GetTileAndPolyByRefUnsafe(crossings[i].to, &tile, &poly)
+			this.m_corridor[tile] = true
+		}
+
+		// The window starts with the last polygon of the previous one.
+		off := n
+		if n > 0 {
+			off = n - 1
+		}
+		var count int
+		status = this.FindPath(curRef, targetRef, curPos[:], targetPos, filter, path[off:], &count, maxPath-off)
+		if DtStatusFailed(status) {
+			return status
+		}
+		n = off + count
+		if path[n-1] != targetRef || DtStatusDetail(status, DT_BUFFER_TOO_SMALL) || e == len(crossings) {
+			break
+		}
+		curRef = targetRef
+		DtVcopy(curPos[:], targetPos)
+		k = e
+	}
+	*pathCount = n
+	if path[n-1] != endRef {
+		status |= DT_PARTIAL_RESULT
+	}
+	return status
+}
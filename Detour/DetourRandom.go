@@ -0,0 +1,117 @@
+package detour
+
+/// A source of random numbers for the sampling queries.
+///
+/// #DtPCG32 is a seeded implementation giving the same numbers on every
+/// platform, *rand.Rand of the standard library implements it too.
+/// @ingroup detour
+type DtRNG interface {
+	/// Returns a random number [0..1).
+	Float32() float32
+}
+
+const dtPCGMultiplier uint64 = 6364136223846793005
+
+/// A seeded PCG random number generator. (PCG-XSH-RR, 64 bit state, 32 bit output.)
+///
+/// Only integer arithmetic is used, and floats are built from the top 24 bits
+/// of the output, so a seed produces the same sequence on every platform.
+/// It is the same generator as pcg32 of the PCG reference implementation.
+/// @ingroup detour
+type DtPCG32 struct {
+	m_state uint64 ///< The state of the generator.
+	m_inc   uint64 ///< The stream of the generator. (Always odd.)
+}
+
+/// Allocates a generator.
+///  @param[in]	seed	The initial state.
+///  @param[in]	seq		The stream. Generators with different streams produce different sequences for the same seed.
+/// @return The generator.
+func DtAllocPCG32(seed, seq uint64) *DtPCG32 {
+	rng := &DtPCG32{}
+	rng.Seed(seed, seq)
+	return rng
+}
+
+/// Restarts the generator.
+///  @param[in]	seed	The initial state.
+///  @param[in]	seq		The stream.
+func (this *DtPCG32) Seed(seed, seq uint64) {
+	this.m_state = 0
+	this.m_inc = (seq << 1) | 1
+	this.Uint32()
+	this.m_state += seed
+	this.Uint32()
+}
+
+/// Returns a uniformly distributed 32 bit number.
+func (this *DtPCG32) Uint32() uint32 {
+	old := this.m_state
+	this.m_state = old*dtPCGMultiplier + this.m_inc
+	xorshifted := uint32(((old >> 18) ^ old) >> 27)
+	rot := uint32(old >> 59)
+	return (xorshifted >> rot) | (xorshifted << ((-rot) & 31))
+}
+
+/// Returns a uniformly distributed number [0..bound).
+///  @param[in]	bound	The exclusive upper bound. [Limit: > 0]
+func (this *DtPCG32) Uint32n(bound uint32) uint32 {
+	// Reject the low values which would bias the modulo.
+	threshold := -bound % bound
+	for {
+		r := this.Uint32()
+		if r >= threshold {
+			return r % bound
+		}
+	}
+}
+
+/// Returns a random number [0..1).
+/// The float is built from the top 24 bits, so every value is exactly representable.
+func (this *DtPCG32) Float32() float32 {
+	return float32(this.Uint32()>>8) * (1.0 / 16777216.0)
+}
+
+/// Returns the state of the generator, which can be restored with #SetState.
+/// Used to save and replay a sequence.
+func (this *DtPCG32) GetState() (state, inc uint64) {
+	return this.m_state, this.m_inc
+}
+
+/// Restores a state returned by #GetState.
+func (this *DtPCG32) SetState(state, inc uint64) {
+	this.m_state = state
+	this.m_inc = inc | 1
+}
+
+/// Returns random location on navmesh, see #FindRandomPoint.
+///  @param[in]		filter			The polygon filter to apply to the query.
+///  @param[in]		rng				The random number generator.
+///  @param[out]	randomRef		The reference id of the random location.
+///  @param[out]	randomPt		The random location.
+/// @returns The status flags for the query.
+func (this *DtNavMeshQuery) FindRandomPointRNG(filter *DtQueryFilter, rng DtRNG,
+	randomRef *DtPolyRef, randomPt []float32) DtStatus {
+	if rng == nil {
+		return DT_FAILURE | DT_INVALID_PARAM
+	}
+	return this.FindRandomPoint(filter, rng.Float32, randomRef, randomPt)
+}
+
+/// Returns random location on navmesh within the reach of specified location,
+/// see #FindRandomPointAroundCircle.
+///  @param[in]		startRef		The reference id of the polygon where the search starts.
+///  @param[in]		centerPos		The center of the search circle. [(x, y, z)]
+///  @param[in]		filter			The polygon filter to apply to the query.
+///  @param[in]		rng				The random number generator.
+///  @param[out]	randomRef		The reference id of the random location.
+///  @param[out]	randomPt		The random location. [(x, y, z)]
+/// @returns The status flags for the query.
+func (this *DtNavMeshQuery) FindRandomPointAroundCircleRNG(startRef DtPolyRef, centerPos []float32, maxRadius float32,
+	filter *DtQueryFilter, rng DtRNG,
+	randomRef *DtPolyRef, randomPt []float32) DtStatus {
+	if rng == nil {
+		return DT_FAILURE | DT_INVALID_PARAM
+	}
+	return this.FindRandomPointAroundCircle(startRef, centerPos, maxRadius, filter, rng.Float32, randomRef, randomPt)
+}
@@ -0,0 +1,197 @@
+package detour
+
+import "sync"
+
+/// A ray to cast with #DtNavMeshQuery.RaycastBatch.
+type DtRaycastRequest struct {
+	StartRef DtPolyRef  ///< The reference id of the polygon containing the start position.
+	StartPos [3]float32 ///< The start of the ray. [(x, y, z)]
+	EndPos   [3]float32 ///< The position to cast the ray toward. [(x, y, z)]
+}
+
+/// The result of a ray cast with #DtNavMeshQuery.RaycastBatch.
+type DtRaycastResult struct {
+	T            float32    ///< The hit parameter. (FLT_MAX if no wall hit.)
+	HitNormal    [3]float32 ///< The normal of the nearest wall hit. [(x, y, z)]
+	HitEdgeIndex int32      ///< The index of the edge on the final polygon where the wall was hit.
+	Status       DtStatus   ///< The status flags of the ray cast.
+}
+
+/// Casts many 'walkability' rays, see #Raycast2.
+///  @param[in]		requests	The rays to cast.
+///  @param[in]		filter		The polygon filter to apply to the query.
+///  @param[out]	results		The result of each ray. [Length: >= len(@p requests)]
+/// @returns The status flags for the query.
+/// @par
+///
+/// The visited polygons are not stored, so the rays are cast without
+/// allocating and #DT_BUFFER_TOO_SMALL is never set in the results. A ray which
+/// cannot be cast has the failure status in its result, the others are still
+/// cast.
+func (this *DtNavMeshQuery) RaycastBatch(requests []DtRaycastRequest, filter *DtQueryFilter, results []DtRaycastResult) DtStatus {
+	DtAssert(this.m_nav != nil)
+
+	// Validate input
+	if filter == nil || len(results) < len(requests) {
+		return DT_FAILURE | DT_INVALID_PARAM
+	}
+
+	for i := range requests {
+		req := &requests[i]
+		res := &results[i]
+		var hit DtRaycastHit
+		res.Status = this.Raycast2(req.StartRef, req.StartPos[:], req.EndPos[:], filter, 0, &hit, 0) &^ DT_BUFFER_TOO_SMALL
+		res.T = hit.T
+		res.HitNormal = hit.HitNormal
+		res.HitEdgeIndex = hit.HitEdgeIndex
+	}
+	return DT_SUCCESS
+}
+
+/// Casts batches of rays on several goroutines, each with its own query object.
+///
+/// The navigation mesh must not change while a batch is cast. The batch itself
+/// must not be used from several goroutines at once.
+/// @ingroup detour
+type DtRaycastBatch struct {
+	m_queries  []*DtNavMeshQuery  ///< The query object of each worker.
+	m_requests []DtRaycastRequest ///< Scratch rays of #LineOfSight.
+	m_results  []DtRaycastResult  ///< Scratch results of #LineOfSight.
+	m_wg       sync.WaitGroup
+}
+
+/// The smallest number of rays worth a goroutine of their own.
+const dtRaycastBatchMinRays int = 32
+
+/// Allocates a ray batch for the navigation mesh.
+///  @param[in]	nav		The navigation mesh to cast the rays on.
+///  @param[in]	workers	The number of goroutines casting the rays. [Limit: > 0]
+/// @return The ray batch, or null if @p workers is not valid.
+func DtAllocRaycastBatch(nav *DtNavMesh, workers int) *DtRaycastBatch {
+	if nav == nil || workers <= 0 {
+		return nil
+	}
+	batch := &DtRaycastBatch{}
+	for i := 0; i < workers; i++ {
+		// Rays do not use the node pool, keep it small.
+		query := DtAllocNavMeshQuery()
+		if DtStatusFailed(query.Init(nav, 32)) {
+			DtFreeRaycastBatch(batch)
+			return nil
+		}
+		batch.m_queries = append(batch.m_queries, query)
+	}
+	return batch
+}
+
+/// Frees the query objects of the batch.
+///  @param[in]	batch	A ray batch allocated using #DtAllocRaycastBatch
+func DtFreeRaycastBatch(batch *DtRaycastBatch) {
+	if batch == nil {
+		return
+	}
+	for _, query := range batch.m_queries {
+		DtFreeNavMeshQuery(query)
+	}
+	batch.m_queries = nil
+	batch.m_requests = nil
+	batch.m_results = nil
+}
+
+/// The number of goroutines casting the rays.
+func (this *DtRaycastBatch) GetWorkerCount() int {
+	return len(this.m_queries)
+}
+
+/// Casts the rays, splitting them between the workers.
+///  @param[in]		requests	The rays to cast.
+///  @param[in]		filter		The polygon filter to apply to the query. It is shared by the workers.
+///  @param[out]	results		The result of each ray. [Length: >= len(@p requests)]
+/// @returns The status flags for the query.
+/// @see #DtNavMeshQuery.RaycastBatch
+func (this *DtRaycastBatch) Raycast(requests []DtRaycastRequest, filter *DtQueryFilter, results []DtRaycastResult) DtStatus {
+	// Validate input
+	if filter == nil || len(results) < len(requests) {
+		return DT_FAILURE | DT_INVALID_PARAM
+	}
+
+	workers := len(this.m_queries)
+	if n := len(requests) / dtRaycastBatchMinRays; n < workers {
+		workers = n
+	}
+	if workers <= 1 {
+		return this.m_queries[0].RaycastBatch(requests, filter, results)
+	}
+
+	this.m_wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		begin := len(requests) * i / workers
+		end := len(requests) * (i + 1) / workers
+		go func(query *DtNavMeshQuery, requests []DtRaycastRequest, results []DtRaycastResult) {
+			query.RaycastBatch(requests, filter, results)
+			this.m_wg.Done()
+		}(this.m_queries[i], requests[begin:end], results[begin:end])
+	}
+	this.m_wg.Wait()
+	return DT_SUCCESS
+}
+
+/// Finds which positions can see each other.
+///  @param[in]		refs		The reference id of the polygon containing each position. [(polyRef) * @p count]
+///  @param[in]		positions	The positions. [(x, y, z) * @p count]
+///  @param[in]		count		The number of positions.
+///  @param[in]		filter		The polygon filter to apply to the query.
+///  @param[out]	visible		Whether position i sees position j, at [i * @p count + j]. [Length: >= @p count * @p count]
+/// @returns The status flags for the query.
+/// @par
+///
+/// A ray is cast once for each pair, from the lower index to the higher, and
+/// the matrix is symmetric. Positions see themselves. A pair whose ray cannot
+/// be cast, e.g. because of an invalid reference, does not see each other.
+///
+/// Like #DtNavMeshQuery.Raycast, the view is checked in 2D against the
+/// boundary of the navigation mesh. Any boundary blocks the view, whether it
+/// is a wall or a ledge.
+///
+/// The rays and their results are kept in buffers which are reused by the
+/// next calls.
+func (this *DtRaycastBatch) LineOfSight(refs []DtPolyRef, positions []float32, count int,
+	filter *DtQueryFilter, visible []bool) DtStatus {
+	// Validate input
+	if count < 0 || len(refs) < count || len(positions) < count*3 || len(visible) < count*count || filter == nil {
+		return DT_FAILURE | DT_INVALID_PARAM
+	}
+
+	this.m_requests = this.m_requests[:0]
+	for i := 0; i < count; i++ {
+		for j := i + 1; j < count; j++ {
+			var req DtRaycastRequest
+			req.StartRef = refs[i]
+			DtVcopy(req.StartPos[:], positions[i*3:])
+			DtVcopy(req.EndPos[:], positions[j*3:])
+			this.m_requests = append(this.m_requests, req)
+		}
+	}
+	if cap(this.m_results) < len(this.m_requests) {
+		this.m_results = make([]DtRaycastResult, len(this.m_requests))
+	}
+	this.m_results = this.m_results[:len(this.m_requests)]
+
+	status := this.Raycast(this.m_requests, filter, this.m_results)
+	if DtStatusFailed(status) {
+		return status
+	}
+
+	k := 0
+	for i := 0; i < count; i++ {
+		visible[i*count+i] = true
+		for j := i + 1; j < count; j++ {
+			res := &this.m_results[k]
+			k++
+			v := DtStatusSucceed(res.Status) && res.T >= 1
+			visible[i*count+j] = v
+			visible[j*count+i] = v
+		}
+	}
+	return status
+}
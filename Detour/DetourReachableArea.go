@@ -0,0 +1,377 @@
+package detour
+
+/// Finds the polygons which can be reached from the start position within a cost budget.
+///  @param[in]		startRef		The reference id of the polygon where the search starts.
+///  @param[in]		startPos		A position within the start polygon. [(x, y, z)]
+///  @param[in]		maxCost			The maximum cost of the paths.
+///  @param[in]		filter			The polygon filter to apply to the query.
+///  @param[out]	resultRef		The reference ids of the polygons reached by the search. [opt]
+///  @param[out]	resultParent	The reference ids of the parent polygons for each result. Zero if a
+///  								result polygon has no parent. [opt]
+///  @param[out]	resultCost		The search cost from @p startPos to the polygon. [opt]
+///  @param[out]	resultCount		The number of polygons found.
+///  @param[in]		maxResult		The maximum number of polygons the result arrays can hold.
+/// @returns The status flags for the query.
+/// @par
+///
+/// This is a Dijkstra search like #FindPolysAroundCircle, but bounded by the
+/// accumulated filter cost instead of the distance to the center, so area
+/// costs are taken into account. The cost of a polygon is the cost to the
+/// point where the search enters it (the middle of the portal edge), so the
+/// far side of the last polygons may be out of budget.
+///
+/// The order of the result set is from least to highest cost, and the
+/// parents can be used to rebuild the path to any result polygon.
+/// (See: #DtGetPathFromParents)
+///
+/// If the result arrays are too small to hold the entire result set, they will
+/// be filled to capacity.
+func (this *DtNavMeshQuery) FindPolysWithinCost(startRef DtPolyRef, startPos []float32, maxCost float32,
+	filter *DtQueryFilter,
+	resultRef, resultParent []DtPolyRef, resultCost []float32,
+	resultCount *int, maxResult int) DtStatus {
+	DtAssert(this.m_nav != nil)
+	DtAssert(this.m_nodePool != nil)
+	DtAssert(this.m_openList != nil)
+
+	*resultCount = 0
+
+	// Validate input
+	if startRef == 0 || !this.m_nav.IsValidPolyRef(startRef) || startPos == nil || filter == nil || maxCost < 0 {
+		return DT_FAILURE | DT_INVALID_PARAM
+	}
+	this.m_nodePool.Clear()
+	this.m_openList.Clear()
+
+	startNode := this.m_nodePool.GetNode(startRef, 0)
+	DtVcopy(startNode.Pos[:], startPos)
+	startNode.Pidx = 0
+	startNode.Cost = 0
+	startNode.Total = 0
+	startNode.Id = startRef
+	startNode.Flags = DT_NODE_OPEN
+	this.m_openList.Push(startNode)
+
+	status := DT_SUCCESS
+
+	n := 0
+
+	for !this.m_openList.Empty() {
+		bestNode := this.m_openList.Pop()
+		bestNode.Flags &= ^DT_NODE_OPEN
+		bestNode.Flags |= DT_NODE_CLOSED
+
+		// Get poly and tile.
+		// The API input has been cheked already, skip checking internal data.
+		bestRef := bestNode.Id
+		var bestTile *DtMeshTile
+		var bestPoly *DtPoly
+		this.m_nav.GetTileAndPolyByRefUnsafe(bestRef, &bestTile, &bestPoly)
+
+		// Get parent poly and tile.
+		var parentRef DtPolyRef
+		var parentTile *DtMeshTile
+		var parentPoly *DtPoly
+		if bestNode.Pidx != 0 {
+			parentRef = this.m_nodePool.GetNodeAtIdx(bestNode.Pidx).Id
+		}
+		if parentRef != 0 {
+			this.m_nav.GetTileAndPolyByRefUnsafe(parentRef, &parentTile, &parentPoly)
+		}
+		if n < maxResult {
+			if resultRef != nil {
+				resultRef[n] = bestRef
+			}
+			if resultParent != nil {
+				resultParent[n] = parentRef
+			}
+			if resultCost != nil {
+				resultCost[n] = bestNode.Total
+			}
+			n++
+		} else {
+			status |= DT_BUFFER_TOO_SMALL
+		}
+
+		for i := bestPoly.FirstLink; i != DT_NULL_LINK; i = bestTile.Links[i].Next {
+			neighbourRef := bestTile.Links[i].Ref
+			// Skip invalid neighbours and do not follow back to parent.
+			if neighbourRef == 0 || neighbourRef == parentRef {
+				continue
+			}
+			// Expand to neighbour
+			var neighbourTile *DtMeshTile
+			var neighbourPoly *DtPoly
+			this.m_nav.GetTileAndPolyByRefUnsafe(neighbourRef, &neighbourTile, &neighbourPoly)
+
+			// Do not advance if the polygon is excluded by the filter.
+			if !filter.PassFilter(neighbourRef, neighbourTile, neighbourPoly) {
+				continue
+			}
+			neighbourNode := this.m_nodePool.GetNode(neighbourRef, 0)
+			if neighbourNode == nil {
+				status |= DT_OUT_OF_NODES
+				continue
+			}
+
+			if (neighbourNode.Flags & DT_NODE_CLOSED) != 0 {
+				continue
+			}
+			// Cost
+			var pos [3]float32
+			if neighbourNode.Flags == 0 {
+				if stat := this.getEdgeMidPoint2(bestRef, bestPoly, bestTile, neighbourRef, neighbourPoly, neighbourTile, pos[:]); DtStatusFailed(stat) {
+					continue
+				}
+			} else {
+				DtVcopy(pos[:], neighbourNode.Pos[:])
+			}
+			cost := filter.GetCost(
+				bestNode.Pos[:], pos[:],
+				parentRef, parentTile, parentPoly,
+				bestRef, bestTile, bestPoly,
+				neighbourRef, neighbourTile, neighbourPoly)
+
+			total := bestNode.Total + cost
+
+			// The polygon cannot be reached within the budget from here.
+			if total > maxCost {
+				continue
+			}
+			// The node is already in open list and the new result is worse, skip.
+			if (neighbourNode.Flags&DT_NODE_OPEN) != 0 && total >= neighbourNode.Total {
+				continue
+			}
+			DtVcopy(neighbourNode.Pos[:], pos[:])
+			neighbourNode.Id = neighbourRef
+			neighbourNode.Pidx = this.m_nodePool.GetNodeIdx(bestNode)
+			neighbourNode.Total = total
+
+			if (neighbourNode.Flags & DT_NODE_OPEN) != 0 {
+				this.m_openList.Modify(neighbourNode)
+			} else {
+				neighbourNode.Flags = DT_NODE_OPEN
+				this.m_openList.Push(neighbourNode)
+			}
+		}
+	}
+
+	*resultCount = n
+
+	return status
+}
+
+/// Builds the path to a polygon from the results of a Dijkstra search.
+///  @param[in]		endRef			The reference id of the last polygon of the path.
+///  @param[in]		resultRef		The reference ids of the polygons found by the search. [(polyRef) * @p resultCount]
+///  @param[in]		resultParent	The reference ids of the parent polygons for each result. [(polyRef) * @p resultCount]
+///  @param[in]		resultCount		The number of polygons found by the search.
+///  @param[out]	path			An ordered list of polygon references representing the path. (Start to end.)
+///  								[(polyRef) * @p pathCount]
+///  @param[out]	pathCount		The number of polygons returned in the @p path array.
+///  @param[in]		maxPath			The maximum number of polygons the @p path array can hold. [Limit: >= 1]
+/// @returns The status flags for the query.
+/// @par
+///
+/// Unlike #GetPathFromDijkstraSearch this does not use the node pool, so the
+/// results stay usable after other queries.
+func DtGetPathFromParents(endRef DtPolyRef, resultRef, resultParent []DtPolyRef, resultCount int,
+	path []DtPolyRef, pathCount *int, maxPath int) DtStatus {
+	*pathCount = 0
+	if endRef == 0 || maxPath <= 0 || path == nil {
+		return DT_FAILURE | DT_INVALID_PARAM
+	}
+	parents := make(map[DtPolyRef]DtPolyRef, resultCount)
+	for i := 0; i < resultCount; i++ {
+		parents[resultRef[i]] = resultParent[i]
+	}
+	if _, ok := parents[endRef]; !ok {
+		return DT_FAILURE | DT_INVALID_PARAM
+	}
+
+	// Count the path length, the parents of a Dijkstra search cannot loop.
+	length := 0
+	for ref := endRef; ref != 0; ref = parents[ref] {
+		length++
+	}
+
+	status := DT_SUCCESS
+	ref := endRef
+	// If the path cannot be fully stored then keep its start.
+	for ; length > maxPath; length-- {
+		ref = parents[ref]
+		status |= DT_BUFFER_TOO_SMALL
+	}
+	for i := length - 1; i >= 0; i-- {
+		path[i] = ref
+		ref = parents[ref]
+	}
+	*pathCount = length
+	return status
+}
+
+/// Returns the boundary contours of a set of polygons.
+///  @param[in]		polys			The reference ids of the polygons. [(polyRef) * @p npolys]
+///  @param[in]		npolys			The number of polygons.
+///  @param[out]	verts			The vertices of the contours. [(x, y, z) * @p nverts]
+///  @param[out]	nverts			The number of vertices returned.
+///  @param[in]		maxVerts		The maximum number of vertices the @p verts array can hold.
+///  @param[out]	contours		The number of vertices of each contour. [(count) * @p ncontours]
+///  @param[out]	ncontours		The number of contours returned.
+///  @param[in]		maxContours		The maximum number of contours the @p contours array can hold.
+/// @returns The status flags for the query.
+/// @par
+///
+/// The boundary is made of the polygon edges which are not shared with another
+/// polygon of the set. It is used to draw the outline of the result of a
+/// search such as #FindPolysWithinCost.
+///
+/// The contours are closed, the last vertex connects back to the first one.
+/// They follow the winding of the polygons, so holes in the set run in the
+/// opposite direction of the outline around them. Off-mesh connections are
+/// ignored.
+///
+/// If the arrays are too small, the contours which fit are returned with
+/// #DT_BUFFER_TOO_SMALL.
+func (this *DtNavMeshQuery) GetPolysContours(polys []DtPolyRef, npolys int,
+	verts []float32, nverts *int, maxVerts int,
+	contours []int32, ncontours *int, maxContours int) DtStatus {
+	DtAssert(this.m_nav != nil)
+
+	*nverts = 0
+	*ncontours = 0
+	if npolys < 0 || len(polys) < npolys || len(verts) < maxVerts*3 || len(contours) < maxContours {
+		return DT_FAILURE | DT_INVALID_PARAM
+	}
+
+	// The value is cleared once the polygon is processed, the keys are the set.
+	inSet := make(map[DtPolyRef]bool, npolys)
+	for i := 0; i < npolys; i++ {
+		inSet[polys[i]] = true
+	}
+
+	// Gather the boundary segments.
+	const MAX_INTERVAL int = 16
+	var ints [MAX_INTERVAL]dtSegInterval
+	var segs []float32
+	var climb float32
+	for n := 0; n < npolys; n++ {
+		ref := polys[n]
+		if !inSet[ref] {
+			continue // Duplicate
+		}
+		inSet[ref] = false
+		var tile *DtMeshTile
+		var poly *DtPoly
+		if DtStatusFailed(this.m_nav.GetTileAndPolyByRef(ref, &tile, &poly)) {
+			return DT_FAILURE | DT_INVALID_PARAM
+		}
+		if poly.GetType() == DT_POLYTYPE_OFFMESH_CONNECTION {
+			continue
+		}
+		climb = DtMaxFloat32(climb, tile.Header.WalkableClimb)
+		base := this.m_nav.GetPolyRefBase(tile)
+		for i, j := 0, int(poly.VertCount-1); i < int(poly.VertCount); j, i = i, i+1 {
+			vj := tile.Verts[poly.Verts[j]*3:]
+			vi := tile.Verts[poly.Verts[i]*3:]
+			if (poly.Neis[j] & DT_EXT_LINK) == 0 {
+				// Internal edge
+				if _, ok := inSet[base|DtPolyRef(poly.Neis[j]-1)]; poly.Neis[j] != 0 && ok {
+					continue
+				}
+				segs = append(segs, vj[0], vj[1], vj[2], vi[0], vi[1], vi[2])
+				continue
+			}
+			// Tile border, the links to polygons of the set cover parts of the edge.
+			nints := 0
+			for k := poly.FirstLink; k != DT_NULL_LINK; k = tile.Links[k].Next {
+				link := &tile.Links[k]
+				if _, ok := inSet[link.Ref]; link.Edge == uint8(j) && link.Ref != 0 && ok {
+					insertInterval(ints[:], &nints, MAX_INTERVAL, int16(link.Bmin), int16(link.Bmax), link.Ref)
+				}
+			}
+			// Add sentinels
+			insertInterval(ints[:], &nints, MAX_INTERVAL, -1, 0, 0)
+			insertInterval(ints[:], &nints, MAX_INTERVAL, 255, 256, 0)
+			for k := 1; k < nints; k++ {
+				imin := ints[k-1].tmax
+				imax := ints[k].tmin
+				if imin >= imax {
+					continue
+				}
+				var a, b [3]float32
+				DtVlerp(a[:], vj, vi, float32(imin)/255.0)
+				DtVlerp(b[:], vj, vi, float32(imax)/255.0)
+				segs = append(segs, a[0], a[1], a[2], b[0], b[1], b[2])
+			}
+		}
+	}
+
+	// Chain the segments into contours, matching the end of a segment with
+	// the start of the next one.
+	nsegs := len(segs) / 6
+	const EPS = 0.01
+	starts := make(map[[2]int32][]int, nsegs)
+	key := func(p []float32) [2]int32 {
+		return [2]int32{int32(DtMathFloorf(p[0]/EPS + 0.5)), int32(DtMathFloorf(p[2]/EPS + 0.5))}
+	}
+	for i := 0; i < nsegs; i++ {
+		k := key(segs[i*6:])
+		starts[k] = append(starts[k], i)
+	}
+	used := make([]bool, nsegs)
+	// The vertices of neighbour tiles may differ in height, match on the
+	// xz-plane and pick the closest height within the climb of the tiles.
+	dist := func(p []float32, i int) (float32, float32) {
+		return DtVdist2DSqr(p, segs[i*6:]), DtAbsFloat32(p[1] - segs[i*6+1])
+	}
+	next := func(p []float32) int {
+		best := -1
+		bestDist, bestDy := float32(EPS*EPS), climb
+		for _, i := range starts[key(p)] {
+			if d, dy := dist(p, i); !used[i] && d <= EPS*EPS && dy <= bestDy {
+				best, bestDist, bestDy = i, d, dy
+			}
+		}
+		if best == -1 {
+			// The end point may have been rounded to a neighbour cell.
+			for i := 0; i < nsegs; i++ {
+				if d, dy := dist(p, i); !used[i] && d <= bestDist && dy <= bestDy {
+					best, bestDist, bestDy = i, d, dy
+				}
+			}
+		}
+		return best
+	}
+
+	status := DT_SUCCESS
+	nv := 0
+	nc := 0
+	for s := 0; s < nsegs; s++ {
+		if used[s] {
+			continue
+		}
+		// Follow the chain, the contour is stored only if it fits.
+		first := nv
+		count := 0
+		for i := s; i != -1; i = next(segs[i*6+3:]) {
+			used[i] = true
+			if nv < maxVerts {
+				DtVcopy(verts[nv*3:], segs[i*6:])
+				nv++
+			}
+			count++
+		}
+		if first+count > maxVerts || nc >= maxContours {
+			nv = first
+			status |= DT_BUFFER_TOO_SMALL
+			continue
+		}
+		contours[nc] = int32(count)
+		nc++
+	}
+
+	*nverts = nv
+	*ncontours = nc
+	return status
+}
@@ -0,0 +1,422 @@
+package detour
+
+import "sort"
+
+/// Tells when polygons can be entered, see #DtNavMeshQuery.FindTimedPath.
+/// @ingroup detour
+type DtTraversalSchedule interface {
+	/// Returns the earliest time at or after @p t at which the polygon can be
+	/// entered, or a negative value if it can never be entered again.
+	///  @param[in]	ref		The reference id of the polygon. (Off-mesh connections are polygons too.)
+	///  @param[in]	t		The time the polygon is reached.
+	GetEntryTime(ref DtPolyRef, t float32) float32
+}
+
+/// A time window, from Start included to End excluded.
+type DtTimeWindow struct {
+	Start float32 ///< The time the window opens.
+	End   float32 ///< The time the window closes.
+}
+
+type dtTimeWindowEntry struct {
+	windows []DtTimeWindow ///< The windows, sorted.
+	period  float32        ///< The period the windows repeat with, zero if they do not.
+}
+
+/// A #DtTraversalSchedule made of time windows per polygon.
+///
+/// A door is a polygon with the windows it is open in. An elevator is an
+/// off-mesh connection whose windows repeat, e.g. a window of 2 seconds every
+/// 20 seconds. The polygons without windows can always be entered.
+/// @ingroup detour
+type DtTimeWindows struct {
+	m_entries map[DtPolyRef]*dtTimeWindowEntry
+}
+
+/// Allocates an empty schedule, all the polygons can always be entered.
+func DtAllocTimeWindows() *DtTimeWindows {
+	return &DtTimeWindows{m_entries: make(map[DtPolyRef]*dtTimeWindowEntry)}
+}
+
+/// Frees the windows of the schedule.
+///  @param[in]	schedule	A schedule allocated using #DtAllocTimeWindows
+func DtFreeTimeWindows(schedule *DtTimeWindows) {
+	if schedule == nil {
+		return
+	}
+	schedule.m_entries = nil
+}
+
+/// Sets the windows in which the polygon can be entered.
+///  @param[in]	ref		The reference id of the polygon.
+///  @param[in]	windows	The windows, they are copied. Empty windows mean the polygon can never be entered.
+///  @param[in]	period	The period the windows repeat with, zero if they do not.
+///  					The windows of a period must be within [0, @p period]. [Limit: >= 0]
+/// @return The status flags for the operation.
+func (this *DtTimeWindows) SetWindows(ref DtPolyRef, windows []DtTimeWindow, period float32) DtStatus {
+	if ref == 0 || !(period >= 0) {
+		return DT_FAILURE | DT_INVALID_PARAM
+	}
+	for _, w := range windows {
+		if !(w.Start < w.End) || (period > 0 && (w.Start < 0 || w.End > period)) {
+			return DT_FAILURE | DT_INVALID_PARAM
+		}
+	}
+	entry := &dtTimeWindowEntry{windows: append([]DtTimeWindow(nil), windows...), period: period}
+	sort.Slice(entry.windows, func(i, j int) bool { return entry.windows[i].Start < entry.windows[j].Start })
+	this.m_entries[ref] = entry
+	return DT_SUCCESS
+}
+
+/// Removes the windows of the polygon, it can then always be entered.
+///  @param[in]	ref		The reference id of the polygon.
+func (this *DtTimeWindows) Remove(ref DtPolyRef) {
+	delete(this.m_entries, ref)
+}
+
+/// Removes the windows of all the polygons.
+func (this *DtTimeWindows) Clear() {
+	this.m_entries = make(map[DtPolyRef]*dtTimeWindowEntry)
+}
+
+/// Implements #DtTraversalSchedule.
+func (this *DtTimeWindows) GetEntryTime(ref DtPolyRef, t float32) float32 {
+	entry := this.m_entries[ref]
+	if entry == nil {
+		return t
+	}
+	if entry.period == 0 {
+		for _, w := range entry.windows {
+			if t < w.End {
+				return DtMaxFloat32(t, w.Start)
+			}
+		}
+		return -1
+	}
+	if len(entry.windows) == 0 {
+		return -1
+	}
+	base := DtMathMulf(DtMathFloorf(t/entry.period), entry.period)
+	local := t - base
+	for _, w := range entry.windows {
+		if local < w.End {
+			return base + DtMaxFloat32(local, w.Start)
+		}
+	}
+	return base + entry.period + entry.windows[0].Start
+}
+
+type dtNodeTime struct {
+	time float32 ///< The time the polygon of the node is entered.
+	wait float32 ///< The time waited before entering it.
+}
+
+/// Finds a path from the start polygon to the end polygon, waiting for the
+/// polygons to be available.
+///  @param[in]		startRef	The reference id of the start polygon.
+///  @param[in]		endRef		The reference id of the end polygon.
+///  @param[in]		startPos	A position within the start polygon. [(x, y, z)]
+///  @param[in]		endPos		A position within the end polygon. [(x, y, z)]
+///  @param[in]		startTime	The time the agent leaves the start position.
+///  @param[in]		speed		The speed of the agent. [Limit: > 0]
+///  @param[in]		waitCost	The cost of waiting for one unit of time. [Limit: >= 0]
+///  @param[in]		filter		The polygon filter to apply to the query.
+///  @param[in]		schedule	Tells when the polygons can be entered. [opt]
+///  @param[out]	path		An ordered list of polygon references representing the path. (Start to end.)
+///  							[(polyRef) * @p pathCount]
+///  @param[out]	times		The time each polygon of the path is entered. [opt] [(time) * @p pathCount]
+///  @param[out]	waits		The time waited before entering each polygon of the path. [opt] [(time) * @p pathCount]
+///  @param[out]	arrivalTime	The time the end position is reached. [opt]
+///  @param[out]	pathCount	The number of polygons returned in the @p path array.
+///  @param[in]		maxPath		The maximum number of polygons the arrays can hold. [Limit: >= 1]
+/// @returns The status flags for the query.
+/// @par
+///
+/// This is #FindPath with time: the agent moves between the edge midpoints of
+/// the polygons at @p speed, and when it reaches a polygon which cannot be
+/// entered yet, it waits at the edge until the polygon opens. Polygons which
+/// never open again are not entered. Waiting adds @p waitCost per unit of time
+/// to the cost of the path, so the search trades detours against waits.
+///
+/// The arrival time of each search node is kept beside the node pool, by node
+/// index. A polygon can have several search nodes, up to 4 per side it is
+/// entered from, so that a path which is more expensive but reaches the polygon
+/// earlier is kept for the polygons it may still enter in time. A path is only
+/// dropped when another one is as cheap and as early, or when all the nodes of
+/// the side are in use and it is more expensive than the open ones. Nodes which
+/// were already expanded are never replaced. Only the time a polygon is
+/// entered is checked against the schedule, the polygon may close while the
+/// agent is in it.
+///
+/// The first polygon is entered at @p startTime without waiting. If the end
+/// polygon cannot be reached, the path to the polygon closest to the end is
+/// returned with #DT_PARTIAL_RESULT, and @p arrivalTime is the time that
+/// polygon is entered.
+///
+/// If the arrays are too small to hold the entire path, they are filled from
+/// the start polygon and #DT_BUFFER_TOO_SMALL is set.
+func (this *DtNavMeshQuery) FindTimedPath(startRef, endRef DtPolyRef, startPos, endPos []float32,
+	startTime, speed, waitCost float32, filter *DtQueryFilter, schedule DtTraversalSchedule,
+	path []DtPolyRef, times, waits []float32, arrivalTime *float32, pathCount *int, maxPath int) DtStatus {
+	DtAssert(this.m_nav != nil)
+	DtAssert(this.m_nodePool != nil)
+	DtAssert(this.m_openList != nil)
+
+	*pathCount = 0
+
+	// Validate input
+	if !this.m_nav.IsValidPolyRef(startRef) || !this.m_nav.IsValidPolyRef(endRef) ||
+		startPos == nil || endPos == nil || filter == nil || !(speed > 0) || !(waitCost >= 0) ||
+		maxPath <= 0 || path == nil {
+		return DT_FAILURE | DT_INVALID_PARAM
+	}
+
+	if n := int(this.m_nodePool.GetMaxNodes()) + 1; len(this.m_nodeTimes) < n {
+		this.m_nodeTimes = make([]dtNodeTime, n)
+	}
+	this.m_nodePool.Clear()
+	this.m_openList.Clear()
+
+	startNode := this.m_nodePool.GetNode(startRef, 0)
+	DtVcopy(startNode.Pos[:], startPos)
+	startNode.Pidx = 0
+	startNode.Cost = 0
+	startNode.Total = DtMathMulf(DtVdist(startPos, endPos), H_SCALE)
+	startNode.Id = startRef
+	startNode.Flags = DT_NODE_OPEN
+	this.m_openList.Push(startNode)
+	this.m_nodeTimes[this.m_nodePool.GetNodeIdx(startNode)] = dtNodeTime{time: startTime}
+
+	// The number of nodes a polygon side can have. The label is stored in the
+	// node state, above the side the polygon is entered from.
+	const SIDE_BITS uint = 2
+	const MAX_LABELS int = DT_MAX_STATES_PER_NODE >> SIDE_BITS
+
+	lastBestNode := startNode
+	lastBestNodeCost := startNode.Total
+
+	outOfNodes := false
+
+	for !this.m_openList.Empty() {
+		// Remove node from open list and put it in closed list.
+		bestNode := this.m_openList.Pop()
+		bestNode.Flags &= ^DT_NODE_OPEN
+		bestNode.Flags |= DT_NODE_CLOSED
+
+		// Reached the goal, stop searching.
+		if bestNode.Id == endRef {
+			lastBestNode = bestNode
+			break
+		}
+
+		// Get current poly and tile.
+		// The API input has been cheked already, skip checking internal data.
+		bestRef := bestNode.Id
+		var bestTile *DtMeshTile
+		var bestPoly *DtPoly
+		this.m_nav.GetTileAndPolyByRefUnsafe(bestRef, &bestTile, &bestPoly)
+		bestTime := this.m_nodeTimes[this.m_nodePool.GetNodeIdx(bestNode)].time
+
+		// Get parent poly and tile.
+		var parentRef DtPolyRef
+		var parentTile *DtMeshTile
+		var parentPoly *DtPoly
+		if bestNode.Pidx != 0 {
+			parentRef = this.m_nodePool.GetNodeAtIdx(bestNode.Pidx).Id
+		}
+		if parentRef != 0 {
+			this.m_nav.GetTileAndPolyByRefUnsafe(parentRef, &parentTile, &parentPoly)
+		}
+
+		for i := bestPoly.FirstLink; i != DT_NULL_LINK; i = bestTile.Links[i].Next {
+			neighbourRef := bestTile.Links[i].Ref
+
+			// Skip invalid ids and do not expand back to where we came from.
+			if neighbourRef == 0 || neighbourRef == parentRef {
+				continue
+			}
+			// Get neighbour poly and tile.
+			// The API input has been cheked already, skip checking internal data.
+			var neighbourTile *DtMeshTile
+			var neighbourPoly *DtPoly
+			this.m_nav.GetTileAndPolyByRefUnsafe(neighbourRef, &neighbourTile, &neighbourPoly)
+
+			if !filter.PassFilter(neighbourRef, neighbourTile, neighbourPoly) {
+				continue
+			}
+			// deal explicitly with crossing tile boundaries
+			var crossSide uint8
+			if bestTile.Links[i].Side != 0xff {
+				crossSide = (bestTile.Links[i].Side >> 1)
+			}
+
+			// The nodes of the neighbour entered through this side, one per time label.
+			// The edge position does not depend on the path, only compute it
+			// for polygons which are not in the pool yet.
+			var labels [MAX_LABELS]*DtNode
+			var pos [3]float32
+			hasPos := false
+			for k := 0; k < MAX_LABELS; k++ {
+				labels[k] = this.m_nodePool.FindNode(neighbourRef, crossSide|uint8(k)<<SIDE_BITS)
+				if labels[k] != nil && labels[k].Flags != 0 && !hasPos {
+					DtVcopy(pos[:], labels[k].Pos[:])
+					hasPos = true
+				}
+			}
+			if !hasPos {
+				this.getEdgeMidPoint2(bestRef, bestPoly, bestTile,
+					neighbourRef, neighbourPoly, neighbourTile, pos[:])
+			}
+
+			// Wait at the edge until the neighbour can be entered.
+			reachTime := bestTime + DtVdist(bestNode.Pos[:], pos[:])/speed
+			entryTime := reachTime
+			if schedule != nil {
+				entryTime = schedule.GetEntryTime(neighbourRef, reachTime)
+				if entryTime < 0 {
+					continue
+				}
+				entryTime = DtMaxFloat32(entryTime, reachTime)
+			}
+			wait := entryTime - reachTime
+
+			// Calculate cost and heuristic.
+			curCost := filter.GetCost(bestNode.Pos[:], pos[:],
+				parentRef, parentTile, parentPoly,
+				bestRef, bestTile, bestPoly,
+				neighbourRef, neighbourTile, neighbourPoly)
+			cost := bestNode.Cost + curCost + DtMathMulf(wait, waitCost)
+			var heuristic float32
+
+			// Special case for last node.
+			if neighbourRef == endRef {
+				endCost := filter.GetCost(pos[:], endPos,
+					bestRef, bestTile, bestPoly,
+					neighbourRef, neighbourTile, neighbourPoly,
+					0, nil, nil)
+				cost += endCost
+				heuristic = 0
+			} else {
+				heuristic = DtMathMulf(DtVdist(pos[:], endPos), H_SCALE)
+			}
+
+			total := cost + heuristic
+
+			// Skip the result if a label is as cheap and as early. Otherwise it replaces
+			// an open label it beats on both, or takes a free one. When all the labels
+			// are in use, it replaces the most expensive open one if it is cheaper.
+			var neighbourNode *DtNode
+			dominated := false
+			free, worst := -1, -1
+			for k, node := range labels {
+				if node == nil || node.Flags == 0 {
+					if free < 0 {
+						free = k
+					}
+					continue
+				}
+				nodeTime := this.m_nodeTimes[this.m_nodePool.GetNodeIdx(node)].time
+				if node.Total <= total && nodeTime <= entryTime {
+					dominated = true
+					break
+				}
+				// Closed labels may have been expanded, their children depend on their time.
+				if (node.Flags & DT_NODE_OPEN) == 0 {
+					continue
+				}
+				if neighbourNode == nil && total <= node.Total && entryTime <= nodeTime {
+					neighbourNode = node
+				}
+				if worst < 0 || node.Total > labels[worst].Total {
+					worst = k
+				}
+			}
+			if dominated {
+				continue
+			}
+			if neighbourNode == nil && free >= 0 {
+				neighbourNode = labels[free]
+				if neighbourNode == nil {
+					neighbourNode = this.m_nodePool.GetNode(neighbourRef, crossSide|uint8(free)<<SIDE_BITS)
+					if neighbourNode == nil {
+						outOfNodes = true
+						continue
+					}
+				}
+			}
+			if neighbourNode == nil {
+				if worst < 0 || total >= labels[worst].Total {
+					continue
+				}
+				neighbourNode = labels[worst]
+			}
+			if neighbourNode.Flags == 0 {
+				DtVcopy(neighbourNode.Pos[:], pos[:])
+			}
+
+			// Add or update the node.
+			neighbourNode.Pidx = this.m_nodePool.GetNodeIdx(bestNode)
+			neighbourNode.Id = neighbourRef
+			neighbourNode.Cost = cost
+			neighbourNode.Total = total
+			this.m_nodeTimes[this.m_nodePool.GetNodeIdx(neighbourNode)] = dtNodeTime{time: entryTime, wait: wait}
+
+			if (neighbourNode.Flags & DT_NODE_OPEN) != 0 {
+				// Already in open, update node location.
+				this.m_openList.Modify(neighbourNode)
+			} else {
+				// Put the node in open list.
+				neighbourNode.Flags |= DT_NODE_OPEN
+				this.m_openList.Push(neighbourNode)
+			}
+
+			// Update nearest node to target so far.
+			if heuristic < lastBestNodeCost {
+				lastBestNodeCost = heuristic
+				lastBestNode = neighbourNode
+			}
+		}
+	}
+
+	status := this.getPathToNode(lastBestNode, path, pathCount, maxPath)
+
+	// The times of the stored part of the path.
+	curNode := lastBestNode
+	for n := this.getPathLength(lastBestNode); n > *pathCount; n-- {
+		curNode = this.m_nodePool.GetNodeAtIdx(curNode.Pidx)
+	}
+	for i := *pathCount - 1; i >= 0; i-- {
+		nodeTime := this.m_nodeTimes[this.m_nodePool.GetNodeIdx(curNode)]
+		if times != nil {
+			times[i] = nodeTime.time
+		}
+		if waits != nil {
+			waits[i] = nodeTime.wait
+		}
+		curNode = this.m_nodePool.GetNodeAtIdx(curNode.Pidx)
+	}
+
+	if arrivalTime != nil {
+		*arrivalTime = this.m_nodeTimes[this.m_nodePool.GetNodeIdx(lastBestNode)].time
+		if lastBestNode.Id == endRef {
+			*arrivalTime += DtVdist(lastBestNode.Pos[:], endPos) / speed
+		}
+	}
+
+	if lastBestNode.Id != endRef {
+		status |= DT_PARTIAL_RESULT
+	}
+	if outOfNodes {
+		status |= DT_OUT_OF_NODES
+	}
+	return status
+}
+
+// Gets the number of nodes from the start node to the specified node.
+func (this *DtNavMeshQuery) getPathLength(endNode *DtNode) int {
+	length := 0
+	for curNode := endNode; curNode != nil; curNode = this.m_nodePool.GetNodeAtIdx(curNode.Pidx) {
+		length++
+	}
+	return length
+}
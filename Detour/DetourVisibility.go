@@ -0,0 +1,177 @@
+package detour
+
+import (
+	"math"
+	"sort"
+)
+
+/// Computes the area visible from a position, bounded by the walls of the navigation mesh.
+///  @param[in]		startRef	The reference id of the polygon containing @p centerPos.
+///  @param[in]		centerPos	The position to look from. [(x, y, z)]
+///  @param[in]		dir			The view direction. Only used when @p halfAngle is less than pi. [(x, y, z)]
+///  @param[in]		radius		The view distance.
+///  @param[in]		halfAngle	Half the view angle in radians. Pi or more is a full circle. [Limit: > 0]
+///  @param[in]		filter		The polygon filter to apply to the query.
+///  @param[out]	verts		The vertices of the visibility polygon. [(x, y, z) * @p vertCount]
+///  @param[out]	vertCount	The number of vertices of the visibility polygon.
+///  @param[in]		maxVerts	The maximum number of vertices the @p verts array can hold.
+/// @returns The status flags for the query.
+/// @par
+///
+/// The polygon is a fan of #Raycast2 results, wound the same way as the
+/// navigation mesh polygons. Rays are cast at a fixed angular step, which
+/// approximates the arc of the view circle, and towards the ends of the walls
+/// found with #GetPolyWallSegments around the center, so the corners of the
+/// walls are kept. A ray is cast on both sides of each wall end to find what is
+/// seen past it. Vertices in the middle of straight edges are removed.
+///
+/// When the view is a cone, the first vertex is @p centerPos and the others go
+/// from one side of the cone to the other.
+///
+/// Like #Raycast, the visibility is checked in 2D against the boundary of the
+/// navigation mesh. The height of each vertex is taken from the polygon the ray
+/// ends in, or from @p centerPos when the ray crosses too many polygons to tell.
+///
+/// If the @p verts array is too small to hold the whole polygon, it is filled
+/// as far as possible from the first vertex.
+func (this *DtNavMeshQuery) FindVisibilityPolygon(startRef DtPolyRef, centerPos, dir []float32,
+	radius, halfAngle float32, filter *DtQueryFilter,
+	verts []float32, vertCount *int, maxVerts int) DtStatus {
+	DtAssert(this.m_nav != nil)
+
+	*vertCount = 0
+
+	// Validate input
+	if startRef == 0 || !this.m_nav.IsValidPolyRef(startRef) || centerPos == nil ||
+		filter == nil || !(radius > 0) || !(halfAngle > 0) || maxVerts < 0 {
+		return DT_FAILURE | DT_INVALID_PARAM
+	}
+
+	const MAX_STEP float64 = math.Pi / 16 // The largest angle between two rays.
+	const VERTEX_EPS float64 = 1e-3       // The angle of the rays passing a wall end.
+	const COLLINEAR_EPS float32 = 1e-2    // The distance a vertex may be off a straight edge.
+
+	fullCircle := float64(halfAngle) >= math.Pi
+	var base float64
+	if !fullCircle {
+		if dir == nil || DtSqrFloat32(dir[0])+DtSqrFloat32(dir[2]) < 1e-12 {
+			return DT_FAILURE | DT_INVALID_PARAM
+		}
+		base = math.Atan2(float64(dir[2]), float64(dir[0]))
+	}
+	half := math.Min(float64(halfAngle), math.Pi)
+
+	// The angles are relative to the view direction, in [-half, half].
+	nsteps := int(math.Ceil(2 * half / MAX_STEP))
+	angles := make([]float64, 0, nsteps+1)
+	for i := 0; i < nsteps; i++ {
+		angles = append(angles, -half+2*half*float64(i)/float64(nsteps))
+	}
+	if !fullCircle {
+		angles = append(angles, half)
+	}
+
+	const MAX_POLYS int = 256
+	var polys [MAX_POLYS]DtPolyRef
+	var npolys int
+	status := this.FindPolysAroundCircle(startRef, centerPos, radius, filter, polys[:], nil, nil, &npolys, MAX_POLYS)
+	if DtStatusFailed(status) {
+		return status
+	}
+	status = DT_SUCCESS
+
+	const MAX_SEGS int = int(DT_VERTS_PER_POLYGON) * 4
+	var segs [MAX_SEGS * 6]float32
+	radiusSqr := DtSqrFloat32(radius)
+	for i := 0; i < npolys; i++ {
+		var nsegs int
+		this.GetPolyWallSegments(polys[i], filter, segs[:], nil, &nsegs, MAX_SEGS)
+		for j := 0; j < nsegs*2; j++ {
+			v := segs[j*3:]
+			d := DtVdist2DSqr(v, centerPos)
+			if d > radiusSqr || d < 1e-6 {
+				continue
+			}
+			a := math.Atan2(float64(v[2]-centerPos[2]), float64(v[0]-centerPos[0])) - base
+			for a > math.Pi {
+				a -= 2 * math.Pi
+			}
+			for a <= -math.Pi {
+				a += 2 * math.Pi
+			}
+			for _, da := range [3]float64{-VERTEX_EPS, 0, VERTEX_EPS} {
+				if a+da >= -half && a+da <= half {
+					angles = append(angles, a+da)
+				}
+			}
+		}
+	}
+	// Walk the angles downwards, the winding of the navigation mesh polygons.
+	sort.Sort(sort.Reverse(sort.Float64Slice(angles)))
+
+	n := 0
+	if !fullCircle {
+		if n < maxVerts {
+			DtVcopy(verts[n*3:], centerPos)
+			n++
+		} else {
+			status |= DT_BUFFER_TOO_SMALL
+		}
+	}
+
+	const MAX_PATH int = 64
+	var path [MAX_PATH]DtPolyRef
+	var hit DtRaycastHit
+	hit.Path = path[:]
+	hit.MaxPath = int32(MAX_PATH)
+	prev := math.Inf(1)
+	for _, a := range angles {
+		if prev-a < VERTEX_EPS*0.5 {
+			continue
+		}
+		prev = a
+
+		var endPos [3]float32
+		endPos[0] = centerPos[0] + DtMathMulf(radius, float32(math.Cos(base+a)))
+		endPos[1] = centerPos[1]
+		endPos[2] = centerPos[2] + DtMathMulf(radius, float32(math.Sin(base+a)))
+		stat := this.Raycast2(startRef, centerPos, endPos[:], filter, 0, &hit, 0)
+		if DtStatusFailed(stat) {
+			return stat
+		}
+
+		var pos [3]float32
+		if hit.T < 1 {
+			DtVlerp(pos[:], centerPos, endPos[:], hit.T)
+		} else {
+			DtVcopy(pos[:], endPos[:])
+		}
+		if !DtStatusDetail(stat, DT_BUFFER_TOO_SMALL) && hit.PathCount > 0 {
+			var h float32
+			if DtStatusSucceed(this.GetPolyHeight(path[hit.PathCount-1], pos[:], &h)) {
+				pos[1] = h
+			}
+		}
+
+		if n > 0 && DtVdist2DSqr(verts[(n-1)*3:], pos[:]) < 1e-6 {
+			continue
+		}
+		// Walls split at tile borders leave vertices in the middle of straight edges.
+		if n >= 2 && (fullCircle || n >= 3) {
+			var t float32
+			if DtDistancePtSegSqr2D(verts[(n-1)*3:], verts[(n-2)*3:], pos[:], &t) < DtSqrFloat32(COLLINEAR_EPS) {
+				DtVcopy(verts[(n-1)*3:], pos[:])
+				continue
+			}
+		}
+		if n >= maxVerts {
+			status |= DT_BUFFER_TOO_SMALL
+			break
+		}
+		DtVcopy(verts[n*3:], pos[:])
+		n++
+	}
+
+	*vertCount = n
+	return status
+}
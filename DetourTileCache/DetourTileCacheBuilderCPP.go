@@ -488,8 +488,8 @@ func distancePtSeg(x, z, px, pz, qx, qz int32) float32 {
 	pqz := float32(qz - pz)
 	dx := float32(x - px)
 	dz := float32(z - pz)
-	d := float32(pqx*pqx + pqz*pqz)
-	t := float32(pqx*dx + pqz*dz)
+	d := float32(detour.DtMathMulf(pqx, pqx) + detour.DtMathMulf(pqz, pqz))
+	t := float32(detour.DtMathMulf(pqx, dx) + detour.DtMathMulf(pqz, dz))
 	if d > 0 {
 		t /= d
 	}
@@ -499,10 +499,10 @@ func distancePtSeg(x, z, px, pz, qx, qz int32) float32 {
 		t = 1
 	}
 
-	dx = float32(px) + t*pqx - float32(x)
-	dz = float32(pz) + t*pqz - float32(z)
+	dx = float32(px) + detour.DtMathMulf(t, pqx) - float32(x)
+	dz = float32(pz) + detour.DtMathMulf(t, pqz) - float32(z)
 
-	return dx*dx + dz*dz
+	return detour.DtMathMulf(dx, dx) + detour.DtMathMulf(dz, dz)
 }
 
 func simplifyContour(cont *dtTempContour, maxError float32) {
@@ -1858,8 +1858,8 @@ func DtMarkCylinderArea(layer *DtTileCacheLayer, orig []float32, cs, ch float32,
 	ics := 1.0 / cs
 	ich := 1.0 / ch
 
-	px := (pos[0] - orig[0]) * ics
-	pz := (pos[2] - orig[2]) * ics
+	px := detour.DtMathMulf(pos[0]-orig[0], ics)
+	pz := detour.DtMathMulf(pos[2]-orig[2], ics)
 
 	minx := int32(detour.DtMathFloorf((bmin[0] - orig[0]) * ics))
 	miny := int32(detour.DtMathFloorf((bmin[1] - orig[1]) * ich))
@@ -1898,7 +1898,7 @@ func DtMarkCylinderArea(layer *DtTileCacheLayer, orig []float32, cs, ch float32,
 		for x := minx; x <= maxx; x++ {
 			dx := float32(x) + 0.5 - px
 			dz := float32(z) + 0.5 - pz
-			if dx*dx+dz*dz > r2 {
+			if detour.DtMathMulf(dx, dx)+detour.DtMathMulf(dz, dz) > r2 {
 				continue
 			}
 			y := int32(layer.Heights[x+z*w])
@@ -1972,14 +1972,14 @@ func DtMarkBoxArea2(layer *DtTileCacheLayer, orig []float32, cs, ch float32,
 	ics := 1.0 / cs
 	ich := 1.0 / ch
 
-	cx := (center[0] - orig[0]) * ics
-	cz := (center[2] - orig[2]) * ics
+	cx := detour.DtMathMulf(center[0]-orig[0], ics)
+	cz := detour.DtMathMulf(center[2]-orig[2], ics)
 
 	maxr := 1.41 * detour.DtMaxFloat32(halfExtents[0], halfExtents[2])
-	minx := int32(detour.DtMathFloorf(cx - maxr*ics))
-	maxx := int32(detour.DtMathFloorf(cx + maxr*ics))
-	minz := int32(detour.DtMathFloorf(cz - maxr*ics))
-	maxz := int32(detour.DtMathFloorf(cz + maxr*ics))
+	minx := int32(detour.DtMathFloorf(cx - detour.DtMathMulf(maxr, ics)))
+	maxx := int32(detour.DtMathFloorf(cx + detour.DtMathMulf(maxr, ics)))
+	minz := int32(detour.DtMathFloorf(cz - detour.DtMathMulf(maxr, ics)))
+	maxz := int32(detour.DtMathFloorf(cz + detour.DtMathMulf(maxr, ics)))
 	miny := int32(detour.DtMathFloorf((center[1] - halfExtents[1] - orig[1]) * ich))
 	maxy := int32(detour.DtMathFloorf((center[1] + halfExtents[1] - orig[1]) * ich))
 
@@ -2008,18 +2008,18 @@ func DtMarkBoxArea2(layer *DtTileCacheLayer, orig []float32, cs, ch float32,
 		maxz = h - 1
 	}
 
-	xhalf := halfExtents[0]*ics + 0.5
-	zhalf := halfExtents[2]*ics + 0.5
+	xhalf := detour.DtMathMulf(halfExtents[0], ics) + 0.5
+	zhalf := detour.DtMathMulf(halfExtents[2], ics) + 0.5
 
 	for z := minz; z <= maxz; z++ {
 		for x := minx; x <= maxx; x++ {
 			x2 := 2.0 * (float32(x) - cx)
 			z2 := 2.0 * (float32(z) - cz)
-			xrot := rotAux[1]*x2 + rotAux[0]*z2
+			xrot := detour.DtMathMulf(rotAux[1], x2) + detour.DtMathMulf(rotAux[0], z2)
 			if xrot > xhalf || xrot < -xhalf {
 				continue
 			}
-			zrot := rotAux[1]*z2 - rotAux[0]*x2
+			zrot := detour.DtMathMulf(rotAux[1], z2) - detour.DtMathMulf(rotAux[0], x2)
 			if zrot > zhalf || zrot < -zhalf {
 				continue
 			}
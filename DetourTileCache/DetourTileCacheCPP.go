@@ -421,7 +421,7 @@ func (this *DtTileCache) AddBoxObstacle2(center, halfExtents []float32, yRadians
 	coshalf := float32(math.Cos(0.5 * float64(yRadians)))
 	sinhalf := float32(math.Sin(-0.5 * float64(yRadians)))
 	ob.OrientedBox.RotAux[0] = coshalf * sinhalf
-	ob.OrientedBox.RotAux[1] = coshalf*coshalf - 0.5
+	ob.OrientedBox.RotAux[1] = detour.DtMathMulf(coshalf, coshalf) - 0.5
 
 	req := &this.m_reqs[this.m_nreqs]
 	this.m_nreqs++
@@ -740,12 +740,12 @@ func (this *DtTileCache) BuildNavMeshTile(ref DtCompressedTileRef, navmesh *deto
 
 func (this *DtTileCache) CalcTightTileBounds(header *DtTileCacheLayerHeader, bmin, bmax []float32) {
 	cs := this.m_params.Cs
-	bmin[0] = header.Bmin[0] + float32(header.Minx)*cs
+	bmin[0] = header.Bmin[0] + detour.DtMathMulf(float32(header.Minx), cs)
 	bmin[1] = header.Bmin[1]
-	bmin[2] = header.Bmin[2] + float32(header.Miny)*cs
-	bmax[0] = header.Bmin[0] + float32(header.Maxx+1)*cs
+	bmin[2] = header.Bmin[2] + detour.DtMathMulf(float32(header.Miny), cs)
+	bmax[0] = header.Bmin[0] + detour.DtMathMulf(float32(header.Maxx+1), cs)
 	bmax[1] = header.Bmax[1]
-	bmax[2] = header.Bmin[2] + float32(header.Maxy+1)*cs
+	bmax[2] = header.Bmin[2] + detour.DtMathMulf(float32(header.Maxy+1), cs)
 }
 
 func (this *DtTileCache) GetObstacleBounds(ob *DtTileCacheObstacle, bmin, bmax []float32) {
@@ -764,7 +764,7 @@ func (this *DtTileCache) GetObstacleBounds(ob *DtTileCacheObstacle, bmin, bmax [
 	} else if ob.Type == DT_OBSTACLE_ORIENTED_BOX {
 		orientedBox := &ob.OrientedBox
 
-		maxr := 1.41 * detour.DtMaxFloat32(orientedBox.HalfExtents[0], orientedBox.HalfExtents[2])
+		maxr := detour.DtMathMulf(1.41, detour.DtMaxFloat32(orientedBox.HalfExtents[0], orientedBox.HalfExtents[2]))
 		bmin[0] = orientedBox.Center[0] - maxr
 		bmax[0] = orientedBox.Center[0] + maxr
 		bmin[1] = orientedBox.Center[1] - orientedBox.HalfExtents[1]
@@ -4,7 +4,6 @@ set GOPATH=%BASEDIR%
 echo %GOPATH%
 
 cd %CURDIR%\tests\c\bin
-call ctest.exe rand
 call ctest.exe a 1
 cd %CURDIR%
 go test -tags debug ./tests/...
@@ -13,3 +12,5 @@ cd %CURDIR%\tests\c\bin
 call ctest.exe a 0
 cd %CURDIR%
 go test -tags debug ./tests/...
+
+go test -tags "debug deterministic" ./tests/...
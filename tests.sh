@@ -4,7 +4,6 @@ export CURDIR=$PWD
 export GOPATH=$CURDIR/../../../../
 
 cd $CURDIR/tests/c/bin/
-./ctest rand
 ./ctest a 0
 
 cd $CURDIR
@@ -18,5 +17,4 @@ cd $CURDIR
 
 go test -tags debug ./tests/...
 
-
-
+go test -tags "debug deterministic" ./tests/...
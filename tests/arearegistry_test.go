@@ -0,0 +1,84 @@
+package tests
+
+import (
+	"testing"
+
+	detour "github.com/fananchong/recastnavigation-go/Detour"
+)
+
+func Test_AreaRegistry(t *testing.T) {
+	registry := LoadAreaRegistry("areas.json")
+
+	var id uint8
+	var flag uint16
+	if !registry.FindArea("door", &id) || id != 3 || registry.GetAreaName(3) != "door" || registry.GetAreaName(6) != "" {
+		t.Fatal("unexpected areas")
+	}
+	if !registry.FindFlag("door", &flag) || flag != 0x04 || registry.GetFlagName(4) != "disabled" || registry.FindFlag("fly", &flag) {
+		t.Fatal("unexpected flags")
+	}
+	if registry.GetAreaFlags(3) != 0x05 || registry.GetAreaFlags(5) != 0 {
+		t.Fatal("unexpected area flags")
+	}
+
+	names := registry.GetFilterPresetNames()
+	if len(names) != 3 || names[0] != "infantry" || names[1] != "swimmer" || names[2] != "vehicle" {
+		t.Fatalf("unexpected presets %v", names)
+	}
+	vehicle := registry.GetFilterPreset("vehicle")
+	if vehicle.GetIncludeFlags() != 0x01 || vehicle.GetExcludeFlags() != 0x14 ||
+		vehicle.GetAreaCost(0) != 2 || vehicle.GetAreaCost(4) != 4 || vehicle.GetAreaCost(2) != 1 {
+		t.Fatal("unexpected vehicle filter")
+	}
+	// The presets are copied out.
+	vehicle.SetAreaCost(0, 10)
+	if registry.GetFilterPreset("vehicle").GetAreaCost(0) != 2 || registry.GetFilterPreset("tank") != nil {
+		t.Fatal("the preset was changed")
+	}
+
+	// The mesh process sets the flags of the areas, the scene is walkable ground.
+	mesh, _ := LoadDynamicMesh("scene1.obj.tilecache.bin")
+	query := CreateQuery(mesh, PATH_MAX_NODE)
+	halfExtents := [3]float32{2, 4, 2}
+	pos := [3]float32{-800, 0, 100}
+	var nearest [3]float32
+	for _, c := range []struct {
+		preset   string
+		walkable bool
+	}{{"infantry", true}, {"vehicle", true}, {"swimmer", false}} {
+		var ref detour.DtPolyRef
+		query.FindNearestPoly(pos[:], halfExtents[:], registry.GetFilterPreset(c.preset), &ref, nearest[:])
+		if (ref != 0) != c.walkable {
+			t.Fatalf("%s: unexpected polygon %d", c.preset, ref)
+		}
+	}
+
+	// An invalid configuration leaves the registry unchanged.
+	for _, config := range []string{
+		`{"flags": {"walk": 0, "run": 0}}`,
+		`{"flags": {"walk": 16}}`,
+		`{"areas": {"ground": {"id": 0, "flags": ["walk"]}}}`,
+		`{"areas": {"ground": {"id": 64}}}`,
+		`{"filters": {"infantry": {"areaCosts": {"mud": 2}}}}`,
+		`{"flags": {"walk": 0}, "filters": {"infantry": {"exclude": ["swim"]}}}`,
+		`{"flags": "walk"}`,
+	} {
+		if err := registry.LoadJSON([]byte(config)); err == nil {
+			t.Fatalf("invalid configuration loaded: %s", config)
+		}
+		if !registry.FindArea("door", &id) || len(registry.GetFilterPresetNames()) != 3 {
+			t.Fatalf("registry changed by %s", config)
+		}
+	}
+
+	// Names are unique.
+	if !detour.DtStatusFailed(registry.SetArea(6, "water", 0)) || !detour.DtStatusFailed(registry.SetFlag(5, "walk")) {
+		t.Fatal("duplicate name accepted")
+	}
+	if registry.SetArea(6, "mud", 0x01) != detour.DT_SUCCESS || registry.SetFlag(5, "fly") != detour.DT_SUCCESS {
+		t.Fatal("cannot name area or flag")
+	}
+	if !registry.FindArea("mud", &id) || id != 6 || !registry.FindFlag("fly", &flag) || flag != 0x20 {
+		t.Fatal("new names not found")
+	}
+}
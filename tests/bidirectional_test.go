@@ -0,0 +1,189 @@
+package tests
+
+import (
+	"testing"
+
+	detour "github.com/fananchong/recastnavigation-go/Detour"
+)
+
+// findPathSliced runs a sliced path query to the end and returns the path and the iterations used.
+func findPathSliced(t *testing.T, query *detour.DtNavMeshQuery, startRef, endRef detour.DtPolyRef,
+	startPos, endPos []float32, filter *detour.DtQueryFilter, options detour.DtFindPathOptions) ([]detour.DtPolyRef, int) {
+	stat := query.InitSlicedFindPath(startRef, endRef, startPos, endPos, filter, options)
+	iters := 0
+	for detour.DtStatusInProgress(stat) {
+		var n int
+		stat = query.UpdateSlicedFindPath(16, &n)
+		iters += n
+	}
+	path := make([]detour.DtPolyRef, PATH_MAX_NODE)
+	var pathCount int
+	stat = query.FinalizeSlicedFindPath(path, &pathCount, PATH_MAX_NODE)
+	if !detour.DtStatusSucceed(stat) || detour.DtStatusDetail(stat, detour.DT_PARTIAL_RESULT) {
+		t.Fatalf("sliced path failed: 0x%x", stat)
+	}
+	return path[:pathCount], iters
+}
+
+func Test_FindPathBidirectional(t *testing.T) {
+	mesh, _ := LoadDynamicMesh("scene1.obj.tilecache.bin")
+	query := CreateQuery(mesh, 65535)
+	filter := detour.DtAllocDtQueryFilter()
+
+	halfExtents := [3]float32{2, 4, 2}
+	startPos := [3]float32{-800, 0, 100}
+	endPos := [3]float32{-200, 0, 880}
+	var startRef, endRef detour.DtPolyRef
+	query.FindNearestPoly(startPos[:], halfExtents[:], filter, &startRef, startPos[:])
+	query.FindNearestPoly(endPos[:], halfExtents[:], filter, &endRef, endPos[:])
+
+	path, iters := findPathSliced(t, query, startRef, endRef, startPos[:], endPos[:], filter, 0)
+	bidir, bidirIters := findPathSliced(t, query, startRef, endRef, startPos[:], endPos[:], filter, detour.DT_FINDPATH_BIDIRECTIONAL)
+	checkPath(t, mesh, bidir, startRef, endRef)
+
+	length := straightPathLength(query, startPos[:], endPos[:], path)
+	bidirLength := straightPathLength(query, startPos[:], endPos[:], bidir)
+	if bidirLength > length*1.01 {
+		t.Fatalf("bidirectional path is too long: %f > %f", bidirLength, length)
+	}
+	if bidirIters >= iters {
+		t.Fatalf("bidirectional search expanded more nodes: %d >= %d", bidirIters, iters)
+	}
+
+	// The same polygon at both ends.
+	same, _ := findPathSliced(t, query, startRef, startRef, startPos[:], startPos[:], filter, detour.DT_FINDPATH_BIDIRECTIONAL)
+	if len(same) != 1 || same[0] != startRef {
+		t.Fatalf("unexpected path on a single polygon: %v", same)
+	}
+}
+
+// createOneWayMesh creates a single tile U shaped corridor, with a one-way
+// off-mesh connection jumping from the bottom of its left arm to the bottom of
+// its right arm.
+func createOneWayMesh(t *testing.T) *detour.DtNavMesh {
+	// Quads as (minx, minz, maxx, maxz) and their neighbours on the west, north, east and south edges.
+	const NONE = 0xffff
+	quads := [][4]uint16{
+		{0, 0, 10, 10},   // 0: left bottom
+		{20, 0, 30, 10},  // 1: right bottom
+		{0, 10, 10, 30},  // 2: left arm
+		{0, 30, 10, 40},  // 3: left top
+		{10, 30, 20, 40}, // 4: top
+		{20, 30, 30, 40}, // 5: right top
+		{20, 10, 30, 30}, // 6: right arm
+	}
+	neis := [][4]uint16{
+		{NONE, 2, NONE, NONE},
+		{NONE, 6, NONE, NONE},
+		{NONE, 3, NONE, 0},
+		{NONE, NONE, 4, 2},
+		{3, NONE, 5, NONE},
+		{4, NONE, NONE, 6},
+		{NONE, 5, NONE, 1},
+	}
+
+	return createQuadMesh(t, quads, neis, []float32{5, 0, 5, 25, 0, 5})
+}
+
+// createQuadMesh creates a single tile mesh of quads given as (minx, minz, maxx, maxz),
+// with their neighbours on the west, north, east and south edges, and one-way off-mesh
+// connections. [(ax, ay, az, bx, by, bz) * connection count]
+func createQuadMesh(t *testing.T, quads [][4]uint16, neis [][4]uint16, offMeshConVerts []float32) *detour.DtNavMesh {
+	var verts []uint16
+	vert := func(x, z uint16) uint16 {
+		for i := 0; i < len(verts); i += 3 {
+			if verts[i] == x && verts[i+2] == z {
+				return uint16(i / 3)
+			}
+		}
+		verts = append(verts, x, 0, z)
+		return uint16(len(verts)/3 - 1)
+	}
+	var polys []uint16
+	var bmax [3]float32
+	for i, q := range quads {
+		polys = append(polys, vert(q[0], q[1]), vert(q[0], q[3]), vert(q[2], q[3]), vert(q[2], q[1]))
+		polys = append(polys, neis[i][:]...)
+		bmax[0] = detour.DtMaxFloat32(bmax[0], float32(q[2]))
+		bmax[2] = detour.DtMaxFloat32(bmax[2], float32(q[3]))
+	}
+	bmax[1] = 1
+
+	params := detour.DtNavMeshCreateParams{}
+	params.Verts = verts
+	params.VertCount = int32(len(verts) / 3)
+	params.Polys = polys
+	params.PolyCount = int32(len(quads))
+	for range quads {
+		params.PolyFlags = append(params.PolyFlags, 1)
+		params.PolyAreas = append(params.PolyAreas, 0)
+	}
+	params.Nvp = 4
+	params.OffMeshConVerts = offMeshConVerts
+	params.OffMeshConCount = int32(len(offMeshConVerts) / 6)
+	for i := 0; i < int(params.OffMeshConCount); i++ {
+		params.OffMeshConRad = append(params.OffMeshConRad, 1)
+		params.OffMeshConFlags = append(params.OffMeshConFlags, 1)
+		params.OffMeshConAreas = append(params.OffMeshConAreas, 0)
+		params.OffMeshConDir = append(params.OffMeshConDir, 0)
+		params.OffMeshConUserID = append(params.OffMeshConUserID, uint32(i+1))
+	}
+	params.Bmax = bmax
+	params.WalkableHeight = 2
+	params.WalkableRadius = 0.5
+	params.WalkableClimb = 1
+	params.Cs = 1
+	params.Ch = 1
+	params.BuildBvTree = true
+
+	var data []byte
+	var dataSize int
+	if !detour.DtCreateNavMeshData(&params, &data, &dataSize) {
+		t.Fatal("failed to create the navmesh data")
+	}
+	mesh := detour.DtAllocNavMesh()
+	if detour.DtStatusFailed(mesh.Init2(data, dataSize, detour.DT_TILE_FREE_DATA)) {
+		t.Fatal("failed to init the navmesh")
+	}
+	return mesh
+}
+
+func Test_FindPathBidirectionalOneWay(t *testing.T) {
+	mesh := createOneWayMesh(t)
+	query := CreateQuery(mesh, 64)
+	filter := detour.DtAllocDtQueryFilter()
+
+	halfExtents := [3]float32{1, 1, 1}
+	leftPos := [3]float32{5, 0, 5}
+	rightPos := [3]float32{25, 0, 5}
+	var leftRef, rightRef detour.DtPolyRef
+	query.FindNearestPoly(leftPos[:], halfExtents[:], filter, &leftRef, leftPos[:])
+	query.FindNearestPoly(rightPos[:], halfExtents[:], filter, &rightRef, rightPos[:])
+
+	for _, c := range []struct {
+		startRef, endRef detour.DtPolyRef
+		startPos, endPos []float32
+		polys            int
+	}{
+		{leftRef, rightRef, leftPos[:], rightPos[:], 3}, // Jumps over the off-mesh connection.
+		{rightRef, leftRef, rightPos[:], leftPos[:], 7}, // Walks around the corridor.
+	} {
+		var path [PATH_MAX_NODE]detour.DtPolyRef
+		var pathCount int
+		query.FindPath(c.startRef, c.endRef, c.startPos, c.endPos, filter, path[:], &pathCount, PATH_MAX_NODE)
+		if pathCount != c.polys {
+			t.Fatalf("unexpected path length %d, expected %d", pathCount, c.polys)
+		}
+
+		bidir, _ := findPathSliced(t, query, c.startRef, c.endRef, c.startPos, c.endPos, filter, detour.DT_FINDPATH_BIDIRECTIONAL)
+		checkPath(t, mesh, bidir, c.startRef, c.endRef)
+		if len(bidir) != pathCount {
+			t.Fatalf("bidirectional path has %d polygons, expected %d", len(bidir), pathCount)
+		}
+		for i := range bidir {
+			if bidir[i] != path[i] {
+				t.Fatalf("bidirectional path differs at %d", i)
+			}
+		}
+	}
+}
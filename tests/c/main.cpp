@@ -1,17 +1,37 @@
 #include "detour.h"
 #include <cassert>
+#include <stdint.h>
 #include <string>
 #include <vector>
 #include <time.h>
 
 const int RAND_MAX_COUNT = 2000000;
-float randValue[RAND_MAX_COUNT];
-int randIndex = 0;
+
+// Same generator and seed as tests/main_test.go (detour.DtPCG32), so the random
+// positions of the test match without sharing a file.
+const uint64_t PCG_SEED = 42;
+const uint64_t PCG_SEQ = 54;
+uint64_t pcgState = 0;
+uint64_t pcgInc = 0;
+inline uint32_t pcg32()
+{
+    uint64_t old = pcgState;
+    pcgState = old * 6364136223846793005ULL + pcgInc;
+    uint32_t xorshifted = (uint32_t)(((old >> 18u) ^ old) >> 27u);
+    uint32_t rot = (uint32_t)(old >> 59u);
+    return (xorshifted >> rot) | (xorshifted << ((-rot) & 31));
+}
+inline void pcg32Seed(uint64_t seed, uint64_t seq)
+{
+    pcgState = 0;
+    pcgInc = (seq << 1u) | 1u;
+    pcg32();
+    pcgState += seed;
+    pcg32();
+}
 inline float frand()
 {
-    auto v = randValue[randIndex];
-    randIndex++;
-    return v;
+    return (float)(pcg32() >> 8) * (1.0f / 16777216.0f);
 }
 
 const int PATH_MAX_NODE = 2048;
@@ -49,22 +69,12 @@ int main(int argn, char* argv[]) {
     assert(query != nullptr);
     auto filter = dtQueryFilter();
 
-    if (argn > 1 && argv[1] == std::string("rand")) {
-        FILE* f = fopen("../../rand.bin", "wb");
-        for (int i = 0; i < RAND_MAX_COUNT;i++) {
-            float v = (float)rand() / (float)RAND_MAX;
-            fwrite(&v, sizeof(float), 1, f);
-        }
-        fclose(f);
-    }
-    else if (argn > 1 && argv[1] == std::string("randpos")) {
+    if (argn > 1 && argv[1] == std::string("randpos")) {
         void randomPos(dtNavMesh* mesh, dtNavMeshQuery* query, const dtQueryFilter* filter, std::string &nn);
         randomPos(mesh, query, &filter, nn);
     }
     else {
-        FILE* f1 = fopen("../../rand.bin", "rb");
-        fread(randValue, RAND_MAX_COUNT * sizeof(float), 1, f1);
-        fclose(f1);
+        pcg32Seed(PCG_SEED, PCG_SEQ);
         int test(dtNavMesh* mesh, dtNavMeshQuery* query, const dtQueryFilter* filter);
         test(mesh, query, &filter);
         FILE* f2 = fopen("../../result.bin", "wb");
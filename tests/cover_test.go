@@ -0,0 +1,91 @@
+package tests
+
+import (
+	"testing"
+
+	detour "github.com/fananchong/recastnavigation-go/Detour"
+	dtcache "github.com/fananchong/recastnavigation-go/DetourTileCache"
+)
+
+func Test_FindCoverPoints(t *testing.T) {
+	mesh, tileCache := LoadDynamicMesh("scene1.obj.tilecache.bin")
+	query := CreateQuery(mesh, PATH_MAX_NODE)
+	filter := detour.DtAllocDtQueryFilter()
+
+	// A wall between the threat and the search circle.
+	for x := float32(-810); x < -690; x += 10 {
+		bmin := [3]float32{x, -100, 450}
+		bmax := [3]float32{x + 11, 100, 455}
+		var wall dtcache.DtObstacleRef
+		if stat := tileCache.AddBoxObstacle(bmin[:], bmax[:], &wall); detour.DtStatusFailed(stat) {
+			t.Fatalf("add obstacle: %x", stat)
+		}
+		for upToDate := false; !upToDate; {
+			tileCache.Update(0, mesh, &upToDate)
+		}
+	}
+
+	halfExtents := [3]float32{2, 4, 2}
+	centerPos := [3]float32{-750, 0, 462}
+	threatPos := [3]float32{-750, 0, 400}
+	var centerRef detour.DtPolyRef
+	query.FindNearestPoly(centerPos[:], halfExtents[:], filter, &centerRef, centerPos[:])
+	if centerRef == 0 {
+		t.Fatal("no polygon at the center")
+	}
+
+	const radius = 10
+	const agentRadius = 0.5
+	var points [64]detour.DtCoverPoint
+	var pointCount int
+	stat := query.FindCoverPoints(centerRef, centerPos[:], radius, threatPos[:], agentRadius, 1, filter, points[:], &pointCount, len(points))
+	if stat != detour.DT_SUCCESS || pointCount == 0 {
+		t.Fatalf("no cover found: 0x%x", stat)
+	}
+	for i := 0; i < pointCount; i++ {
+		p := &points[i]
+		if p.Pos[2] < 455 {
+			t.Fatalf("point %d is on the threat side: %v", i, p.Pos)
+		}
+		if d := detour.DtVdist2D(p.Pos[:], centerPos[:]); d > radius {
+			t.Fatalf("point %d is outside the search circle: %f", i, d)
+		}
+		if p.Score <= 0 || p.Score > 1 || (i > 0 && p.Score > points[i-1].Score) {
+			t.Fatalf("point %d has a bad score %f", i, p.Score)
+		}
+		var closest [3]float32
+		query.ClosestPointOnPoly(p.Ref, p.Pos[:], closest[:], nil)
+		if detour.DtVdist2D(closest[:], p.Pos[:]) > 1e-3 {
+			t.Fatalf("point %d is not on its polygon", i)
+		}
+		var hit float32
+		var n int
+		query.Raycast(p.Ref, p.Pos[:], threatPos[:], filter, &hit, nil, nil, &n, 0)
+		if hit >= 1 {
+			t.Fatalf("point %d can be seen from the threat", i)
+		}
+	}
+	// The wall right in front of the threat gives the best cover.
+	if points[0].Score < 0.99 || points[0].Normal[2] < 0.99 {
+		t.Fatalf("unexpected best cover %+v", points[0])
+	}
+
+	// The best points are kept when the array is too small.
+	var best [2]detour.DtCoverPoint
+	stat = query.FindCoverPoints(centerRef, centerPos[:], radius, threatPos[:], agentRadius, 1, filter, best[:], &pointCount, len(best))
+	if stat != detour.DT_SUCCESS|detour.DT_BUFFER_TOO_SMALL || pointCount != 2 || best[0] != points[0] || best[1] != points[1] {
+		t.Fatalf("unexpected short result: 0x%x %d", stat, pointCount)
+	}
+
+	// Nothing hides from a threat standing in the open circle.
+	stat = query.FindCoverPoints(centerRef, centerPos[:], radius, centerPos[:], agentRadius, 1, filter, points[:], &pointCount, len(points))
+	if stat != detour.DT_SUCCESS || pointCount != 0 {
+		t.Fatalf("unexpected cover from the center: 0x%x %d", stat, pointCount)
+	}
+
+	// Searching more polygons than fit in the polygon buffer is reported.
+	stat = query.FindCoverPoints(centerRef, centerPos[:], 200, threatPos[:], agentRadius, 1, filter, points[:], &pointCount, len(points))
+	if detour.DtStatusFailed(stat) || !detour.DtStatusDetail(stat, detour.DT_BUFFER_TOO_SMALL) {
+		t.Fatalf("unexpected status for a large circle: 0x%x", stat)
+	}
+}
@@ -0,0 +1,237 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	debugutils "github.com/fananchong/recastnavigation-go/DebugUtils"
+	detour "github.com/fananchong/recastnavigation-go/Detour"
+)
+
+func countPolys(mesh *detour.DtNavMesh) (polys int, detailTris int) {
+	for i := 0; i < int(mesh.GetMaxTiles()); i++ {
+		tile := mesh.GetTile(i)
+		if tile == nil || tile.Header == nil {
+			continue
+		}
+		for j := 0; j < int(tile.Header.PolyCount); j++ {
+			if tile.Polys[j].GetType() != detour.DT_POLYTYPE_GROUND {
+				continue
+			}
+			polys++
+			detailTris += int(tile.DetailMeshes[j].TriCount)
+		}
+	}
+	return
+}
+
+func Test_DumpNavMeshOBJ(t *testing.T) {
+	mesh, _ := LoadDynamicMesh("scene1.obj.tilecache.bin")
+	polys, detailTris := countPolys(mesh)
+
+	var buf bytes.Buffer
+	if err := debugutils.DumpNavMeshOBJ(&buf, mesh); err != nil {
+		t.Fatal(err)
+	}
+	var faces int
+	for _, line := range strings.Split(buf.String(), "\n") {
+		if strings.HasPrefix(line, "f ") {
+			faces++
+		}
+	}
+	if faces != polys+detailTris {
+		t.Fatalf("faces: %d, want %d", faces, polys+detailTris)
+	}
+}
+
+func Test_DumpNavMeshGLTF(t *testing.T) {
+	mesh, _ := LoadDynamicMesh("scene1.obj.tilecache.bin")
+	_, detailTris := countPolys(mesh)
+
+	var buf bytes.Buffer
+	if err := debugutils.DumpNavMeshGLTF(&buf, mesh); err != nil {
+		t.Fatal(err)
+	}
+	var doc struct {
+		Asset struct {
+			Version string `json:"version"`
+		} `json:"asset"`
+		Nodes []struct {
+			Name string `json:"name"`
+		} `json:"nodes"`
+		Accessors []struct {
+			Count int    `json:"count"`
+			Type  string `json:"type"`
+		} `json:"accessors"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatal(err)
+	}
+	if doc.Asset.Version != "2.0" {
+		t.Fatalf("version: %s", doc.Asset.Version)
+	}
+	if len(doc.Nodes) != 2 || doc.Nodes[0].Name != "polys" || doc.Nodes[1].Name != "detail" {
+		t.Fatalf("unexpected nodes: %v", doc.Nodes)
+	}
+	// polys: position, color, indices; detail: position, color.
+	if len(doc.Accessors) != 5 || doc.Accessors[3].Count != detailTris*3 {
+		t.Fatalf("unexpected accessors: %v", doc.Accessors)
+	}
+}
+
+func Test_DumpNavMeshGLTFOffMesh(t *testing.T) {
+	mesh := createOneWayMesh(t)
+
+	var buf bytes.Buffer
+	if err := debugutils.DumpNavMeshGLTF(&buf, mesh); err != nil {
+		t.Fatal(err)
+	}
+	var doc struct {
+		Nodes []struct {
+			Name string `json:"name"`
+			Mesh int    `json:"mesh"`
+		} `json:"nodes"`
+		Meshes []struct {
+			Primitives []struct {
+				Attributes map[string]int `json:"attributes"`
+				Mode       int            `json:"mode"`
+			} `json:"primitives"`
+		} `json:"meshes"`
+		Accessors []struct {
+			Count int       `json:"count"`
+			Type  string    `json:"type"`
+			Min   []float32 `json:"min"`
+			Max   []float32 `json:"max"`
+		} `json:"accessors"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatal(err)
+	}
+	if len(doc.Nodes) != 3 || doc.Nodes[2].Name != "offmesh" {
+		t.Fatalf("unexpected nodes: %v", doc.Nodes)
+	}
+	// The connection is a line between its end points.
+	prim := doc.Meshes[doc.Nodes[2].Mesh].Primitives[0]
+	if prim.Mode != 1 {
+		t.Fatalf("offmesh mode: %d", prim.Mode)
+	}
+	pos := doc.Accessors[prim.Attributes["POSITION"]]
+	if pos.Count != 2 || pos.Type != "VEC3" || len(pos.Min) != 3 || len(pos.Max) != 3 ||
+		pos.Min[0] != 5 || pos.Max[0] != 25 || pos.Min[2] != 5 || pos.Max[2] != 5 {
+		t.Fatalf("unexpected offmesh positions: %+v", pos)
+	}
+	if col := doc.Accessors[prim.Attributes["COLOR_0"]]; col.Count != 2 {
+		t.Fatalf("unexpected offmesh colors: %+v", col)
+	}
+}
+
+func Test_RenderNavMesh(t *testing.T) {
+	mesh, _ := LoadDynamicMesh("scene1.obj.tilecache.bin")
+	query := CreateQuery(mesh, PATH_MAX_NODE)
+	filter := detour.DtAllocDtQueryFilter()
+
+	halfExtents := [3]float32{2, 4, 2}
+	startPos := [3]float32{-800, 0, 100}
+	endPos := [3]float32{-200, 0, 880}
+	var startRef, endRef detour.DtPolyRef
+	query.FindNearestPoly(startPos[:], halfExtents[:], filter, &startRef, startPos[:])
+	query.FindNearestPoly(endPos[:], halfExtents[:], filter, &endRef, endPos[:])
+	if startRef == 0 || endRef == 0 {
+		t.Fatal("no start or end poly")
+	}
+
+	var path [PATH_MAX_NODE]detour.DtPolyRef
+	var pathCount int
+	query.FindPath(startRef, endRef, startPos[:], endPos[:], filter, path[:], &pathCount, PATH_MAX_NODE)
+	var straightPath [PATH_MAX_NODE * 3]float32
+	var straightPathFlags [PATH_MAX_NODE]detour.DtStraightPathFlags
+	var straightPathRefs [PATH_MAX_NODE]detour.DtPolyRef
+	var straightPathCount int
+	query.FindStraightPath(startPos[:], endPos[:], path[:], pathCount,
+		straightPath[:], straightPathFlags[:], straightPathRefs[:], &straightPathCount, PATH_MAX_NODE, 0)
+
+	dl := debugutils.DuAllocDisplayList()
+	debugutils.DuDebugDrawNavMesh(dl, mesh, debugutils.DU_DRAWNAVMESH_OFFMESHCONS|debugutils.DU_DRAWNAVMESH_TILE_BOUNDS)
+	debugutils.DuDebugDrawPathPolys(dl, mesh, path[:], pathCount, debugutils.DuRGBA(255, 0, 0, 255))
+	debugutils.DuDebugDrawStraightPath(dl, straightPath[:], straightPathFlags[:], straightPathCount, debugutils.DuRGBA(64, 16, 0, 220))
+
+	params := debugutils.DuDefaultRenderParams()
+	params.Width = 256
+	params.Bmin = [3]float32{-1000, 0, 0}
+	params.Bmax = [3]float32{0, 60, 500}
+	img := dl.Rasterize(params)
+	if img.Rect.Dx() != 256 || img.Rect.Dy() != 2*params.Margin+120 {
+		t.Fatalf("unexpected image size: %v", img.Rect)
+	}
+	drawn := 0
+	for i := 0; i < len(img.Pix); i += 4 {
+		if img.Pix[i] != 255 || img.Pix[i+1] != 255 || img.Pix[i+2] != 255 {
+			drawn++
+		}
+	}
+	if drawn == 0 {
+		t.Fatal("nothing was drawn")
+	}
+
+	var png, svg bytes.Buffer
+	if err := dl.WritePNG(&png, params); err != nil {
+		t.Fatal(err)
+	}
+	if err := dl.WriteSVG(&svg, params); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(svg.String(), "<svg") || !strings.Contains(svg.String(), "<polygon") {
+		t.Fatal("unexpected svg output")
+	}
+}
+
+func Test_DumpNavMeshNodes(t *testing.T) {
+	mesh, _ := LoadDynamicMesh("scene1.obj.tilecache.bin")
+	query := CreateQuery(mesh, 64)
+	filter := detour.DtAllocDtQueryFilter()
+
+	halfExtents := [3]float32{2, 4, 2}
+	startPos := [3]float32{-800, 0, 100}
+	endPos := [3]float32{-200, 0, 880}
+	var startRef, endRef detour.DtPolyRef
+	query.FindNearestPoly(startPos[:], halfExtents[:], filter, &startRef, startPos[:])
+	query.FindNearestPoly(endPos[:], halfExtents[:], filter, &endRef, endPos[:])
+
+	var path [PATH_MAX_NODE]detour.DtPolyRef
+	var pathCount int
+	stat := query.FindPath(startRef, endRef, startPos[:], endPos[:], filter, path[:], &pathCount, PATH_MAX_NODE)
+	if !detour.DtStatusDetail(stat, detour.DT_PARTIAL_RESULT) {
+		t.Fatalf("expected a partial result, got %x", stat)
+	}
+
+	info := debugutils.DuGetNavMeshNodes(query)
+	if info.NodeCount != info.MaxNodes || len(info.Nodes) != int(info.NodeCount) || info.Closed == 0 {
+		t.Fatalf("unexpected node pool info: max %d, count %d, closed %d", info.MaxNodes, info.NodeCount, info.Closed)
+	}
+	for _, n := range info.Nodes {
+		if n.Ref == startRef && n.Parent != 0 {
+			t.Fatal("start node has a parent")
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := debugutils.DuDumpNavMeshNodesJSON(&buf, query); err != nil {
+		t.Fatal(err)
+	}
+	var decoded debugutils.DuNodePoolInfo
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if len(decoded.Nodes) != len(info.Nodes) {
+		t.Fatalf("decoded %d nodes, want %d", len(decoded.Nodes), len(info.Nodes))
+	}
+
+	dl := debugutils.DuAllocDisplayList()
+	debugutils.DuDebugDrawNavMeshWithClosedList(dl, mesh, query, debugutils.DU_DRAWNAVMESH_CLOSEDLIST)
+	debugutils.DuDebugDrawNavMeshNodes(dl, query)
+	if err := dl.WriteSVG(&buf, nil); err != nil {
+		t.Fatal(err)
+	}
+}
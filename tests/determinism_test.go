@@ -0,0 +1,126 @@
+package tests
+
+import (
+	"hash"
+	"hash/fnv"
+	"math"
+	"runtime"
+	"testing"
+
+	detour "github.com/fananchong/recastnavigation-go/Detour"
+)
+
+// The hash of the results of the queries of queryHash, the same on all GOARCHs
+// when built with the deterministic tag.
+const GOLDEN_QUERY_HASH uint64 = 0xae496154085a5148
+
+type queryHasher struct {
+	h   hash.Hash64
+	buf [4]byte
+}
+
+func (this *queryHasher) uint32(v uint32) {
+	this.buf[0] = byte(v)
+	this.buf[1] = byte(v >> 8)
+	this.buf[2] = byte(v >> 16)
+	this.buf[3] = byte(v >> 24)
+	this.h.Write(this.buf[:])
+}
+
+func (this *queryHasher) floats(v []float32) {
+	for _, f := range v {
+		this.uint32(math.Float32bits(f))
+	}
+}
+
+func (this *queryHasher) refs(v []detour.DtPolyRef) {
+	for _, r := range v {
+		this.uint32(uint32(r))
+	}
+}
+
+// queryHash runs FindNearestPoly, FindPath, FindStraightPath, MoveAlongSurface,
+// Raycast and GetPolyHeight over a grid of points, and hashes the bits of their results.
+func queryHash(query *detour.DtNavMeshQuery) uint64 {
+	filter := detour.DtAllocDtQueryFilter()
+	halfExtents := [3]float32{10, 20, 10}
+
+	var refs []detour.DtPolyRef
+	var points []float32
+	for x := -900; x <= -100; x += 100 {
+		for z := 0; z <= 900; z += 100 {
+			center := [3]float32{float32(x) + 0.37, 0, float32(z) + 0.61}
+			var ref detour.DtPolyRef
+			var pt [3]float32
+			if detour.DtStatusSucceed(query.FindNearestPoly(center[:], halfExtents[:], filter, &ref, pt[:])) && ref != 0 {
+				refs = append(refs, ref)
+				points = append(points, pt[:]...)
+			}
+		}
+	}
+
+	h := &queryHasher{h: fnv.New64a()}
+	h.refs(refs)
+	h.floats(points)
+
+	var path [PATH_MAX_NODE]detour.DtPolyRef
+	var straight [PATH_MAX_NODE * 3]float32
+	var flags [PATH_MAX_NODE]detour.DtStraightPathFlags
+	var straightRefs [PATH_MAX_NODE]detour.DtPolyRef
+	var visited [16]detour.DtPolyRef
+	for i := range refs {
+		j := (i*7 + 3) % len(refs)
+		startPos, endPos := points[i*3:i*3+3], points[j*3:j*3+3]
+
+		var pathCount int
+		stat := query.FindPath(refs[i], refs[j], startPos, endPos, filter, path[:], &pathCount, PATH_MAX_NODE)
+		h.uint32(uint32(stat))
+		h.refs(path[:pathCount])
+
+		var straightCount int
+		stat = query.FindStraightPath(startPos, endPos, path[:], pathCount, straight[:], flags[:], straightRefs[:],
+			&straightCount, PATH_MAX_NODE, detour.DT_STRAIGHTPATH_ALL_CROSSINGS)
+		h.uint32(uint32(stat))
+		h.floats(straight[:straightCount*3])
+		h.refs(straightRefs[:straightCount])
+
+		var resultPos [3]float32
+		var visitedCount int
+		var bHit bool
+		stat = query.MoveAlongSurface(refs[i], startPos, endPos, filter, resultPos[:], visited[:], &visitedCount, len(visited), &bHit)
+		h.uint32(uint32(stat))
+		h.floats(resultPos[:])
+		h.refs(visited[:visitedCount])
+
+		var height float32
+		if visitedCount > 0 && detour.DtStatusSucceed(query.GetPolyHeight(visited[visitedCount-1], resultPos[:], &height)) {
+			h.floats([]float32{height})
+		}
+
+		var t float32
+		var hitNormal [3]float32
+		stat = query.Raycast(refs[i], startPos, endPos, filter, &t, hitNormal[:], path[:], &pathCount, PATH_MAX_NODE)
+		h.uint32(uint32(stat))
+		h.floats([]float32{t})
+		h.floats(hitNormal[:])
+		h.refs(path[:pathCount])
+	}
+	return h.h.Sum64()
+}
+
+func Test_DeterministicQueries(t *testing.T) {
+	mesh, _ := LoadDynamicMesh("scene1.obj.tilecache.bin")
+	query := CreateQuery(mesh, 65535)
+
+	hash := queryHash(query)
+	if hash != queryHash(query) {
+		t.Fatal("the same queries gave different results")
+	}
+	t.Logf("query hash on %s: 0x%016x", runtime.GOARCH, hash)
+	if !detour.DT_DETERMINISTIC {
+		t.Skip("the golden hash is only checked when built with the deterministic tag")
+	}
+	if hash != GOLDEN_QUERY_HASH {
+		t.Fatalf("query hash 0x%016x differs from the golden hash 0x%016x", hash, GOLDEN_QUERY_HASH)
+	}
+}
@@ -0,0 +1,267 @@
+package tests
+
+import (
+	"math"
+	"testing"
+
+	detour "github.com/fananchong/recastnavigation-go/Detour"
+)
+
+// The documented tolerance of the fixed-point query against the float one.
+const FIXED_POS_TOLERANCE float32 = 1.0 / 1024
+const FIXED_T_TOLERANCE float32 = 1.0 / 16384
+
+func toFixed(v []float32) []detour.DtFixed {
+	r := make([]detour.DtFixed, len(v))
+	for i := range v {
+		r[i] = detour.DtFixedFromFloat(v[i])
+	}
+	return r
+}
+
+func maxFixedError(f []float32, x []detour.DtFixed) float32 {
+	var e float32
+	for i := range f {
+		if d := float32(math.Abs(float64(f[i] - detour.DtFixedToFloat(x[i])))); d > e {
+			e = d
+		}
+	}
+	return e
+}
+
+// The cost of a corridor as the A* search sees it: the length of the line
+// through the middles of its portals.
+func corridorCost(mesh *detour.DtNavMesh, path []detour.DtPolyRef, startPos, endPos []float32) float64 {
+	prev := startPos
+	var cost float64
+	for k := 1; k < len(path); k++ {
+		var tile *detour.DtMeshTile
+		var poly *detour.DtPoly
+		mesh.GetTileAndPolyByRef(path[k-1], &tile, &poly)
+		for l := poly.FirstLink; l != detour.DT_NULL_LINK; l = tile.Links[l].Next {
+			link := &tile.Links[l]
+			if link.Ref != path[k] {
+				continue
+			}
+			v0 := tile.Verts[poly.Verts[link.Edge]*3:]
+			v1 := tile.Verts[poly.Verts[(int(link.Edge)+1)%int(poly.VertCount)]*3:]
+			var left, right, mid [3]float32
+			detour.DtVlerp(left[:], v0, v1, float32(link.Bmin)/255)
+			detour.DtVlerp(right[:], v0, v1, float32(link.Bmax)/255)
+			if link.Side == 0xff {
+				detour.DtVcopy(left[:], v0)
+				detour.DtVcopy(right[:], v1)
+			}
+			detour.DtVlerp(mid[:], left[:], right[:], 0.5)
+			cost += float64(detour.DtVdist(prev, mid[:]))
+			prev = mid[:]
+			break
+		}
+	}
+	return cost + float64(detour.DtVdist(prev, endPos))
+}
+
+// Merges the consecutive points of a straight path closer than the tolerance.
+// A crossing at a corner of the path may land on either side of the threshold
+// under which detour merges the points.
+func mergeStraightPath(pts []float32, flags []detour.DtStraightPathFlags) ([]float32, []detour.DtStraightPathFlags) {
+	var rpts []float32
+	var rflags []detour.DtStraightPathFlags
+	for k := range flags {
+		n := len(rflags)
+		if n > 0 && detour.DtVdist(rpts[(n-1)*3:], pts[k*3:k*3+3]) < FIXED_POS_TOLERANCE {
+			rflags[n-1] |= flags[k]
+			continue
+		}
+		rpts = append(rpts, pts[k*3:k*3+3]...)
+		rflags = append(rflags, flags[k])
+	}
+	return rpts, rflags
+}
+
+func Test_FixedMath(t *testing.T) {
+	for _, c := range []struct {
+		f float32
+		x detour.DtFixed
+	}{
+		{0, 0},
+		{1, detour.DT_FIXED_ONE},
+		{-1, -detour.DT_FIXED_ONE},
+		{0.5, detour.DT_FIXED_HALF},
+		{-812.25, -812*detour.DT_FIXED_ONE - detour.DT_FIXED_ONE/4},
+		{1.0 / 131072, 1},
+		{1.0 / 262144, 0},
+		{1e-40, 0},
+		{1e10, detour.DT_FIXED_MAX},
+		{-1e10, -detour.DT_FIXED_MAX},
+		{float32(math.Inf(-1)), detour.DT_FIXED_MIN},
+	} {
+		if x := detour.DtFixedFromFloat(c.f); x != c.x {
+			t.Fatalf("%g converted to %d, expected %d", c.f, x, c.x)
+		}
+	}
+	for _, f := range []float32{0.1, -0.3, 123.456, -7999.99, 3.0 / 7} {
+		if e := math.Abs(float64(detour.DtFixedToFloat(detour.DtFixedFromFloat(f)) - f)); e > 1.0/131072+1e-6*math.Abs(float64(f)) {
+			t.Fatalf("%g converted with an error of %g", f, e)
+		}
+	}
+	three := detour.DtFixedFromInt(3)
+	if detour.DtFixedMul(three, detour.DT_FIXED_HALF) != three/2 || detour.DtFixedDiv(three, detour.DtFixedFromInt(2)) != three/2 {
+		t.Fatal("unexpected product or quotient")
+	}
+	if detour.DtFixedSqrt(detour.DtFixedFromInt(9)) != three || detour.DtFixedSqrt(-three) != 0 {
+		t.Fatal("unexpected square root")
+	}
+}
+
+func Test_FixedQueries(t *testing.T) {
+	mesh, _ := LoadDynamicMesh("scene1.obj.tilecache.bin")
+	query := CreateQuery(mesh, PATH_MAX_NODE)
+	fixed := detour.DtAllocNavMeshQueryFixed()
+	if !detour.DtStatusSucceed(fixed.Init(mesh, PATH_MAX_NODE)) {
+		t.Fatal("fixed query init failed")
+	}
+	defer detour.DtFreeNavMeshQueryFixed(fixed)
+	filter := detour.DtAllocDtQueryFilter()
+	halfExtents := [3]float32{10, 20, 10}
+
+	// The nearest polygons of a grid of points.
+	var refs []detour.DtPolyRef
+	var points []float32
+	var fpoints []detour.DtFixed
+	var maxPosErr, maxTErr float32
+	for x := -900; x <= -100; x += 50 {
+		for z := 0; z <= 900; z += 50 {
+			center := [3]float32{float32(x) + 0.37, 0, float32(z) + 0.61}
+			var ref, fref detour.DtPolyRef
+			var pt [3]float32
+			fpt := make([]detour.DtFixed, 3)
+			query.FindNearestPoly(center[:], halfExtents[:], filter, &ref, pt[:])
+			stat := fixed.FindNearestPoly(toFixed(center[:]), toFixed(halfExtents[:]), filter, &fref, fpt)
+			if !detour.DtStatusSucceed(stat) || fref != ref {
+				t.Fatalf("nearest poly of %v is %d, expected %d", center, fref, ref)
+			}
+			if ref == 0 {
+				continue
+			}
+			if e := maxFixedError(pt[:], fpt); e > maxPosErr {
+				maxPosErr = e
+			}
+			refs = append(refs, ref)
+			points = append(points, pt[:]...)
+			fpoints = append(fpoints, fpt...)
+		}
+	}
+	if len(refs) < 100 {
+		t.Fatalf("only %d points on the mesh", len(refs))
+	}
+
+	var path, fpath [PATH_MAX_NODE]detour.DtPolyRef
+	var straight [PATH_MAX_NODE * 3]float32
+	var fstraight [PATH_MAX_NODE * 3]detour.DtFixed
+	var flags, fflags [PATH_MAX_NODE]detour.DtStraightPathFlags
+	var visited, fvisited [16]detour.DtPolyRef
+	completePaths, samePaths := 0, 0
+	for i := range refs {
+		j := (i*7 + 3) % len(refs)
+		startPos, endPos := points[i*3:i*3+3], points[j*3:j*3+3]
+		fstartPos, fendPos := fpoints[i*3:i*3+3], fpoints[j*3:j*3+3]
+
+		// Paths.
+		var pathCount, fpathCount int
+		stat := query.FindPath(refs[i], refs[j], startPos, endPos, filter, path[:], &pathCount, PATH_MAX_NODE)
+		fstat := fixed.FindPath(refs[i], refs[j], fstartPos, fendPos, filter, fpath[:], &fpathCount, PATH_MAX_NODE)
+		if fstat != stat {
+			t.Fatalf("path %d: status 0x%x, expected 0x%x", i, fstat, stat)
+		}
+		if detour.DtStatusDetail(stat, detour.DT_OUT_OF_NODES) {
+			// Which polygons a search reaches before running out of nodes depends on the order of the ties.
+			continue
+		}
+		if fpath[fpathCount-1] != path[pathCount-1] {
+			t.Fatalf("path %d: ends at %d, expected %d", i, fpath[fpathCount-1], path[pathCount-1])
+		}
+		completePaths++
+		same := fpathCount == pathCount
+		for k := 0; same && k < pathCount; k++ {
+			same = fpath[k] == path[k]
+		}
+		if same {
+			samePaths++
+		} else if a, b := corridorCost(mesh, path[:pathCount], startPos, endPos), corridorCost(mesh, fpath[:fpathCount], startPos, endPos); math.Abs(a-b) > 1e-5*a {
+			t.Fatalf("path %d costs %f, expected %f", i, b, a)
+		}
+
+		// Straight paths along the float corridor.
+		var straightCount, fstraightCount int
+		stat = query.FindStraightPath(startPos, endPos, path[:], pathCount, straight[:], flags[:], nil,
+			&straightCount, PATH_MAX_NODE, detour.DT_STRAIGHTPATH_ALL_CROSSINGS)
+		fstat = fixed.FindStraightPath(fstartPos, fendPos, path[:], pathCount, fstraight[:], fflags[:], nil,
+			&fstraightCount, PATH_MAX_NODE, detour.DT_STRAIGHTPATH_ALL_CROSSINGS)
+		if fstat != stat {
+			t.Fatalf("straight path %d: status 0x%x, expected 0x%x", i, fstat, stat)
+		}
+		fpts := make([]float32, fstraightCount*3)
+		for k := 0; k < fstraightCount; k++ {
+			detour.DtVfromFixed(fpts[k*3:], fstraight[k*3:])
+		}
+		pts, ptFlags := mergeStraightPath(straight[:straightCount*3], flags[:straightCount])
+		fpts, fptFlags := mergeStraightPath(fpts, fflags[:fstraightCount])
+		if len(fpts) != len(pts) {
+			t.Fatalf("straight path %d: %d points, expected %d", i, len(fpts)/3, len(pts)/3)
+		}
+		for k := range ptFlags {
+			if fptFlags[k] != ptFlags[k] {
+				t.Fatalf("straight path %d: flags %v, expected %v", i, fptFlags, ptFlags)
+			}
+		}
+		for k := range pts {
+			if e := float32(math.Abs(float64(pts[k] - fpts[k]))); e > maxPosErr {
+				maxPosErr = e
+			}
+		}
+
+		// Moves.
+		var resultPos [3]float32
+		fresultPos := make([]detour.DtFixed, 3)
+		var visitedCount, fvisitedCount int
+		var bHit, fbHit bool
+		query.MoveAlongSurface(refs[i], startPos, endPos, filter, resultPos[:], visited[:], &visitedCount, len(visited), &bHit)
+		fixed.MoveAlongSurface(refs[i], fstartPos, fendPos, filter, fresultPos, fvisited[:], &fvisitedCount, len(fvisited), &fbHit)
+		if fvisitedCount != visitedCount || fbHit != bHit || (visitedCount > 0 && fvisited[visitedCount-1] != visited[visitedCount-1]) {
+			t.Fatalf("move %d: %v, expected %v", i, fvisited[:fvisitedCount], visited[:visitedCount])
+		}
+		if e := maxFixedError(resultPos[:], fresultPos); e > maxPosErr {
+			maxPosErr = e
+		}
+
+		// Raycasts.
+		var hitT float32
+		var hitNormal [3]float32
+		var ft detour.DtFixed
+		fhitNormal := make([]detour.DtFixed, 3)
+		query.Raycast(refs[i], startPos, endPos, filter, &hitT, hitNormal[:], path[:], &pathCount, PATH_MAX_NODE)
+		fixed.Raycast(refs[i], fstartPos, fendPos, filter, &ft, fhitNormal, fpath[:], &fpathCount, PATH_MAX_NODE)
+		// A wall hit within the tolerance of the end position counts as reaching it.
+		reached := hitT >= 1-FIXED_T_TOLERANCE
+		freached := ft >= detour.DT_FIXED_ONE-detour.DtFixedFromFloat(FIXED_T_TOLERANCE)
+		if fpathCount != pathCount || freached != reached {
+			t.Fatalf("raycast %d: t %f over %d polygons, expected %f over %d", i, detour.DtFixedToFloat(ft), fpathCount, hitT, pathCount)
+		}
+		if !reached {
+			if e := maxFixedError([]float32{hitT}, []detour.DtFixed{ft}); e > maxTErr {
+				maxTErr = e
+			}
+			if e := maxFixedError(hitNormal[:], fhitNormal); e > maxPosErr {
+				maxPosErr = e
+			}
+		}
+	}
+	t.Logf("%d of %d paths are the same, position error %g, t error %g", samePaths, completePaths, maxPosErr, maxTErr)
+	if completePaths < 100 {
+		t.Fatalf("only %d complete paths", completePaths)
+	}
+	if maxPosErr > FIXED_POS_TOLERANCE || maxTErr > FIXED_T_TOLERANCE {
+		t.Fatalf("position error %g or t error %g is out of tolerance", maxPosErr, maxTErr)
+	}
+}
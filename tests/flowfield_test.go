@@ -0,0 +1,157 @@
+package tests
+
+import (
+	"math"
+	"testing"
+
+	detour "github.com/fananchong/recastnavigation-go/Detour"
+	dtcache "github.com/fananchong/recastnavigation-go/DetourTileCache"
+)
+
+// checkSameFlow fails if the two fields do not give the same cost for every polygon.
+func checkSameFlow(t *testing.T, mesh *detour.DtNavMesh, a, b *detour.DtFlowField) {
+	reached := 0
+	for i := 0; i < int(mesh.GetMaxTiles()); i++ {
+		tile := mesh.GetTile(i)
+		if tile == nil || tile.Header == nil {
+			continue
+		}
+		base := mesh.GetPolyRefBase(tile)
+		for j := 0; j < int(tile.Header.PolyCount); j++ {
+			ref := base | detour.DtPolyRef(j)
+			// The cost is sampled at the first vertex of the polygon.
+			pos := tile.Verts[tile.Polys[j].Verts[0]*3:]
+			var ca, cb float32
+			sa := a.Sample(ref, pos, nil, nil, &ca)
+			sb := b.Sample(ref, pos, nil, nil, &cb)
+			if detour.DtStatusSucceed(sa) != detour.DtStatusSucceed(sb) {
+				t.Fatalf("poly %d: reachable %v, want %v", ref, detour.DtStatusSucceed(sa), detour.DtStatusSucceed(sb))
+			}
+			if detour.DtStatusSucceed(sa) {
+				reached++
+				if math.Abs(float64(ca-cb)) > float64(cb)*1e-4+1e-3 {
+					t.Fatalf("poly %d: cost %f, want %f", ref, ca, cb)
+				}
+			}
+		}
+	}
+	if reached == 0 {
+		t.Fatal("no polygon reaches the goal")
+	}
+}
+
+func Test_FlowField(t *testing.T) {
+	mesh, tileCache := LoadDynamicMesh("scene1.obj.tilecache.bin")
+	query := CreateQuery(mesh, 65535)
+	filter := detour.DtAllocDtQueryFilter()
+
+	halfExtents := [3]float32{2, 4, 2}
+	startPos := [3]float32{-800, 0, 100}
+	goalPos := [3]float32{-200, 0, 880}
+	var startRef, goalRef detour.DtPolyRef
+	query.FindNearestPoly(startPos[:], halfExtents[:], filter, &startRef, startPos[:])
+	query.FindNearestPoly(goalPos[:], halfExtents[:], filter, &goalRef, goalPos[:])
+
+	field := detour.DtAllocFlowField(mesh, filter)
+	defer detour.DtFreeFlowField(field)
+	if stat := field.SetGoal(goalRef, goalPos[:]); detour.DtStatusFailed(stat) {
+		t.Fatalf("SetGoal: %x", stat)
+	}
+
+	// Following the field from the start leads to the goal at a decreasing cost.
+	followField := func() float32 {
+		var startCost float32
+		ref := startRef
+		pos := startPos
+		lastCost := float32(math.MaxFloat32)
+		for steps := 0; ref != field.GetGoalRef(); steps++ {
+			var next detour.DtPolyRef
+			var target [3]float32
+			var cost float32
+			if stat := field.Sample(ref, pos[:], &next, target[:], &cost); detour.DtStatusFailed(stat) || steps > 10000 {
+				t.Fatalf("step %d: %x", steps, stat)
+			}
+			if cost > lastCost {
+				t.Fatalf("step %d: cost %f increases from %f", steps, cost, lastCost)
+			}
+			if steps == 0 {
+				startCost = cost
+			}
+			ref, pos, lastCost = next, target, cost
+		}
+		return startCost
+	}
+	openCost := followField()
+
+	// The field is at least as good as the corridor found by FindPath.
+	var path [PATH_MAX_NODE]detour.DtPolyRef
+	var pathCount int
+	query.FindPath(startRef, goalRef, startPos[:], goalPos[:], filter, path[:], &pathCount, PATH_MAX_NODE)
+	var straight [PATH_MAX_NODE * 3]float32
+	var straightCount int
+	query.FindStraightPath(startPos[:], goalPos[:], path[:], pathCount, straight[:], nil, nil, &straightCount, PATH_MAX_NODE, 0)
+	var length float32
+	for i := 1; i < straightCount; i++ {
+		length += detour.DtVdist(straight[(i-1)*3:], straight[i*3:])
+	}
+	if openCost < length {
+		t.Fatalf("field cost %f is shorter than the straight path %f", openCost, length)
+	}
+
+	// A wall with a gap makes the route longer. Only the affected polygons are searched again.
+	var walls []dtcache.DtObstacleRef
+	for x := float32(-1010); x < 10; x += 10 {
+		if x > -520 && x < -480 {
+			continue
+		}
+		bmin := [3]float32{x, -100, 450}
+		bmax := [3]float32{x + 11, 100, 455}
+		var wall dtcache.DtObstacleRef
+		if stat := tileCache.AddBoxObstacle(bmin[:], bmax[:], &wall); detour.DtStatusFailed(stat) {
+			t.Fatalf("add obstacle: %x", stat)
+		}
+		walls = append(walls, wall)
+		for upToDate := false; !upToDate; {
+			tileCache.Update(0, mesh, &upToDate)
+		}
+	}
+	fresh := detour.DtAllocFlowField(mesh, filter)
+	fresh.SetGoal(field.GetGoalRef(), goalPos[:])
+	checkSameFlow(t, mesh, field, fresh)
+	detour.DtFreeFlowField(fresh)
+	query.FindNearestPoly(startPos[:], halfExtents[:], filter, &startRef, startPos[:])
+	if wallCost := followField(); wallCost <= openCost {
+		t.Fatalf("the wall does not make the route longer: %f, was %f", wallCost, openCost)
+	}
+
+	// Rebuilding the goal tile finds the goal polygon again.
+	bmin := [3]float32{goalPos[0] + 3, -100, goalPos[2] + 3}
+	bmax := [3]float32{goalPos[0] + 4, 100, goalPos[2] + 4}
+	var ob dtcache.DtObstacleRef
+	tileCache.AddBoxObstacle(bmin[:], bmax[:], &ob)
+	for upToDate := false; !upToDate; {
+		tileCache.Update(0, mesh, &upToDate)
+	}
+	if detour.DtStatusFailed(field.Sample(startRef, startPos[:], nil, nil, nil)) ||
+		field.GetGoalRef() == goalRef || !mesh.IsValidPolyRef(field.GetGoalRef()) {
+		t.Fatal("goal polygon not found again")
+	}
+
+	// Removing the obstacles restores the original costs.
+	walls = append(walls, ob)
+	for _, wall := range walls {
+		tileCache.RemoveObstacle(wall)
+		for upToDate := false; !upToDate; {
+			tileCache.Update(0, mesh, &upToDate)
+		}
+	}
+	fresh = detour.DtAllocFlowField(mesh, filter)
+	query.FindNearestPoly(goalPos[:], halfExtents[:], filter, &goalRef, goalPos[:])
+	fresh.SetGoal(goalRef, goalPos[:])
+	checkSameFlow(t, mesh, field, fresh)
+	detour.DtFreeFlowField(fresh)
+	query.FindNearestPoly(startPos[:], halfExtents[:], filter, &startRef, startPos[:])
+	if cost := followField(); math.Abs(float64(cost-openCost)) > 1e-3 {
+		t.Fatalf("cost %f after removing the wall, was %f", cost, openCost)
+	}
+}
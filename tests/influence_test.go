@@ -0,0 +1,97 @@
+package tests
+
+import (
+	"testing"
+
+	detour "github.com/fananchong/recastnavigation-go/Detour"
+	dtcache "github.com/fananchong/recastnavigation-go/DetourTileCache"
+)
+
+func Test_InfluenceMap(t *testing.T) {
+	mesh, tileCache := LoadDynamicMesh("scene1.obj.tilecache.bin")
+	query := CreateQuery(mesh, 65535)
+	filter := detour.DtAllocDtQueryFilter()
+	influence := detour.DtAllocInfluenceMap(mesh, 2, 0.01)
+	defer detour.DtFreeInfluenceMap(influence)
+	danger := detour.DtAllocDtQueryFilter()
+	danger.SetCustom(&detour.DtInfluenceCost{Influence: influence, Weight: 10})
+
+	halfExtents := [3]float32{2, 4, 2}
+	startPos := [3]float32{-800, 0, 100}
+	endPos := [3]float32{-200, 0, 880}
+	var startRef, endRef detour.DtPolyRef
+	query.FindNearestPoly(startPos[:], halfExtents[:], filter, &startRef, startPos[:])
+	query.FindNearestPoly(endPos[:], halfExtents[:], filter, &endRef, endPos[:])
+	var path, avoiding [PATH_MAX_NODE]detour.DtPolyRef
+	var pathCount, avoidingCount int
+	query.FindPath(startRef, endRef, startPos[:], endPos[:], filter, path[:], &pathCount, PATH_MAX_NODE)
+
+	// An explosion on the path.
+	blastRef := path[pathCount/2]
+	var blastPos [3]float32
+	query.ClosestPointOnPoly(blastRef, startPos[:], blastPos[:], nil)
+	const radius = 20
+	if stat := influence.SplatCircle(query, blastRef, blastPos[:], radius, 1, true, filter); detour.DtStatusFailed(stat) {
+		t.Fatalf("splat: 0x%x", stat)
+	}
+	if influence.GetEntryCount() < 2 || influence.Get(blastRef) <= 0 || influence.Get(blastRef) > 1 {
+		t.Fatalf("unexpected influence %f on %d polygons", influence.Get(blastRef), influence.GetEntryCount())
+	}
+	var polys [256]detour.DtPolyRef
+	var npolys int
+	query.FindPolysAroundCircle(blastRef, blastPos[:], radius, filter, polys[:], nil, nil, &npolys, len(polys))
+	for i := 0; i < npolys; i++ {
+		if influence.Get(polys[i]) > influence.Get(blastRef) {
+			t.Fatalf("polygon %d has more influence than the blast center", i)
+		}
+	}
+
+	// The path goes around the danger.
+	inDanger := func(p []detour.DtPolyRef, n int) (sum float32) {
+		for i := 0; i < n; i++ {
+			sum += influence.Get(p[i])
+		}
+		return sum
+	}
+	if stat := query.FindPath(startRef, endRef, startPos[:], endPos[:], danger, avoiding[:], &avoidingCount, PATH_MAX_NODE); stat != detour.DT_SUCCESS {
+		t.Fatalf("find path: 0x%x", stat)
+	}
+	if inDanger(avoiding[:], avoidingCount) >= inDanger(path[:], pathCount) {
+		t.Fatal("the path does not avoid the danger")
+	}
+
+	// The danger decays away.
+	before := influence.Get(blastRef)
+	influence.Update(2)
+	if !IsEquals(influence.Get(blastRef), before/2) {
+		t.Fatalf("unexpected decay %f -> %f", before, influence.Get(blastRef))
+	}
+	influence.Update(20)
+	if influence.GetEntryCount() != 0 {
+		t.Fatalf("%d influences left", influence.GetEntryCount())
+	}
+	query.FindPath(startRef, endRef, startPos[:], endPos[:], danger, avoiding[:], &avoidingCount, PATH_MAX_NODE)
+	if avoidingCount != pathCount || avoiding != path {
+		t.Fatal("the path differs without danger")
+	}
+
+	// A shape splat, then rebuilding its tile drops the influence of the tile.
+	shape := []float32{
+		blastPos[0] - 1, blastPos[1], blastPos[2] - 1,
+		blastPos[0] - 1, blastPos[1], blastPos[2] + 1,
+		blastPos[0] + 1, blastPos[1], blastPos[2] + 1,
+		blastPos[0] + 1, blastPos[1], blastPos[2] - 1,
+	}
+	influence.SplatShape(query, blastRef, shape, 4, 3, filter)
+	if influence.Get(blastRef) != 3 {
+		t.Fatalf("unexpected shape influence %f", influence.Get(blastRef))
+	}
+	var ob dtcache.DtObstacleRef
+	tileCache.AddObstacle(blastPos[:], 0.5, 2, &ob)
+	for upToDate := false; !upToDate; {
+		tileCache.Update(0, mesh, &upToDate)
+	}
+	if influence.Get(blastRef) != 0 {
+		t.Fatal("the influence of the rebuilt tile was kept")
+	}
+}
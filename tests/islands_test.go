@@ -0,0 +1,122 @@
+package tests
+
+import (
+	"testing"
+
+	detour "github.com/fananchong/recastnavigation-go/Detour"
+	dtcache "github.com/fananchong/recastnavigation-go/DetourTileCache"
+)
+
+// checkSameIslands fails if the two labelings do not partition the polygons the same way.
+func checkSameIslands(t *testing.T, mesh *detour.DtNavMesh, a, b *detour.DtNavMeshIslands) {
+	ab := make(map[uint32]uint32)
+	ba := make(map[uint32]uint32)
+	for i := 0; i < int(mesh.GetMaxTiles()); i++ {
+		tile := mesh.GetTile(i)
+		if tile == nil || tile.Header == nil {
+			continue
+		}
+		base := mesh.GetPolyRefBase(tile)
+		for j := 0; j < int(tile.Header.PolyCount); j++ {
+			ref := base | detour.DtPolyRef(j)
+			ia, ib := a.GetIsland(ref), b.GetIsland(ref)
+			if (ia == detour.DT_NULL_ISLAND) != (ib == detour.DT_NULL_ISLAND) {
+				t.Fatalf("poly %d: island %d, want %d", ref, ia, ib)
+			}
+			if v, ok := ab[ia]; ok && v != ib {
+				t.Fatalf("poly %d: island %d is split", ref, ib)
+			}
+			if v, ok := ba[ib]; ok && v != ia {
+				t.Fatalf("poly %d: islands %d and %d are merged", ref, v, ia)
+			}
+			ab[ia] = ib
+			ba[ib] = ia
+		}
+	}
+}
+
+func reachesEnd(query *detour.DtNavMeshQuery, filter *detour.DtQueryFilter,
+	startRef, endRef detour.DtPolyRef, startPos, endPos []float32) bool {
+	var path [PATH_MAX_NODE]detour.DtPolyRef
+	var pathCount int
+	stat := query.FindPath(startRef, endRef, startPos, endPos, filter, path[:], &pathCount, PATH_MAX_NODE)
+	return detour.DtStatusSucceed(stat) && pathCount > 0 && path[pathCount-1] == endRef
+}
+
+func Test_NavMeshIslands(t *testing.T) {
+	mesh, tileCache := LoadDynamicMesh("scene1.obj.tilecache.bin")
+	query := CreateQuery(mesh, 65535)
+	filter := detour.DtAllocDtQueryFilter()
+	islands := detour.DtAllocNavMeshIslands(mesh, filter)
+	defer detour.DtFreeNavMeshIslands(islands)
+	query.SetIslands(islands)
+
+	halfExtents := [3]float32{2, 4, 2}
+	startPos := [3]float32{-800, 0, 100}
+	endPos := [3]float32{-200, 0, 880}
+	var startRef, endRef detour.DtPolyRef
+	query.FindNearestPoly(startPos[:], halfExtents[:], filter, &startRef, startPos[:])
+	query.FindNearestPoly(endPos[:], halfExtents[:], filter, &endRef, endPos[:])
+	if !query.AreConnected(startRef, endRef) || !reachesEnd(query, filter, startRef, endRef, startPos[:], endPos[:]) {
+		t.Fatal("start and end should be connected")
+	}
+
+	// Disabling a polygon removes it from its island.
+	var flags uint16
+	mesh.GetPolyFlags(startRef, &flags)
+	mesh.SetPolyFlags(startRef, 0)
+	if query.AreConnected(startRef, endRef) {
+		t.Fatal("disabled poly is connected")
+	}
+	fresh := detour.DtAllocNavMeshIslands(mesh, filter)
+	checkSameIslands(t, mesh, islands, fresh)
+	detour.DtFreeNavMeshIslands(fresh)
+	mesh.SetPolyFlags(startRef, flags)
+	if !query.AreConnected(startRef, endRef) {
+		t.Fatal("enabled poly is not connected")
+	}
+
+	// Cut the level in two with a wall, which rebuilds the tiles it touches.
+	// An obstacle touches a limited number of tiles, so build the wall from pieces.
+	var walls []dtcache.DtObstacleRef
+	for x := float32(-1010); x < 10; x += 10 {
+		bmin := [3]float32{x, -100, 450}
+		bmax := [3]float32{x + 11, 100, 455}
+		var wall dtcache.DtObstacleRef
+		if stat := tileCache.AddBoxObstacle(bmin[:], bmax[:], &wall); detour.DtStatusFailed(stat) {
+			t.Fatalf("add obstacle: %x", stat)
+		}
+		walls = append(walls, wall)
+		for upToDate := false; !upToDate; {
+			tileCache.Update(0, mesh, &upToDate)
+		}
+	}
+	fresh = detour.DtAllocNavMeshIslands(mesh, filter)
+	checkSameIslands(t, mesh, islands, fresh)
+	detour.DtFreeNavMeshIslands(fresh)
+
+	query.FindNearestPoly(startPos[:], halfExtents[:], filter, &startRef, startPos[:])
+	query.FindNearestPoly(endPos[:], halfExtents[:], filter, &endRef, endPos[:])
+	if query.AreConnected(startRef, endRef) != reachesEnd(query, filter, startRef, endRef, startPos[:], endPos[:]) {
+		t.Fatal("island labeling does not agree with FindPath")
+	}
+	if query.AreConnected(startRef, endRef) {
+		t.Fatal("start and end should be separated by the wall")
+	}
+
+	// Removing the wall joins the islands again.
+	for _, wall := range walls {
+		tileCache.RemoveObstacle(wall)
+		for upToDate := false; !upToDate; {
+			tileCache.Update(0, mesh, &upToDate)
+		}
+	}
+	fresh = detour.DtAllocNavMeshIslands(mesh, filter)
+	checkSameIslands(t, mesh, islands, fresh)
+	detour.DtFreeNavMeshIslands(fresh)
+	query.FindNearestPoly(startPos[:], halfExtents[:], filter, &startRef, startPos[:])
+	query.FindNearestPoly(endPos[:], halfExtents[:], filter, &endRef, endPos[:])
+	if !query.AreConnected(startRef, endRef) {
+		t.Fatal("start and end should be connected again")
+	}
+}
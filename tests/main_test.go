@@ -10,30 +10,26 @@ import (
 	"github.com/fananchong/recastnavigation-go/DetourTileCache"
 )
 
-var randValue []float32
-var randIndex = 0
+// Same generator and seed as tests/c/main.cpp.
+const PCG_SEED uint64 = 42
+const PCG_SEQ uint64 = 54
+
+var rng = detour.DtAllocPCG32(PCG_SEED, PCG_SEQ)
 
 func frand() float32 {
-	v := randValue[randIndex]
-	randIndex++
-	return v
+	return rng.Float32()
 }
 
 const PATH_MAX_NODE int = 2048
 
 func Test_main(t *testing.T) {
-	tempdata, err := ioutil.ReadFile("rand.bin")
-	detour.DtAssert(err == nil)
-	sliceHeader := (*reflect.SliceHeader)((unsafe.Pointer(&randValue)))
-	sliceHeader.Cap = int(len(tempdata) / int(unsafe.Sizeof(float32(1.0))))
-	sliceHeader.Len = int(len(tempdata) / int(unsafe.Sizeof(float32(1.0))))
-	sliceHeader.Data = uintptr(unsafe.Pointer(&(tempdata[0])))
+	rng.Seed(PCG_SEED, PCG_SEQ)
 
 	var resultValue []float32
 	var resultIndex = 0
 	tempdata2, err2 := ioutil.ReadFile("result.bin")
 	detour.DtAssert(err2 == nil)
-	sliceHeader = (*reflect.SliceHeader)((unsafe.Pointer(&resultValue)))
+	sliceHeader := (*reflect.SliceHeader)((unsafe.Pointer(&resultValue)))
 	sliceHeader.Cap = int(len(tempdata2) / int(unsafe.Sizeof(float32(1.0))))
 	sliceHeader.Len = int(len(tempdata2) / int(unsafe.Sizeof(float32(1.0))))
 	sliceHeader.Data = uintptr(unsafe.Pointer(&(tempdata2[0])))
@@ -0,0 +1,73 @@
+package tests
+
+import (
+	"testing"
+
+	detour "github.com/fananchong/recastnavigation-go/Detour"
+)
+
+func Test_FindPathToAnyGoal(t *testing.T) {
+	mesh, _ := LoadDynamicMesh("scene1.obj.tilecache.bin")
+	query := CreateQuery(mesh, 65535)
+	filter := detour.DtAllocDtQueryFilter()
+	rng := detour.DtAllocPCG32(3, 4)
+
+	halfExtents := [3]float32{2, 4, 2}
+	startPos := [3]float32{-800, 0, 100}
+	var startRef detour.DtPolyRef
+	query.FindNearestPoly(startPos[:], halfExtents[:], filter, &startRef, startPos[:])
+
+	const goalCount = 8
+	var goalRefs [goalCount]detour.DtPolyRef
+	var goalPos [goalCount * 3]float32
+	for i := 0; i < goalCount; i++ {
+		query.FindRandomPointAroundCircleRNG(startRef, startPos[:], 300, filter, rng, &goalRefs[i], goalPos[i*3:])
+	}
+
+	var path [PATH_MAX_NODE]detour.DtPolyRef
+	var pathCount int
+	var bestGoal int
+	var bestCost float32
+	stat := query.FindPathToAnyGoal(startRef, startPos[:], goalRefs[:], goalPos[:], goalCount, filter,
+		&bestGoal, &bestCost, path[:], &pathCount, PATH_MAX_NODE)
+	if detour.DtStatusFailed(stat) || detour.DtStatusDetail(stat, detour.DT_PARTIAL_RESULT) || bestGoal < 0 {
+		t.Fatalf("FindPathToAnyGoal: %x, goal %d", stat, bestGoal)
+	}
+	if path[0] != startRef || path[pathCount-1] != goalRefs[bestGoal] {
+		t.Fatal("path does not connect the start and the goal")
+	}
+
+	// The same as searching every goal on its own and keeping the cheapest.
+	for i := 0; i < goalCount; i++ {
+		var goal, single int
+		var cost float32
+		var singlePath [PATH_MAX_NODE]detour.DtPolyRef
+		stat = query.FindPathToAnyGoal(startRef, startPos[:], goalRefs[i:], goalPos[i*3:], 1, filter,
+			&goal, &cost, singlePath[:], &single, PATH_MAX_NODE)
+		if detour.DtStatusFailed(stat) || goal != 0 || singlePath[single-1] != goalRefs[i] {
+			t.Fatalf("goal %d: %x", i, stat)
+		}
+		if cost < bestCost-bestCost*1e-5 {
+			t.Fatalf("goal %d costs %f, cheaper than the best goal %d at %f", i, cost, bestGoal, bestCost)
+		}
+		if i == bestGoal && cost > bestCost+bestCost*1e-5 {
+			t.Fatalf("best goal costs %f alone, %f with others", cost, bestCost)
+		}
+
+		// FindPath finds a path to the same polygon.
+		var n int
+		stat = query.FindPath(startRef, goalRefs[i], startPos[:], goalPos[i*3:], filter, singlePath[:], &n, PATH_MAX_NODE)
+		if detour.DtStatusFailed(stat) || singlePath[n-1] != goalRefs[i] {
+			t.Fatalf("goal %d: FindPath %x", i, stat)
+		}
+	}
+
+	// Goals which do not pass the filter are ignored.
+	excluded := detour.DtAllocDtQueryFilter()
+	excluded.SetExcludeFlags(0xffff)
+	stat = query.FindPathToAnyGoal(startRef, startPos[:], goalRefs[:], goalPos[:], goalCount, excluded,
+		&bestGoal, nil, path[:], &pathCount, PATH_MAX_NODE)
+	if !detour.DtStatusFailed(stat) {
+		t.Fatalf("all goals filtered out: %x", stat)
+	}
+}
@@ -0,0 +1,91 @@
+package tests
+
+import (
+	"testing"
+
+	detour "github.com/fananchong/recastnavigation-go/Detour"
+)
+
+func Test_MultiNavMesh(t *testing.T) {
+	large, _ := LoadDynamicMesh("scene1.obj.tilecache.bin")
+	small := createOneWayMesh(t)
+	var largeProfile detour.DtAgentProfile
+	if stat := detour.DtGetAgentProfile(large, &largeProfile); stat != detour.DT_SUCCESS || largeProfile.Radius <= 0.5 {
+		t.Fatalf("unexpected profile %+v", largeProfile)
+	}
+
+	multi := detour.DtAllocMultiNavMesh()
+	defer detour.DtFreeMultiNavMesh(multi)
+	var largeIdx, smallIdx int
+	if multi.AddNavMesh(large, 65535, &largeIdx) != detour.DT_SUCCESS || multi.AddNavMesh(small, 64, &smallIdx) != detour.DT_SUCCESS {
+		t.Fatal("cannot add the meshes")
+	}
+	if multi.GetProfileCount() != 2 || largeIdx != 0 || smallIdx != 1 || multi.GetProfile(smallIdx).Radius != 0.5 || multi.GetNavMesh(largeIdx) != large {
+		t.Fatal("unexpected profiles")
+	}
+
+	// Agents use the mesh of the smallest radius fitting them.
+	for _, c := range []struct {
+		radius  float32
+		profile int
+	}{{0, smallIdx}, {0.5, smallIdx}, {0.51, largeIdx}, {largeProfile.Radius, largeIdx}, {largeProfile.Radius + 0.01, -1}} {
+		if idx := multi.SelectProfile(c.radius); idx != c.profile {
+			t.Fatalf("radius %f: profile %d", c.radius, idx)
+		}
+	}
+
+	filter := detour.DtAllocDtQueryFilter()
+	halfExtents := [3]float32{2, 4, 2}
+	startPos := [3]float32{-800, 0, 100}
+	endPos := [3]float32{-200, 0, 880}
+	var startRef, endRef detour.DtMultiPolyRef
+	multi.FindNearestPoly(largeProfile.Radius, startPos[:], halfExtents[:], filter, &startRef, startPos[:])
+	multi.FindNearestPoly(largeProfile.Radius, endPos[:], halfExtents[:], filter, &endRef, endPos[:])
+	var idx int
+	var ref detour.DtPolyRef
+	if startRef == 0 || endRef == 0 || multi.DecodePolyRef(startRef, &idx, &ref) != detour.DT_SUCCESS || idx != largeIdx {
+		t.Fatal("no polygon on the large mesh")
+	}
+	// A plain reference is not taken for one of the first profile.
+	if !detour.DtStatusFailed(multi.DecodePolyRef(detour.DtMultiPolyRef(ref), &idx, &ref)) {
+		t.Fatal("a plain reference was decoded")
+	}
+
+	// The path matches the one found on the mesh directly.
+	var path [PATH_MAX_NODE]detour.DtMultiPolyRef
+	var pathCount int
+	if stat := multi.FindPath(largeProfile.Radius, startRef, endRef, startPos[:], endPos[:], filter, path[:], &pathCount, PATH_MAX_NODE); stat != detour.DT_SUCCESS {
+		t.Fatalf("find path: 0x%x", stat)
+	}
+	var direct [PATH_MAX_NODE]detour.DtPolyRef
+	var directCount int
+	var start, end detour.DtPolyRef
+	multi.DecodePolyRef(startRef, &idx, &start)
+	multi.DecodePolyRef(endRef, &idx, &end)
+	multi.GetQuery(largeIdx).FindPath(start, end, startPos[:], endPos[:], filter, direct[:], &directCount, PATH_MAX_NODE)
+	if pathCount != directCount {
+		t.Fatalf("path of %d polygons, expected %d", pathCount, directCount)
+	}
+	for i := 0; i < pathCount; i++ {
+		if path[i] != multi.EncodePolyRef(largeIdx, direct[i]) {
+			t.Fatalf("polygon %d differs", i)
+		}
+	}
+
+	// The small mesh is used for small agents, its references do not mix with the large ones.
+	smallPos := [3]float32{2, 0, 8}
+	var smallRef detour.DtMultiPolyRef
+	if stat := multi.FindNearestPoly(0.3, smallPos[:], halfExtents[:], filter, &smallRef, smallPos[:]); stat != detour.DT_SUCCESS || smallRef == 0 {
+		t.Fatalf("no polygon on the small mesh: 0x%x", stat)
+	}
+	if stat := multi.FindPath(0.3, smallRef, endRef, smallPos[:], endPos[:], filter, path[:], &pathCount, PATH_MAX_NODE); !detour.DtStatusFailed(stat) {
+		t.Fatal("a path was found between meshes")
+	}
+	plainStart, plainEnd := detour.DtMultiPolyRef(start), detour.DtMultiPolyRef(end)
+	if stat := multi.FindPath(largeProfile.Radius, plainStart, plainEnd, startPos[:], endPos[:], filter, path[:], &pathCount, PATH_MAX_NODE); !detour.DtStatusFailed(stat) {
+		t.Fatal("a path was found between plain references")
+	}
+	if stat := multi.FindPath(largeProfile.Radius+1, startRef, endRef, startPos[:], endPos[:], filter, path[:], &pathCount, PATH_MAX_NODE); !detour.DtStatusFailed(stat) {
+		t.Fatal("a path was found for an agent too large for all meshes")
+	}
+}
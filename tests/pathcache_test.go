@@ -0,0 +1,110 @@
+package tests
+
+import (
+	"testing"
+
+	detour "github.com/fananchong/recastnavigation-go/Detour"
+	dtcache "github.com/fananchong/recastnavigation-go/DetourTileCache"
+)
+
+func Test_PathCache(t *testing.T) {
+	mesh, tileCache := LoadDynamicMesh("scene1.obj.tilecache.bin")
+	query := CreateQuery(mesh, 65535)
+	filter := detour.DtAllocDtQueryFilter()
+	cache := detour.DtAllocPathCache(mesh, 2)
+	defer detour.DtFreePathCache(cache)
+	query.SetPathCache(cache)
+
+	halfExtents := [3]float32{2, 4, 2}
+	startPos := [3]float32{-800, 0, 100}
+	endPos := [3]float32{-200, 0, 880}
+	var startRef, endRef detour.DtPolyRef
+	query.FindNearestPoly(startPos[:], halfExtents[:], filter, &startRef, startPos[:])
+	query.FindNearestPoly(endPos[:], halfExtents[:], filter, &endRef, endPos[:])
+
+	var path, cached [PATH_MAX_NODE]detour.DtPolyRef
+	var pathCount, cachedCount int
+	findPath := func(f *detour.DtQueryFilter, p []detour.DtPolyRef, n *int) detour.DtStatus {
+		return query.FindPath(startRef, endRef, startPos[:], endPos[:], f, p, n, len(p))
+	}
+	expect := func(hits, misses, evictions, invalidations int) {
+		stats := cache.GetStats()
+		if stats != (detour.DtPathCacheStats{Hits: hits, Misses: misses, Evictions: evictions, Invalidations: invalidations}) {
+			t.Fatalf("unexpected stats %+v", stats)
+		}
+	}
+
+	if stat := findPath(filter, path[:], &pathCount); stat != detour.DT_SUCCESS {
+		t.Fatalf("find path: 0x%x", stat)
+	}
+	if !query.IsInClosedList(startRef) {
+		t.Fatal("start poly not searched")
+	}
+	if stat := findPath(filter, cached[:], &cachedCount); stat != detour.DT_SUCCESS {
+		t.Fatalf("cached path: 0x%x", stat)
+	}
+	expect(1, 1, 0, 0)
+	if cachedCount != pathCount || cached != path {
+		t.Fatal("cached path differs")
+	}
+	// A hit does not leave the nodes of the previous search behind.
+	if query.IsInClosedList(startRef) || query.GetNodePool().GetNodeCount() != 0 {
+		t.Fatal("node pool not cleared on a hit")
+	}
+
+	// A short buffer is filled from the start.
+	if stat := findPath(filter, cached[:3], &cachedCount); stat != detour.DT_SUCCESS|detour.DT_BUFFER_TOO_SMALL || cachedCount != 3 || cached[2] != path[2] {
+		t.Fatalf("short cached path: 0x%x", stat)
+	}
+	expect(2, 1, 0, 0)
+
+	// Filters with different settings do not share paths, the least recently used is evicted.
+	other := detour.DtAllocDtQueryFilter()
+	other.SetAreaCost(0, 2)
+	findPath(other, cached[:], &cachedCount)
+	expect(2, 2, 0, 0)
+	third := detour.DtAllocDtQueryFilter()
+	third.SetExcludeFlags(0x8000)
+	findPath(third, cached[:], &cachedCount)
+	findPath(filter, cached[:], &cachedCount)
+	expect(2, 4, 2, 0)
+	if cache.GetEntryCount() != 2 {
+		t.Fatalf("unexpected entry count %d", cache.GetEntryCount())
+	}
+
+	// Filters are told apart by their settings, not by identity.
+	same := detour.DtAllocDtQueryFilter()
+	findPath(same, cached[:], &cachedCount)
+	expect(3, 4, 2, 0)
+	same.SetAreaCost(detour.DT_MAX_AREAS-1, 2)
+	findPath(same, cached[:], &cachedCount)
+	expect(3, 5, 3, 0)
+
+	// Setting the same flags keeps the paths, changing them drops the paths on the polygon.
+	var flags uint16
+	mesh.GetPolyFlags(path[pathCount/2], &flags)
+	mesh.SetPolyFlags(path[pathCount/2], flags)
+	expect(3, 5, 3, 0)
+	mesh.SetPolyFlags(path[pathCount/2], flags|0x4000)
+	expect(3, 5, 3, 2)
+	mesh.SetPolyFlags(path[pathCount/2], flags)
+	findPath(filter, cached[:], &cachedCount)
+	expect(3, 6, 3, 2)
+
+	// Rebuilding a tile on the path drops it.
+	var pos [3]float32
+	query.ClosestPointOnPoly(path[pathCount/2], startPos[:], pos[:], nil)
+	var ob dtcache.DtObstacleRef
+	tileCache.AddObstacle(pos[:], 0.5, 2, &ob)
+	for upToDate := false; !upToDate; {
+		tileCache.Update(0, mesh, &upToDate)
+	}
+	expect(3, 6, 3, 3)
+	if stat := findPath(filter, path[:], &pathCount); stat != detour.DT_SUCCESS {
+		t.Fatalf("find path: 0x%x", stat)
+	}
+	checkPath(t, mesh, path[:pathCount], startRef, endRef)
+	expect(3, 7, 3, 3)
+	findPath(filter, cached[:], &cachedCount)
+	expect(4, 7, 3, 3)
+}
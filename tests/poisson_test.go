@@ -0,0 +1,96 @@
+package tests
+
+import (
+	"math/rand"
+	"testing"
+
+	detour "github.com/fananchong/recastnavigation-go/Detour"
+)
+
+func checkPoissonPoints(t *testing.T, mesh *detour.DtNavMesh, refs []detour.DtPolyRef, pts []float32, count int, minDist float32) {
+	for i := 0; i < count; i++ {
+		if !mesh.IsValidPolyRef(refs[i]) {
+			t.Fatalf("point %d: invalid poly %d", i, refs[i])
+		}
+		for j := 0; j < i; j++ {
+			if d := detour.DtVdist(pts[i*3:], pts[j*3:]); d < minDist {
+				t.Fatalf("points %d and %d are %f apart", i, j, d)
+			}
+		}
+	}
+}
+
+func Test_FindPoissonDiskPoints(t *testing.T) {
+	mesh, _ := LoadDynamicMesh("scene1.obj.tilecache.bin")
+	query := CreateQuery(mesh, 4096)
+	filter := detour.DtAllocDtQueryFilter()
+	rnd := rand.New(rand.NewSource(1))
+
+	halfExtents := [3]float32{2, 4, 2}
+	center := [3]float32{-800, 0, 100}
+	var centerRef detour.DtPolyRef
+	query.FindNearestPoly(center[:], halfExtents[:], filter, &centerRef, center[:])
+	if centerRef == 0 {
+		t.Fatal("no center poly")
+	}
+
+	const MAX_POINTS = 1024
+	refs := make([]detour.DtPolyRef, MAX_POINTS)
+	pts := make([]float32, MAX_POINTS*3)
+	var count int
+
+	// Circle region.
+	params := detour.DtPoissonDiskParams{
+		MinDist:     5,
+		HalfExtents: [3]float32{1, 4, 1},
+		RegionType:  detour.DT_POISSON_REGION_CIRCLE,
+		StartRef:    centerRef,
+		Center:      center,
+		Radius:      60,
+	}
+	stat := query.FindPoissonDiskPoints(&params, filter, rnd, refs, pts, &count, MAX_POINTS)
+	if detour.DtStatusFailed(stat) || detour.DtStatusDetail(stat, detour.DT_BUFFER_TOO_SMALL) {
+		t.Fatalf("circle: %x", stat)
+	}
+	// A maximal Poisson-disk set covers the disc with circles of MinDist.
+	if count < 100 {
+		t.Fatalf("circle: only %d points", count)
+	}
+	checkPoissonPoints(t, mesh, refs, pts, count, params.MinDist)
+	for i := 0; i < count; i++ {
+		dx, dz := pts[i*3]-center[0], pts[i*3+2]-center[2]
+		if dx*dx+dz*dz > params.Radius*params.Radius {
+			t.Fatalf("circle: point %d is outside the region", i)
+		}
+	}
+
+	// Polygon region.
+	square := []float32{
+		center[0] - 30, center[1], center[2] - 30,
+		center[0] - 30, center[1], center[2] + 30,
+		center[0] + 30, center[1], center[2] + 30,
+		center[0] + 30, center[1], center[2] - 30,
+	}
+	params.RegionType = detour.DT_POISSON_REGION_POLYGON
+	params.Verts = square
+	params.NVerts = 4
+	stat = query.FindPoissonDiskPoints(&params, filter, rnd, refs, pts, &count, MAX_POINTS)
+	if detour.DtStatusFailed(stat) || count == 0 {
+		t.Fatalf("polygon: %x, %d points", stat, count)
+	}
+	checkPoissonPoints(t, mesh, refs, pts, count, params.MinDist)
+	for i := 0; i < count; i++ {
+		if !detour.DtPointInPolygon(pts[i*3:], square, 4) {
+			t.Fatalf("polygon: point %d is outside the region", i)
+		}
+	}
+
+	// The whole mesh fills the result arrays.
+	params.RegionType = detour.DT_POISSON_REGION_NONE
+	params.MinDist = 20
+	stat = query.FindPoissonDiskPoints(&params, filter, rnd, refs, pts, &count, 200)
+	if !detour.DtStatusDetail(stat, detour.DT_BUFFER_TOO_SMALL) || count != 200 {
+		t.Fatalf("mesh: %x, %d points", stat, count)
+	}
+	checkPoissonPoints(t, mesh, refs, pts, count, params.MinDist)
+}
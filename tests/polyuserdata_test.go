@@ -0,0 +1,117 @@
+package tests
+
+import (
+	"bytes"
+	"testing"
+
+	detour "github.com/fananchong/recastnavigation-go/Detour"
+)
+
+// blockedZoneFilter refuses the polygons whose user data names a blocked zone.
+type blockedZoneFilter struct {
+	userData *detour.DtPolyUserData
+	blocked  []byte
+}
+
+func (this *blockedZoneFilter) PassFilter(filter *detour.DtQueryFilter, ref detour.DtPolyRef, tile *detour.DtMeshTile, poly *detour.DtPoly) bool {
+	return !bytes.Equal(this.userData.Get(ref), this.blocked) && filter.DefaultPassFilter(ref, tile, poly)
+}
+
+func (this *blockedZoneFilter) GetCost(filter *detour.DtQueryFilter, pa, pb []float32,
+	prevRef detour.DtPolyRef, prevTile *detour.DtMeshTile, prevPoly *detour.DtPoly,
+	curRef detour.DtPolyRef, curTile *detour.DtMeshTile, curPoly *detour.DtPoly,
+	nextRef detour.DtPolyRef, nextTile *detour.DtMeshTile, nextPoly *detour.DtPoly) float32 {
+	return filter.DefaultGetCost(pa, pb, prevRef, prevTile, prevPoly, curRef, curTile, curPoly, nextRef, nextTile, nextPoly)
+}
+
+func Test_PolyUserData(t *testing.T) {
+	mesh, _ := LoadDynamicMesh("scene1.obj.tilecache.bin")
+	query := CreateQuery(mesh, 65535)
+	filter := detour.DtAllocDtQueryFilter()
+	userData := detour.DtAllocPolyUserData(mesh)
+	defer detour.DtFreePolyUserData(userData)
+
+	halfExtents := [3]float32{2, 4, 2}
+	startPos := [3]float32{-800, 0, 100}
+	endPos := [3]float32{-200, 0, 880}
+	var startRef, endRef detour.DtPolyRef
+	query.FindNearestPoly(startPos[:], halfExtents[:], filter, &startRef, startPos[:])
+	query.FindNearestPoly(endPos[:], halfExtents[:], filter, &endRef, endPos[:])
+	var path [PATH_MAX_NODE]detour.DtPolyRef
+	var pathCount int
+	query.FindPath(startRef, endRef, startPos[:], endPos[:], filter, path[:], &pathCount, PATH_MAX_NODE)
+	if pathCount < 3 {
+		t.Fatalf("path too short: %d", pathCount)
+	}
+
+	blocked := []byte("blocked")
+	zone := []byte{1, 2, 3}
+	middle := path[pathCount/2]
+	if userData.Set(middle, blocked) != detour.DT_SUCCESS || userData.Set(startRef, zone) != detour.DT_SUCCESS {
+		t.Fatal("cannot set the user data")
+	}
+	zone[0] = 9
+	if !bytes.Equal(userData.Get(startRef), []byte{1, 2, 3}) || userData.Get(endRef) != nil || userData.GetEntryCount() != 2 {
+		t.Fatal("unexpected user data")
+	}
+	if !detour.DtStatusFailed(userData.Set(0, zone)) {
+		t.Fatal("user data set on an invalid polygon")
+	}
+
+	// A custom filter reads the user data.
+	custom := detour.DtAllocDtQueryFilter()
+	custom.SetCustom(&blockedZoneFilter{userData, blocked})
+	var around [PATH_MAX_NODE]detour.DtPolyRef
+	var detourCount int
+	if stat := query.FindPath(startRef, endRef, startPos[:], endPos[:], custom, around[:], &detourCount, PATH_MAX_NODE); stat != detour.DT_SUCCESS {
+		t.Fatalf("find path: 0x%x", stat)
+	}
+	for i := 0; i < detourCount; i++ {
+		if around[i] == middle {
+			t.Fatal("the path goes through the blocked polygon")
+		}
+	}
+
+	// Reloading the tile gives the polygon a new reference, its data is kept.
+	var tile *detour.DtMeshTile
+	var poly *detour.DtPoly
+	mesh.GetTileAndPolyByRef(middle, &tile, &poly)
+	data := append([]byte(nil), tile.Data[:tile.DataSize]...)
+	x, y, layer := tile.Header.X, tile.Header.Y, tile.Header.Layer
+	index := mesh.DecodePolyIdPoly(middle)
+	if stat := mesh.RemoveTile(mesh.GetTileRef(tile), nil, nil); detour.DtStatusFailed(stat) {
+		t.Fatalf("remove tile: 0x%x", stat)
+	}
+	if userData.Get(middle) != nil {
+		t.Fatal("the data of an unloaded polygon was found")
+	}
+	var newTileRef detour.DtTileRef
+	if stat := mesh.AddTile(data, len(data), detour.DT_TILE_FREE_DATA, 0, &newTileRef); detour.DtStatusFailed(stat) {
+		t.Fatalf("add tile: 0x%x", stat)
+	}
+	reloaded := mesh.GetPolyRefBase(mesh.GetTileAt(x, y, layer)) | detour.DtPolyRef(index)
+	if reloaded == middle || !bytes.Equal(userData.Get(reloaded), blocked) {
+		t.Fatal("the data was lost when the tile was reloaded")
+	}
+
+	// The table is restored from its stored data.
+	stored := make([]byte, userData.GetDataSize())
+	if stat := userData.Store(stored, len(stored)); stat != detour.DT_SUCCESS {
+		t.Fatalf("store: 0x%x", stat)
+	}
+	restored := detour.DtAllocPolyUserData(mesh)
+	if stat := restored.Restore(stored, len(stored)); stat != detour.DT_SUCCESS {
+		t.Fatalf("restore: 0x%x", stat)
+	}
+	if restored.GetEntryCount() != 2 || !bytes.Equal(restored.Get(reloaded), blocked) || !bytes.Equal(restored.Get(startRef), []byte{1, 2, 3}) {
+		t.Fatal("the restored table differs")
+	}
+	if stat := restored.Restore(stored[:len(stored)-1], len(stored)-1); !detour.DtStatusFailed(stat) || restored.GetEntryCount() != 2 {
+		t.Fatalf("truncated data was restored: 0x%x", stat)
+	}
+
+	userData.ClearTile(x, y, layer)
+	if userData.Get(reloaded) != nil || userData.GetEntryCount() != 1 {
+		t.Fatal("the tile was not cleared")
+	}
+}
@@ -0,0 +1,172 @@
+package tests
+
+import (
+	"testing"
+
+	detour "github.com/fananchong/recastnavigation-go/Detour"
+	dtcache "github.com/fananchong/recastnavigation-go/DetourTileCache"
+)
+
+// checkPath fails if the path does not connect the polygons through links.
+func checkPath(t *testing.T, mesh *detour.DtNavMesh, path []detour.DtPolyRef, startRef, endRef detour.DtPolyRef) {
+	if len(path) == 0 || path[0] != startRef || path[len(path)-1] != endRef {
+		t.Fatalf("path does not go from %d to %d", startRef, endRef)
+	}
+	for i := 1; i < len(path); i++ {
+		var tile *detour.DtMeshTile
+		var poly *detour.DtPoly
+		mesh.GetTileAndPolyByRef(path[i-1], &tile, &poly)
+		linked := false
+		for k := poly.FirstLink; k != detour.DT_NULL_LINK; k = tile.Links[k].Next {
+			linked = linked || tile.Links[k].Ref == path[i]
+		}
+		if !linked {
+			t.Fatalf("step %d: %d is not linked to %d", i, path[i-1], path[i])
+		}
+	}
+}
+
+func straightPathLength(query *detour.DtNavMeshQuery, startPos, endPos []float32, path []detour.DtPolyRef) float32 {
+	var straight [PATH_MAX_NODE * 3]float32
+	var n int
+	query.FindStraightPath(startPos, endPos, path, len(path), straight[:], nil, nil, &n, PATH_MAX_NODE, 0)
+	var length float32
+	for i := 1; i < n; i++ {
+		length += detour.DtVdist(straight[(i-1)*3:], straight[i*3:])
+	}
+	return length
+}
+
+func Test_FindPathHierarchical(t *testing.T) {
+	mesh, tileCache := LoadDynamicMesh("scene1.obj.tilecache.bin")
+	query := CreateQuery(mesh, 65535)
+	small := CreateQuery(mesh, 256)
+	filter := detour.DtAllocDtQueryFilter()
+	graph := detour.DtAllocPortalGraph(mesh, filter)
+	defer detour.DtFreePortalGraph(graph)
+	small.SetPortalGraph(graph)
+
+	halfExtents := [3]float32{2, 4, 2}
+	startPos := [3]float32{-800, 0, 100}
+	endPos := [3]float32{-200, 0, 880}
+	var startRef, endRef detour.DtPolyRef
+	query.FindNearestPoly(startPos[:], halfExtents[:], filter, &startRef, startPos[:])
+	query.FindNearestPoly(endPos[:], halfExtents[:], filter, &endRef, endPos[:])
+
+	var path [PATH_MAX_NODE]detour.DtPolyRef
+	var pathCount int
+
+	// The small node pool is not enough for a plain search.
+	stat := small.FindPath(startRef, endRef, startPos[:], endPos[:], filter, path[:], &pathCount, PATH_MAX_NODE)
+	if !detour.DtStatusDetail(stat, detour.DT_PARTIAL_RESULT) {
+		t.Fatalf("FindPath with a small pool: %x", stat)
+	}
+
+	stat = query.FindPath(startRef, endRef, startPos[:], endPos[:], filter, path[:], &pathCount, PATH_MAX_NODE)
+	if detour.DtStatusFailed(stat) || detour.DtStatusDetail(stat, detour.DT_PARTIAL_RESULT) {
+		t.Fatalf("FindPath: %x", stat)
+	}
+	optimal := straightPathLength(query, startPos[:], endPos[:], path[:pathCount])
+
+	stat = small.FindPathHierarchical(startRef, endRef, startPos[:], endPos[:], filter, path[:], &pathCount, PATH_MAX_NODE)
+	if detour.DtStatusFailed(stat) || detour.DtStatusDetail(stat, detour.DT_PARTIAL_RESULT) {
+		t.Fatalf("FindPathHierarchical: %x", stat)
+	}
+	checkPath(t, mesh, path[:pathCount], startRef, endRef)
+	if length := straightPathLength(query, startPos[:], endPos[:], path[:pathCount]); length > optimal*1.1 {
+		t.Fatalf("hierarchical path is %f long, optimal %f", length, optimal)
+	}
+
+	// The graph follows the tile cache.
+	var walls []dtcache.DtObstacleRef
+	for x := float32(-1010); x < 10; x += 10 {
+		if x > -520 && x < -480 {
+			continue
+		}
+		bmin := [3]float32{x, -100, 450}
+		bmax := [3]float32{x + 11, 100, 455}
+		var wall dtcache.DtObstacleRef
+		tileCache.AddBoxObstacle(bmin[:], bmax[:], &wall)
+		walls = append(walls, wall)
+		for upToDate := false; !upToDate; {
+			tileCache.Update(0, mesh, &upToDate)
+		}
+	}
+	fresh := detour.DtAllocPortalGraph(mesh, filter)
+	for i := 0; i < int(mesh.GetMaxTiles()); i++ {
+		tile := mesh.GetTile(i)
+		if tile.Header != nil && graph.GetPortalCount(tile) != fresh.GetPortalCount(tile) {
+			t.Fatalf("tile %d: %d portals, want %d", i, graph.GetPortalCount(tile), fresh.GetPortalCount(tile))
+		}
+	}
+	detour.DtFreePortalGraph(fresh)
+
+	query.FindNearestPoly(startPos[:], halfExtents[:], filter, &startRef, startPos[:])
+	query.FindNearestPoly(endPos[:], halfExtents[:], filter, &endRef, endPos[:])
+	stat = small.FindPathHierarchical(startRef, endRef, startPos[:], endPos[:], filter, path[:], &pathCount, PATH_MAX_NODE)
+	if detour.DtStatusFailed(stat) || detour.DtStatusDetail(stat, detour.DT_PARTIAL_RESULT) {
+		t.Fatalf("FindPathHierarchical through the gap: %x", stat)
+	}
+	checkPath(t, mesh, path[:pathCount], startRef, endRef)
+	if length := straightPathLength(query, startPos[:], endPos[:], path[:pathCount]); length <= optimal {
+		t.Fatalf("the wall does not make the path longer: %f, was %f", length, optimal)
+	}
+
+	for _, wall := range walls {
+		tileCache.RemoveObstacle(wall)
+		for upToDate := false; !upToDate; {
+			tileCache.Update(0, mesh, &upToDate)
+		}
+	}
+}
+
+type polyChangeCounter struct {
+	changed []detour.DtPolyRef
+}
+
+func (this *polyChangeCounter) OnTileAdded(mesh *detour.DtNavMesh, tile *detour.DtMeshTile)   {}
+func (this *polyChangeCounter) OnTileRemoved(mesh *detour.DtNavMesh, tile *detour.DtMeshTile) {}
+func (this *polyChangeCounter) OnPolyChanged(mesh *detour.DtNavMesh, ref detour.DtPolyRef) {
+	this.changed = append(this.changed, ref)
+}
+
+func Test_PolyChangeNotifications(t *testing.T) {
+	mesh := createOneWayMesh(t)
+	counter := &polyChangeCounter{}
+	mesh.AddListener(counter)
+	graph := detour.DtAllocPortalGraph(mesh, detour.DtAllocDtQueryFilter())
+	defer detour.DtFreePortalGraph(graph)
+	tile := mesh.GetTile(0)
+	base := mesh.GetPolyRefBase(tile)
+
+	size := mesh.GetTileStateSize(tile)
+	state := make([]byte, size)
+	if stat := mesh.StoreTileState(tile, state, size); stat != detour.DT_SUCCESS {
+		t.Fatalf("store: 0x%x", stat)
+	}
+	expect := func(what string, refs ...detour.DtPolyRef) {
+		if len(counter.changed) != len(refs) {
+			t.Fatalf("%s: changed %v, want %v", what, counter.changed, refs)
+		}
+		for i := range refs {
+			if counter.changed[i] != refs[i] {
+				t.Fatalf("%s: changed %v, want %v", what, counter.changed, refs)
+			}
+		}
+		counter.changed = counter.changed[:0]
+	}
+
+	// Nothing is notified when nothing changes.
+	mesh.RestoreTileState(tile, state, size)
+	mesh.SetPolyFlags(base|2, 1)
+	mesh.SetPolyArea(base|3, 0)
+	expect("unchanged")
+
+	mesh.SetPolyFlags(base|2, 3)
+	mesh.SetPolyArea(base|3, 5)
+	expect("changed", base|2, base|3)
+
+	// Restoring notifies the polygons which differ from the state.
+	mesh.RestoreTileState(tile, state, size)
+	expect("restored", base|2, base|3)
+}
@@ -0,0 +1,61 @@
+package tests
+
+import (
+	"testing"
+
+	detour "github.com/fananchong/recastnavigation-go/Detour"
+)
+
+func Test_PCG32(t *testing.T) {
+	// Output of the PCG reference implementation (pcg32-demo) for seed 42, stream 54.
+	expected := []uint32{0xa15c02b7, 0x7b47f409, 0xba1d3330, 0x83d2f293, 0xbfa4784b, 0xcbed606e}
+	rng := detour.DtAllocPCG32(42, 54)
+	for i, v := range expected {
+		if r := rng.Uint32(); r != v {
+			t.Fatalf("value %d: 0x%08x, want 0x%08x", i, r, v)
+		}
+	}
+
+	state, inc := rng.GetState()
+	a := rng.Float32()
+	rng.SetState(state, inc)
+	if b := rng.Float32(); a != b {
+		t.Fatalf("replay: %f, want %f", b, a)
+	}
+
+	for i := 0; i < 100000; i++ {
+		if v := rng.Float32(); v < 0 || v >= 1 {
+			t.Fatalf("Float32 out of range: %f", v)
+		}
+		if v := rng.Uint32n(10); v >= 10 {
+			t.Fatalf("Uint32n out of range: %d", v)
+		}
+	}
+}
+
+func Test_FindRandomPointSeeded(t *testing.T) {
+	mesh, _ := LoadDynamicMesh("scene1.obj.tilecache.bin")
+	query := CreateQuery(mesh, PATH_MAX_NODE)
+	filter := detour.DtAllocDtQueryFilter()
+
+	// The same seed gives the same points, through the interface or the function.
+	var refs [2][8]detour.DtPolyRef
+	var pts [2][8 * 3]float32
+	for k := 0; k < 2; k++ {
+		var rng detour.DtRNG = detour.DtAllocPCG32(7, 1)
+		for i := 0; i < 8; i++ {
+			var stat detour.DtStatus
+			if k == 0 {
+				stat = query.FindRandomPointRNG(filter, rng, &refs[k][i], pts[k][i*3:])
+			} else {
+				stat = query.FindRandomPoint(filter, rng.Float32, &refs[k][i], pts[k][i*3:])
+			}
+			if detour.DtStatusFailed(stat) {
+				t.Fatalf("FindRandomPoint: %x", stat)
+			}
+		}
+	}
+	if refs[0] != refs[1] || pts[0] != pts[1] {
+		t.Fatal("the same seed gave different points")
+	}
+}
@@ -0,0 +1,105 @@
+package tests
+
+import (
+	"math/rand"
+	"testing"
+
+	detour "github.com/fananchong/recastnavigation-go/Detour"
+)
+
+func Test_RaycastBatch(t *testing.T) {
+	mesh, _ := LoadDynamicMesh("scene1.obj.tilecache.bin")
+	query := CreateQuery(mesh, PATH_MAX_NODE)
+	filter := detour.DtAllocDtQueryFilter()
+
+	// Units scattered around the start position, far enough apart to hide behind walls.
+	const count = 40
+	halfExtents := [3]float32{2, 4, 2}
+	rnd := rand.New(rand.NewSource(1))
+	var refs [count]detour.DtPolyRef
+	var positions [count * 3]float32
+	for i := 0; i < count; {
+		pos := [3]float32{-800 + rnd.Float32()*300, 0, 100 + rnd.Float32()*300}
+		query.FindNearestPoly(pos[:], halfExtents[:], filter, &refs[i], positions[i*3:])
+		if refs[i] != 0 {
+			i++
+		}
+	}
+
+	var requests []detour.DtRaycastRequest
+	for i := 0; i < count; i++ {
+		for j := 0; j < count; j++ {
+			var req detour.DtRaycastRequest
+			req.StartRef = refs[i]
+			copy(req.StartPos[:], positions[i*3:i*3+3])
+			copy(req.EndPos[:], positions[j*3:j*3+3])
+			requests = append(requests, req)
+		}
+	}
+	requests[1].StartRef = 0
+	results := make([]detour.DtRaycastResult, len(requests))
+	if stat := query.RaycastBatch(requests, filter, results); stat != detour.DT_SUCCESS {
+		t.Fatalf("batch: 0x%x", stat)
+	}
+	if !detour.DtStatusFailed(results[1].Status) {
+		t.Fatal("a ray without a start polygon was cast")
+	}
+	hits := 0
+	for i := range requests {
+		if i == 1 {
+			continue
+		}
+		req := &requests[i]
+		var hit float32
+		var normal [3]float32
+		var n int
+		query.Raycast(req.StartRef, req.StartPos[:], req.EndPos[:], filter, &hit, normal[:], nil, &n, 0)
+		if results[i].Status != detour.DT_SUCCESS || results[i].T != hit || results[i].HitNormal != normal {
+			t.Fatalf("ray %d differs: %+v", i, results[i])
+		}
+		if hit < 1 {
+			hits++
+		}
+	}
+	if hits == 0 || hits == len(requests)-1 {
+		t.Fatalf("the rays do not test anything: %d hits", hits)
+	}
+
+	// The rays are cast without allocating.
+	if allocs := testing.AllocsPerRun(10, func() { query.RaycastBatch(requests, filter, results) }); allocs != 0 {
+		t.Fatalf("%f allocations per batch", allocs)
+	}
+
+	// The workers find the same results.
+	batch := detour.DtAllocRaycastBatch(mesh, 4)
+	defer detour.DtFreeRaycastBatch(batch)
+	parallel := make([]detour.DtRaycastResult, len(requests))
+	if stat := batch.Raycast(requests, filter, parallel); stat != detour.DT_SUCCESS {
+		t.Fatalf("parallel batch: 0x%x", stat)
+	}
+	for i := range results {
+		if parallel[i] != results[i] {
+			t.Fatalf("ray %d differs on the workers", i)
+		}
+	}
+
+	// The line-of-sight matrix matches the rays from the lower index.
+	var visible [count * count]bool
+	for k := 0; k < 2; k++ {
+		if stat := batch.LineOfSight(refs[:], positions[:], count, filter, visible[:]); stat != detour.DT_SUCCESS {
+			t.Fatalf("line of sight: 0x%x", stat)
+		}
+	}
+	for i := 0; i < count; i++ {
+		for j := 0; j < count; j++ {
+			lo, hi := i, j
+			if lo > hi {
+				lo, hi = hi, lo
+			}
+			expected := lo == hi || results[lo*count+hi].T >= 1
+			if visible[i*count+j] != expected {
+				t.Fatalf("units %d and %d: %v", i, j, visible[i*count+j])
+			}
+		}
+	}
+}
@@ -0,0 +1,153 @@
+package tests
+
+import (
+	"math"
+	"testing"
+
+	detour "github.com/fananchong/recastnavigation-go/Detour"
+)
+
+// shoelace returns the signed area of the polygon on the xz-plane.
+func shoelace(verts []float32, n int) float64 {
+	var area float64
+	for i, j := 0, n-1; i < n; j, i = i, i+1 {
+		area += float64(verts[j*3+0])*float64(verts[i*3+2]) - float64(verts[i*3+0])*float64(verts[j*3+2])
+	}
+	return area / 2
+}
+
+func Test_FindPolysWithinCost(t *testing.T) {
+	mesh, _ := LoadDynamicMesh("scene1.obj.tilecache.bin")
+	query := CreateQuery(mesh, 65535)
+	filter := detour.DtAllocDtQueryFilter()
+
+	halfExtents := [3]float32{2, 4, 2}
+	startPos := [3]float32{-800, 0, 100}
+	var startRef detour.DtPolyRef
+	query.FindNearestPoly(startPos[:], halfExtents[:], filter, &startRef, startPos[:])
+
+	const maxResult = 4096
+	const maxCost = 80
+	var refs, parents [maxResult]detour.DtPolyRef
+	var costs [maxResult]float32
+	var n int
+	stat := query.FindPolysWithinCost(startRef, startPos[:], maxCost, filter, refs[:], parents[:], costs[:], &n, maxResult)
+	if detour.DtStatusFailed(stat) || stat != detour.DT_SUCCESS || n < 2 || refs[0] != startRef {
+		t.Fatalf("FindPolysWithinCost: %x, %d polys", stat, n)
+	}
+	index := make(map[detour.DtPolyRef]int)
+	for i := 0; i < n; i++ {
+		if costs[i] > maxCost || (i > 0 && costs[i] < costs[i-1]) {
+			t.Fatalf("poly %d: cost %f", i, costs[i])
+		}
+		if p, ok := index[parents[i]]; i > 0 && (!ok || p >= i) {
+			t.Fatalf("poly %d: parent %d not found before", i, parents[i])
+		}
+		index[refs[i]] = i
+	}
+
+	// The path to the last polygon goes back to the start.
+	var path [PATH_MAX_NODE]detour.DtPolyRef
+	var pathCount int
+	stat = detour.DtGetPathFromParents(refs[n-1], refs[:], parents[:], n, path[:], &pathCount, PATH_MAX_NODE)
+	if detour.DtStatusFailed(stat) || path[0] != startRef || path[pathCount-1] != refs[n-1] {
+		t.Fatalf("DtGetPathFromParents: %x", stat)
+	}
+
+	// The cost is at least the distance, so everything is within the circle.
+	var circle [maxResult]detour.DtPolyRef
+	var nc int
+	query.FindPolysAroundCircle(startRef, startPos[:], maxCost, filter, circle[:], nil, nil, &nc, maxResult)
+	inCircle := make(map[detour.DtPolyRef]bool)
+	for i := 0; i < nc; i++ {
+		inCircle[circle[i]] = true
+	}
+	for i := 0; i < n; i++ {
+		if !inCircle[refs[i]] {
+			t.Fatalf("poly %d is out of the circle", refs[i])
+		}
+	}
+
+	// Doubling the area costs halves the reachable distance.
+	expensive := detour.DtAllocDtQueryFilter()
+	for i := 0; i < detour.DT_MAX_AREAS; i++ {
+		expensive.SetAreaCost(i, 2*filter.GetAreaCost(i))
+	}
+	var refs2 [maxResult]detour.DtPolyRef
+	var n2, nHalf int
+	query.FindPolysWithinCost(startRef, startPos[:], maxCost, expensive, refs2[:], nil, nil, &n2, maxResult)
+	query.FindPolysWithinCost(startRef, startPos[:], maxCost/2, filter, refs[:], nil, nil, &nHalf, maxResult)
+	if n2 != nHalf || n2 >= n {
+		t.Fatalf("doubled costs reach %d polys, half budget %d", n2, nHalf)
+	}
+	for i := 0; i < n2; i++ {
+		if refs2[i] != refs[i] {
+			t.Fatalf("poly %d: %d, want %d", i, refs2[i], refs[i])
+		}
+	}
+}
+
+func Test_GetPolysContours(t *testing.T) {
+	mesh, _ := LoadDynamicMesh("scene1.obj.tilecache.bin")
+	query := CreateQuery(mesh, 65535)
+	filter := detour.DtAllocDtQueryFilter()
+
+	halfExtents := [3]float32{2, 4, 2}
+	startPos := [3]float32{-800, 0, 100}
+	var startRef detour.DtPolyRef
+	query.FindNearestPoly(startPos[:], halfExtents[:], filter, &startRef, startPos[:])
+
+	const maxResult = 4096
+	var refs [maxResult]detour.DtPolyRef
+	var n int
+	query.FindPolysWithinCost(startRef, startPos[:], 150, filter, refs[:], nil, nil, &n, maxResult)
+
+	const maxVerts = 8192
+	var verts [maxVerts * 3]float32
+	var contours [256]int32
+	var nverts, ncontours int
+
+	// A single polygon is its own contour.
+	var tile *detour.DtMeshTile
+	var poly *detour.DtPoly
+	mesh.GetTileAndPolyByRef(startRef, &tile, &poly)
+	stat := query.GetPolysContours(refs[:], 1, verts[:], &nverts, maxVerts, contours[:], &ncontours, len(contours))
+	if detour.DtStatusFailed(stat) || ncontours != 1 || nverts != int(poly.VertCount) {
+		t.Fatalf("single poly: %x, %d contours, %d verts", stat, ncontours, nverts)
+	}
+
+	// The contours enclose the area of the polygons, holes counting negative.
+	stat = query.GetPolysContours(refs[:], n, verts[:], &nverts, maxVerts, contours[:], &ncontours, len(contours))
+	if stat != detour.DT_SUCCESS || ncontours == 0 {
+		t.Fatalf("GetPolysContours: %x, %d contours", stat, ncontours)
+	}
+	var polyArea, contourArea float64
+	for i := 0; i < n; i++ {
+		mesh.GetTileAndPolyByRef(refs[i], &tile, &poly)
+		var pv [detour.DT_VERTS_PER_POLYGON * 3]float32
+		for j := 0; j < int(poly.VertCount); j++ {
+			detour.DtVcopy(pv[j*3:], tile.Verts[poly.Verts[j]*3:])
+		}
+		polyArea += shoelace(pv[:], int(poly.VertCount))
+	}
+	v := 0
+	for i := 0; i < ncontours; i++ {
+		if contours[i] < 3 {
+			t.Fatalf("contour %d has %d verts", i, contours[i])
+		}
+		contourArea += shoelace(verts[v*3:], int(contours[i]))
+		v += int(contours[i])
+	}
+	if v != nverts {
+		t.Fatalf("contours have %d verts, want %d", v, nverts)
+	}
+	if math.Abs(polyArea-contourArea) > math.Abs(polyArea)*1e-3 {
+		t.Fatalf("contour area %f, polygon area %f", contourArea, polyArea)
+	}
+
+	// Too small arrays keep the contours which fit.
+	stat = query.GetPolysContours(refs[:], n, verts[:], &nverts, 4, contours[:], &ncontours, len(contours))
+	if !detour.DtStatusDetail(stat, detour.DT_BUFFER_TOO_SMALL) || nverts > 4 {
+		t.Fatalf("small buffer: %x, %d verts", stat, nverts)
+	}
+}
@@ -0,0 +1,97 @@
+package tests
+
+import (
+	"math/rand"
+	"testing"
+
+	detour "github.com/fananchong/recastnavigation-go/Detour"
+)
+
+func Test_NavMeshSampler(t *testing.T) {
+	mesh, _ := LoadDynamicMesh("scene1.obj.tilecache.bin")
+	filter := detour.DtAllocDtQueryFilter()
+	sampler := detour.DtAllocNavMeshSampler(mesh, filter)
+	defer detour.DtFreeNavMeshSampler(sampler)
+
+	// Area of the walkable polygons per tile, and per block of 250x250 units.
+	tileAreas := make(map[detour.DtTileRef]float32)
+	blockAreas := make(map[int]float32)
+	block := func(tile *detour.DtMeshTile) int {
+		return int((tile.Header.Bmin[0]+1000)/250)*8 + int(tile.Header.Bmin[2]/250)
+	}
+	var totalArea float32
+	for i := 0; i < int(mesh.GetMaxTiles()); i++ {
+		tile := mesh.GetTile(i)
+		if tile == nil || tile.Header == nil {
+			continue
+		}
+		for j := 0; j < int(tile.Header.PolyCount); j++ {
+			p := &tile.Polys[j]
+			if p.GetType() != detour.DT_POLYTYPE_GROUND || p.Flags == 0 {
+				continue
+			}
+			for k := 2; k < int(p.VertCount); k++ {
+				area := detour.DtTriArea2D(tile.Verts[p.Verts[0]*3:], tile.Verts[p.Verts[k-1]*3:], tile.Verts[p.Verts[k]*3:])
+				tileAreas[mesh.GetTileRef(tile)] += area
+				blockAreas[block(tile)] += area
+				totalArea += area
+			}
+		}
+	}
+	// Allow for the float summation order.
+	tolerance := totalArea * 1e-4
+	if diff := sampler.GetTotalArea() - totalArea; diff > tolerance || diff < -tolerance {
+		t.Fatalf("total area: %f, want %f", sampler.GetTotalArea(), totalArea)
+	}
+
+	const N = 100000
+	rnd := rand.New(rand.NewSource(1))
+	refs := make([]detour.DtPolyRef, N)
+	pts := make([]float32, N*3)
+	var count int
+	if stat := sampler.SampleUniformN(rnd, refs, pts, &count, N); detour.DtStatusFailed(stat) || count != N {
+		t.Fatalf("sampled %d points: %x", count, stat)
+	}
+
+	// The share of the samples in a block must follow the share of its area.
+	hits := make(map[int]int)
+	for i := 0; i < N; i++ {
+		var tile *detour.DtMeshTile
+		var poly *detour.DtPoly
+		if detour.DtStatusFailed(mesh.GetTileAndPolyByRef(refs[i], &tile, &poly)) {
+			t.Fatalf("invalid sample ref %d", refs[i])
+		}
+		hits[block(tile)]++
+	}
+	var deviation float32
+	for b, area := range blockAreas {
+		d := float32(hits[b])/N - area/totalArea
+		if d < 0 {
+			d = -d
+		}
+		deviation += d
+	}
+	if deviation > 0.03 {
+		t.Fatalf("samples do not follow the area: total deviation %f", deviation)
+	}
+
+	// Removed tiles are not sampled anymore.
+	var removed detour.DtTileRef
+	for ref := range tileAreas {
+		if tileAreas[ref] > tileAreas[removed] {
+			removed = ref
+		}
+	}
+	mesh.RemoveTile(removed, nil, nil)
+	if diff := sampler.GetTotalArea() - (totalArea - tileAreas[removed]); diff > tolerance || diff < -tolerance {
+		t.Fatalf("total area after removal: %f, want %f", sampler.GetTotalArea(), totalArea-tileAreas[removed])
+	}
+	for i := 0; i < 1000; i++ {
+		var ref detour.DtPolyRef
+		var pt [3]float32
+		sampler.SampleUniform(rnd, &ref, pt[:])
+		if !mesh.IsValidPolyRef(ref) {
+			t.Fatalf("sampled invalid poly %d", ref)
+		}
+	}
+}
@@ -0,0 +1,136 @@
+package tests
+
+import (
+	"testing"
+
+	detour "github.com/fananchong/recastnavigation-go/Detour"
+)
+
+// checkWalkable fails if a segment of the path leaves the navmesh.
+func checkWalkable(t *testing.T, query *detour.DtNavMeshQuery, filter *detour.DtQueryFilter, pts []float32, npts int) {
+	halfExtents := [3]float32{0.5, 4, 0.5}
+	for i := 1; i < npts; i++ {
+		steps := int(detour.DtVdist2D(pts[(i-1)*3:], pts[i*3:])/0.1) + 1
+		for k := 0; k <= steps; k++ {
+			var pos, nearest [3]float32
+			detour.DtVlerp(pos[:], pts[(i-1)*3:], pts[i*3:], float32(k)/float32(steps))
+			var ref detour.DtPolyRef
+			query.FindNearestPoly(pos[:], halfExtents[:], filter, &ref, nearest[:])
+			if ref == 0 || detour.DtVdist2D(pos[:], nearest[:]) > 0.01 {
+				t.Fatalf("segment %d leaves the navmesh at %v", i, pos)
+			}
+		}
+	}
+}
+
+func Test_SmoothPath(t *testing.T) {
+	mesh, _ := LoadDynamicMesh("scene1.obj.tilecache.bin")
+	query := CreateQuery(mesh, 65535)
+	filter := detour.DtAllocDtQueryFilter()
+
+	halfExtents := [3]float32{2, 4, 2}
+	startPos := [3]float32{-800, 0, 100}
+	endPos := [3]float32{-200, 0, 880}
+	var startRef, endRef detour.DtPolyRef
+	query.FindNearestPoly(startPos[:], halfExtents[:], filter, &startRef, startPos[:])
+	query.FindNearestPoly(endPos[:], halfExtents[:], filter, &endRef, endPos[:])
+
+	var path [PATH_MAX_NODE]detour.DtPolyRef
+	var pathCount int
+	query.FindPath(startRef, endRef, startPos[:], endPos[:], filter, path[:], &pathCount, PATH_MAX_NODE)
+	var straight [PATH_MAX_NODE * 3]float32
+	var straightRefs [PATH_MAX_NODE]detour.DtPolyRef
+	var straightCount int
+	query.FindStraightPath(startPos[:], endPos[:], path[:], pathCount, straight[:], nil, straightRefs[:], &straightCount, PATH_MAX_NODE, 0)
+	if straightCount < 3 {
+		t.Fatalf("the straight path has no corner: %d", straightCount)
+	}
+
+	const subdivisions = 8
+	var smooth [PATH_MAX_NODE * 8 * 3]float32
+	var smoothCount int
+	for _, c := range []struct {
+		name string
+		run  func() detour.DtStatus
+	}{
+		{"catmull-rom", func() detour.DtStatus {
+			return query.SmoothPath(straight[:], straightRefs[:], straightCount, filter, detour.DT_SMOOTH_CATMULL_ROM, subdivisions, smooth[:], &smoothCount, len(smooth)/3)
+		}},
+		{"bezier", func() detour.DtStatus {
+			return query.SmoothPath(straight[:], straightRefs[:], straightCount, filter, detour.DT_SMOOTH_BEZIER, subdivisions, smooth[:], &smoothCount, len(smooth)/3)
+		}},
+		{"round", func() detour.DtStatus {
+			return query.RoundPathCorners(straight[:], straightRefs[:], straightCount, filter, 0.6, subdivisions, smooth[:], &smoothCount, len(smooth)/3)
+		}},
+	} {
+		if stat := c.run(); stat != detour.DT_SUCCESS {
+			t.Fatalf("%s: 0x%x", c.name, stat)
+		}
+		if smoothCount <= straightCount {
+			t.Fatalf("%s: no corner was smoothed", c.name)
+		}
+		if !detour.DtVequal(smooth[:], straight[:]) || !detour.DtVequal(smooth[(smoothCount-1)*3:], straight[(straightCount-1)*3:]) {
+			t.Fatalf("%s: the path end points moved", c.name)
+		}
+		checkWalkable(t, query, filter, smooth[:], smoothCount)
+	}
+
+	// The rounded corners keep away from the corners.
+	query.RoundPathCorners(straight[:], straightRefs[:], straightCount, filter, 0.6, subdivisions, smooth[:], &smoothCount, len(smooth)/3)
+	for i := 1; i+1 < straightCount; i++ {
+		for j := 1; j+1 < smoothCount; j++ {
+			if d := detour.DtVdist2D(straight[i*3:], smooth[j*3:]); d > 1e-3 && d < 0.6*0.99 && d*2 < detour.DtVdist2D(straight[(i-1)*3:], straight[i*3:]) {
+				t.Fatalf("point %d is %f from corner %d", j, d, i)
+			}
+		}
+	}
+
+	// A short buffer is filled from the start.
+	if stat := query.SmoothPath(straight[:], straightRefs[:], straightCount, filter, detour.DT_SMOOTH_BEZIER, subdivisions, smooth[:], &smoothCount, 4); stat != detour.DT_SUCCESS|detour.DT_BUFFER_TOO_SMALL || smoothCount != 4 {
+		t.Fatalf("short buffer: 0x%x %d", stat, smoothCount)
+	}
+}
+
+func Test_SmoothPathOffMesh(t *testing.T) {
+	mesh := createOneWayMesh(t)
+	query := CreateQuery(mesh, 64)
+	filter := detour.DtAllocDtQueryFilter()
+
+	halfExtents := [3]float32{1, 1, 1}
+	startPos := [3]float32{2, 0, 8}
+	endPos := [3]float32{28, 0, 2}
+	var startRef, endRef detour.DtPolyRef
+	query.FindNearestPoly(startPos[:], halfExtents[:], filter, &startRef, startPos[:])
+	query.FindNearestPoly(endPos[:], halfExtents[:], filter, &endRef, endPos[:])
+
+	var path [16]detour.DtPolyRef
+	var pathCount int
+	query.FindPath(startRef, endRef, startPos[:], endPos[:], filter, path[:], &pathCount, len(path))
+	var straight [16 * 3]float32
+	var straightRefs [16]detour.DtPolyRef
+	var straightCount int
+	query.FindStraightPath(startPos[:], endPos[:], path[:], pathCount, straight[:], nil, straightRefs[:], &straightCount, 16, 0)
+	if straightCount != 4 {
+		t.Fatalf("unexpected straight path of %d points", straightCount)
+	}
+
+	// The end points of the off-mesh connection are kept.
+	var smooth [64 * 3]float32
+	var smoothCount int
+	for _, smoothType := range []detour.DtSmoothPathType{detour.DT_SMOOTH_CATMULL_ROM, detour.DT_SMOOTH_BEZIER} {
+		query.SmoothPath(straight[:], straightRefs[:], straightCount, filter, smoothType, 4, smooth[:], &smoothCount, 64)
+		for i := 1; i <= 2; i++ {
+			found := false
+			for j := 0; j < smoothCount; j++ {
+				found = found || detour.DtVequal(smooth[j*3:], straight[i*3:])
+			}
+			if !found {
+				t.Fatalf("type %d: off-mesh point %d was smoothed", smoothType, i)
+			}
+		}
+	}
+	query.RoundPathCorners(straight[:], straightRefs[:], straightCount, filter, 0.5, 4, smooth[:], &smoothCount, 64)
+	if smoothCount != straightCount {
+		t.Fatalf("off-mesh corners were rounded: %d points", smoothCount)
+	}
+}
@@ -0,0 +1,290 @@
+package tests
+
+import (
+	"math"
+	"testing"
+
+	detour "github.com/fananchong/recastnavigation-go/Detour"
+)
+
+func Test_TimeWindows(t *testing.T) {
+	schedule := detour.DtAllocTimeWindows()
+	const door, elevator, wall detour.DtPolyRef = 1, 2, 3
+	schedule.SetWindows(door, []detour.DtTimeWindow{{Start: 30, End: 40}, {Start: 5, End: 10}}, 0)
+	schedule.SetWindows(elevator, []detour.DtTimeWindow{{Start: 10, End: 12}}, 20)
+	schedule.SetWindows(wall, nil, 0)
+
+	for _, c := range []struct {
+		ref        detour.DtPolyRef
+		t, entered float32
+	}{
+		{door, 0, 5},
+		{door, 7, 7},
+		{door, 10, 30},
+		{door, 40, -1},
+		{elevator, 0, 10},
+		{elevator, 11, 11},
+		{elevator, 12, 30},
+		{elevator, 45, 50},
+		{elevator, -5, 10},
+		{wall, 0, -1},
+		{4, 3, 3},
+	} {
+		if entered := schedule.GetEntryTime(c.ref, c.t); entered != c.entered {
+			t.Fatalf("poly %d reached at %f: entered at %f, expected %f", c.ref, c.t, entered, c.entered)
+		}
+	}
+
+	if !detour.DtStatusFailed(schedule.SetWindows(elevator, []detour.DtTimeWindow{{Start: 15, End: 25}}, 20)) {
+		t.Fatal("a window longer than its period was accepted")
+	}
+	if !detour.DtStatusFailed(schedule.SetWindows(door, []detour.DtTimeWindow{{Start: 5, End: 5}}, 0)) {
+		t.Fatal("an empty window was accepted")
+	}
+	schedule.Remove(door)
+	if entered := schedule.GetEntryTime(door, 10); entered != 10 {
+		t.Fatalf("removed door entered at %f", entered)
+	}
+}
+
+func Test_FindTimedPathElevator(t *testing.T) {
+	mesh := createOneWayMesh(t)
+	query := CreateQuery(mesh, 64)
+	filter := detour.DtAllocDtQueryFilter()
+
+	halfExtents := [3]float32{1, 1, 1}
+	leftPos := [3]float32{5, 0, 5}
+	rightPos := [3]float32{25, 0, 5}
+	var leftRef, rightRef detour.DtPolyRef
+	query.FindNearestPoly(leftPos[:], halfExtents[:], filter, &leftRef, leftPos[:])
+	query.FindNearestPoly(rightPos[:], halfExtents[:], filter, &rightRef, rightPos[:])
+
+	var path [PATH_MAX_NODE]detour.DtPolyRef
+	var times, waits [PATH_MAX_NODE]float32
+	var pathCount int
+	var arrival float32
+	findTimedPath := func(startTime, waitCost float32, schedule detour.DtTraversalSchedule) {
+		stat := query.FindTimedPath(leftRef, rightRef, leftPos[:], rightPos[:], startTime, 2, waitCost, filter, schedule,
+			path[:], times[:], waits[:], &arrival, &pathCount, PATH_MAX_NODE)
+		if !detour.DtStatusSucceed(stat) || detour.DtStatusDetail(stat, detour.DT_PARTIAL_RESULT) {
+			t.Fatalf("timed path failed: 0x%x", stat)
+		}
+		checkPath(t, mesh, path[:pathCount], leftRef, rightRef)
+		if times[0] != startTime || waits[0] != 0 {
+			t.Fatalf("unexpected start time %f and wait %f", times[0], waits[0])
+		}
+		for i := 1; i < pathCount; i++ {
+			if times[i] < times[i-1]+waits[i] || waits[i] < 0 {
+				t.Fatalf("time goes back at %d: %v, waits %v", i, times[:pathCount], waits[:pathCount])
+			}
+		}
+		if arrival < times[pathCount-1] {
+			t.Fatalf("arrival %f before the end polygon is entered at %f", arrival, times[pathCount-1])
+		}
+	}
+
+	// Without a schedule the elevator is taken right away. The distances are
+	// about 10 along the elevator, and 70 along the corridor.
+	findTimedPath(0, 1, nil)
+	if pathCount != 3 || waits[1] != 0 || arrival > 11 {
+		t.Fatalf("unexpected path of %d polygons, arriving at %f", pathCount, arrival)
+	}
+	elevatorRef := path[1]
+
+	// The elevator leaves for 2 seconds every 20 seconds.
+	schedule := detour.DtAllocTimeWindows()
+	schedule.SetWindows(elevatorRef, []detour.DtTimeWindow{{Start: 10, End: 12}}, 20)
+
+	// Cheap waits, the agent waits for the elevator.
+	findTimedPath(0, 0.5, schedule)
+	if pathCount != 3 || path[1] != elevatorRef || !IsEquals(times[1], 10) || !IsEquals(waits[1], 10) {
+		t.Fatalf("expected to wait for the elevator: %v, times %v, waits %v", path[:pathCount], times[:pathCount], waits[:pathCount])
+	}
+	if arrival < 10 || arrival > 21 {
+		t.Fatalf("unexpected arrival %f", arrival)
+	}
+
+	// Starting when the elevator is about to leave, the agent does not wait.
+	findTimedPath(10, 0.5, schedule)
+	if pathCount != 3 || waits[1] != 0 {
+		t.Fatalf("expected to take the elevator right away: waits %v", waits[:pathCount])
+	}
+
+	// Expensive waits, the agent walks along the corridor.
+	findTimedPath(0, 10, schedule)
+	if pathCount != 7 || arrival < 30 {
+		t.Fatalf("expected to walk along the corridor: %v, arriving at %f", path[:pathCount], arrival)
+	}
+	for i := 0; i < pathCount; i++ {
+		if waits[i] != 0 {
+			t.Fatalf("unexpected wait at %d: %v", i, waits[:pathCount])
+		}
+	}
+
+	// An elevator which never leaves again.
+	schedule.SetWindows(elevatorRef, []detour.DtTimeWindow{{Start: 10, End: 12}}, 0)
+	findTimedPath(15, 0, schedule)
+	if pathCount != 7 {
+		t.Fatalf("expected to walk along the corridor: %v", path[:pathCount])
+	}
+
+	// A truncated path keeps the start, with its times.
+	stat := query.FindTimedPath(leftRef, rightRef, leftPos[:], rightPos[:], 0, 2, 0, filter, schedule,
+		path[:], times[:], waits[:], &arrival, &pathCount, 2)
+	if !detour.DtStatusDetail(stat, detour.DT_BUFFER_TOO_SMALL) || pathCount != 2 || path[0] != leftRef || times[0] != 0 {
+		t.Fatalf("unexpected truncated path: 0x%x %v", stat, path[:pathCount])
+	}
+}
+
+func Test_FindTimedPathNoSchedule(t *testing.T) {
+	mesh, _ := LoadDynamicMesh("scene1.obj.tilecache.bin")
+	query := CreateQuery(mesh, 65535)
+	filter := detour.DtAllocDtQueryFilter()
+
+	halfExtents := [3]float32{2, 4, 2}
+	startPos := [3]float32{-800, 0, 100}
+	endPos := [3]float32{-200, 0, 880}
+	var startRef, endRef detour.DtPolyRef
+	query.FindNearestPoly(startPos[:], halfExtents[:], filter, &startRef, startPos[:])
+	query.FindNearestPoly(endPos[:], halfExtents[:], filter, &endRef, endPos[:])
+
+	var path, timed [PATH_MAX_NODE]detour.DtPolyRef
+	var pathCount, timedCount int
+	query.FindPath(startRef, endRef, startPos[:], endPos[:], filter, path[:], &pathCount, PATH_MAX_NODE)
+	var arrival float32
+	stat := query.FindTimedPath(startRef, endRef, startPos[:], endPos[:], 0, 1, 1, filter, nil,
+		timed[:], nil, nil, &arrival, &timedCount, PATH_MAX_NODE)
+	if !detour.DtStatusSucceed(stat) || detour.DtStatusDetail(stat, detour.DT_PARTIAL_RESULT) {
+		t.Fatalf("timed path failed: 0x%x", stat)
+	}
+	// The search keeps several nodes per polygon, corridors of the same cost may swap.
+	checkPath(t, mesh, timed[:timedCount], startRef, endRef)
+	cost := corridorCost(mesh, path[:pathCount], startPos[:], endPos[:])
+	if timedCost := corridorCost(mesh, timed[:timedCount], startPos[:], endPos[:]); math.Abs(timedCost-cost) > 1e-5*cost {
+		t.Fatalf("timed path costs %f, expected %f", timedCost, cost)
+	}
+	if direct := detour.DtVdist(startPos[:], endPos[:]); arrival < direct {
+		t.Fatalf("arrival %f is earlier than the straight line allows %f", arrival, direct)
+	}
+}
+
+func Test_FindTimedPathEarlierRoute(t *testing.T) {
+	// A short route through a gate and a long route around merge before a door.
+	const NONE = 0xffff
+	quads := [][4]uint16{
+		{0, 0, 10, 10},   // 0: start
+		{10, 0, 20, 10},  // 1: gate
+		{20, 0, 30, 10},  // 2: merge
+		{30, 0, 40, 10},  // 3: door
+		{40, 0, 50, 10},  // 4: end
+		{0, 10, 10, 40},  // 5: long route up
+		{10, 30, 20, 40}, // 6: long route across
+		{20, 10, 30, 40}, // 7: long route down
+	}
+	neis := [][4]uint16{
+		{NONE, 5, 1, NONE},
+		{0, NONE, 2, NONE},
+		{1, 7, 3, NONE},
+		{2, NONE, 4, NONE},
+		{3, NONE, NONE, NONE},
+		{NONE, NONE, 6, 0},
+		{5, NONE, 7, NONE},
+		{6, NONE, NONE, 2},
+	}
+	mesh := createQuadMesh(t, quads, neis, nil)
+	query := CreateQuery(mesh, 64)
+	filter := detour.DtAllocDtQueryFilter()
+	base := mesh.GetPolyRefBase(mesh.GetTile(0))
+
+	// The gate opens late, waiting for it is free but the merge polygon is then
+	// reached after the door has closed.
+	schedule := detour.DtAllocTimeWindows()
+	schedule.SetWindows(base|1, []detour.DtTimeWindow{{Start: 40, End: 1000}}, 0)
+	schedule.SetWindows(base|3, []detour.DtTimeWindow{{Start: 0, End: 40}}, 0)
+
+	startPos := [3]float32{5, 0, 5}
+	endPos := [3]float32{45, 0, 5}
+	var path [PATH_MAX_NODE]detour.DtPolyRef
+	var times, waits [PATH_MAX_NODE]float32
+	var pathCount int
+	var arrival float32
+	stat := query.FindTimedPath(base|0, base|4, startPos[:], endPos[:], 0, 2, 0, filter, schedule,
+		path[:], times[:], waits[:], &arrival, &pathCount, PATH_MAX_NODE)
+	if !detour.DtStatusSucceed(stat) || detour.DtStatusDetail(stat, detour.DT_PARTIAL_RESULT) {
+		t.Fatalf("timed path failed: 0x%x %v", stat, path[:pathCount])
+	}
+	expected := []detour.DtPolyRef{base | 0, base | 5, base | 6, base | 7, base | 2, base | 3, base | 4}
+	if pathCount != len(expected) {
+		t.Fatalf("expected the long route: %v", path[:pathCount])
+	}
+	for i := range expected {
+		if path[i] != expected[i] || waits[i] != 0 {
+			t.Fatalf("expected the long route without waits: %v, waits %v", path[:pathCount], waits[:pathCount])
+		}
+	}
+	if times[5] >= 40 {
+		t.Fatalf("the door is entered at %f", times[5])
+	}
+}
+
+func Test_FindTimedPathDoors(t *testing.T) {
+	mesh, _ := LoadDynamicMesh("scene1.obj.tilecache.bin")
+	query := CreateQuery(mesh, 65535)
+	filter := detour.DtAllocDtQueryFilter()
+
+	halfExtents := [3]float32{2, 4, 2}
+	startPos := [3]float32{-800, 0, 100}
+	endPos := [3]float32{-200, 0, 880}
+	var startRef, endRef detour.DtPolyRef
+	query.FindNearestPoly(startPos[:], halfExtents[:], filter, &startRef, startPos[:])
+	query.FindNearestPoly(endPos[:], halfExtents[:], filter, &endRef, endPos[:])
+
+	// Doors opening at different times all over the mesh.
+	schedule := detour.DtAllocTimeWindows()
+	for i := 0; i < int(mesh.GetMaxTiles()); i++ {
+		tile := mesh.GetTile(i)
+		if tile.Header == nil {
+			continue
+		}
+		base := mesh.GetPolyRefBase(tile)
+		for j := 0; j < int(tile.Header.PolyCount); j += 3 {
+			ref := base | detour.DtPolyRef(j)
+			if ref == startRef || ref == endRef {
+				continue
+			}
+			start := float32((i*7+j*13)%50) * 10
+			schedule.SetWindows(ref, []detour.DtTimeWindow{{Start: start, End: start + 20}}, 600)
+		}
+	}
+
+	var path [PATH_MAX_NODE]detour.DtPolyRef
+	var times, waits [PATH_MAX_NODE]float32
+	var pathCount int
+	var arrival float32
+	stat := query.FindTimedPath(startRef, endRef, startPos[:], endPos[:], 0, 5, 0.5, filter, schedule,
+		path[:], times[:], waits[:], &arrival, &pathCount, PATH_MAX_NODE)
+	if !detour.DtStatusSucceed(stat) || detour.DtStatusDetail(stat, detour.DT_PARTIAL_RESULT) {
+		t.Fatalf("timed path failed: 0x%x", stat)
+	}
+	checkPath(t, mesh, path[:pathCount], startRef, endRef)
+	for i := 1; i < pathCount; i++ {
+		if times[i]-waits[i] < times[i-1] {
+			t.Fatalf("poly %d is reached at %f, before poly %d is entered at %f", i, times[i]-waits[i], i-1, times[i-1])
+		}
+		if entered := schedule.GetEntryTime(path[i], times[i]); entered != times[i] {
+			t.Fatalf("poly %d is entered at %f while closed", i, times[i])
+		}
+	}
+
+	// The search may keep several nodes per polygon, the closed list sees them all.
+	pool := query.GetNodePool()
+	for i := uint32(1); i <= pool.GetNodeCount(); i++ {
+		node := pool.GetNodeAtIdx(i)
+		if int(node.State) >= detour.DT_MAX_STATES_PER_NODE {
+			t.Fatalf("node %d has state %d", i, node.State)
+		}
+		if (node.Flags&detour.DT_NODE_CLOSED) != 0 && !query.IsInClosedList(node.Id) {
+			t.Fatalf("closed polygon %d is not in the closed list", node.Id)
+		}
+	}
+}
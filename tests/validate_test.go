@@ -0,0 +1,164 @@
+package tests
+
+import (
+	"testing"
+
+	detour "github.com/fananchong/recastnavigation-go/Detour"
+)
+
+func countIssues(issues []detour.DtNavMeshIssue, t detour.DtNavMeshIssueType) (errors int, warnings int) {
+	for i := range issues {
+		if issues[i].Type != t {
+			continue
+		}
+		if issues[i].Warning {
+			warnings++
+		} else {
+			errors++
+		}
+	}
+	return
+}
+
+func firstLinkedGroundPoly(mesh *detour.DtNavMesh) (*detour.DtMeshTile, int) {
+	for i := 0; i < int(mesh.GetMaxTiles()); i++ {
+		tile := mesh.GetTile(i)
+		if tile == nil || tile.Header == nil {
+			continue
+		}
+		for j := 0; j < int(tile.Header.PolyCount); j++ {
+			poly := &tile.Polys[j]
+			if poly.GetType() != detour.DT_POLYTYPE_GROUND || poly.FirstLink == detour.DT_NULL_LINK {
+				continue
+			}
+			if tile.Links[poly.FirstLink].Side == 0xff {
+				return tile, j
+			}
+		}
+	}
+	return nil, -1
+}
+
+func Test_ValidateNavMesh(t *testing.T) {
+	mesh, _ := LoadDynamicMesh("scene1.obj.tilecache.bin")
+	for _, issue := range detour.DtValidateNavMesh(mesh) {
+		if !issue.Warning {
+			t.Fatal(issue.String())
+		}
+	}
+
+	tile, ip := firstLinkedGroundPoly(mesh)
+	if tile == nil {
+		t.Fatal("no linked poly")
+	}
+	poly := &tile.Polys[ip]
+	link := &tile.Links[poly.FirstLink]
+
+	// Break the internal neighbour of the link edge.
+	nei := poly.Neis[link.Edge]
+	poly.Neis[link.Edge] = 0
+	issues := detour.DtValidateMeshTile(mesh, tile)
+	if errors, _ := countIssues(issues, detour.DT_ISSUE_NEIS_MISMATCH); errors == 0 {
+		t.Fatal("neis mismatch not detected")
+	}
+	poly.Neis[link.Edge] = nei
+
+	// Point the link to a poly which does not link back.
+	ref := link.Ref
+	link.Ref = mesh.GetPolyRefBase(tile) | detour.DtPolyRef(ip)
+	issues = detour.DtValidateMeshTile(mesh, tile)
+	if errors, _ := countIssues(issues, detour.DT_ISSUE_ASYMMETRIC_LINK); errors == 0 {
+		t.Fatal("asymmetric link not detected")
+	}
+	link.Ref = ref
+
+	// Reference a detail vertex which does not exist.
+	pd := &tile.DetailMeshes[ip]
+	tri := tile.DetailTris[pd.TriBase*4:]
+	tri[0] = uint8(int(poly.VertCount) + int(pd.VertCount))
+	issues = detour.DtValidateMeshTile(mesh, tile)
+	if errors, _ := countIssues(issues, detour.DT_ISSUE_DETAIL_MESH); errors == 0 {
+		t.Fatal("bad detail triangle not detected")
+	}
+}
+
+func Test_ValidateNavMeshCorrupted(t *testing.T) {
+	mesh := createOneWayMesh(t)
+	tile := mesh.GetTile(0)
+	for _, issue := range detour.DtValidateMeshTile(mesh, tile) {
+		t.Fatal(issue.String())
+	}
+	expect := func(what string, issueType detour.DtNavMeshIssueType) {
+		issues := detour.DtValidateMeshTile(mesh, tile)
+		if errors, _ := countIssues(issues, issueType); errors == 0 {
+			t.Fatalf("%s not detected: %v", what, issues)
+		}
+	}
+
+	// Shrink a leaf so that it does not contain its poly.
+	for i := range tile.BvTree {
+		node := &tile.BvTree[i]
+		if node.I < 0 {
+			continue
+		}
+		bmax := node.Bmax[0]
+		node.Bmax[0] = node.Bmin[0]
+		expect("shrunk BV node", detour.DT_ISSUE_BVTREE)
+		node.Bmax[0] = bmax
+		break
+	}
+
+	// Move the start of the off-mesh connection away from the mesh.
+	con := &tile.OffMeshCons[0]
+	z := con.Pos[2]
+	con.Pos[2] = -20
+	expect("off-mesh connection off the mesh", detour.DT_ISSUE_OFFMESH_UNCONNECTED)
+	con.Pos[2] = z
+
+	// Swap two corners of a quad, which makes it self-intersecting.
+	poly := &tile.Polys[0]
+	poly.Verts[1], poly.Verts[2] = poly.Verts[2], poly.Verts[1]
+	expect("self-intersecting poly area", detour.DT_ISSUE_DEGENERATE_POLY)
+	expect("self-intersecting poly", detour.DT_ISSUE_NONCONVEX_POLY)
+	poly.Verts[1], poly.Verts[2] = poly.Verts[2], poly.Verts[1]
+
+	// Broken vertices are reported without reading them, also from the BV tree.
+	if tile.BvTree == nil {
+		t.Fatal("no BV tree")
+	}
+	vert := poly.Verts[2]
+	poly.Verts[2] = 0xfff0
+	expect("vertex index out of range", detour.DT_ISSUE_DEGENERATE_POLY)
+	poly.Verts[2] = vert
+	vertCount := poly.VertCount
+	poly.VertCount = 200
+	expect("too many vertices", detour.DT_ISSUE_DEGENERATE_POLY)
+	poly.VertCount = vertCount
+	offMeshPoly := &tile.Polys[con.Poly]
+	vert = offMeshPoly.Verts[1]
+	offMeshPoly.Verts[1] = 0xfff0
+	expect("off-mesh vertex index out of range", detour.DT_ISSUE_DEGENERATE_POLY)
+	offMeshPoly.Verts[1] = vert
+
+	// Point a link to a poly whose tile has been replaced.
+	link := &tile.Links[poly.FirstLink]
+	ref := link.Ref
+	var salt, it, ip uint32
+	mesh.DecodePolyId(ref, &salt, &it, &ip)
+	link.Ref = mesh.EncodePolyId(salt+1, it, ip)
+	expect("dangling link", detour.DT_ISSUE_DANGLING_LINK)
+	link.Ref = ref
+
+	// Make the link list loop back to its head.
+	last := poly.FirstLink
+	for tile.Links[last].Next != detour.DT_NULL_LINK {
+		last = tile.Links[last].Next
+	}
+	tile.Links[last].Next = poly.FirstLink
+	expect("cyclic link list", detour.DT_ISSUE_BROKEN_LINK_LIST)
+	tile.Links[last].Next = detour.DT_NULL_LINK
+
+	for _, issue := range detour.DtValidateMeshTile(mesh, tile) {
+		t.Fatal(issue.String())
+	}
+}
@@ -0,0 +1,88 @@
+package tests
+
+import (
+	"math"
+	"testing"
+
+	detour "github.com/fananchong/recastnavigation-go/Detour"
+	dtcache "github.com/fananchong/recastnavigation-go/DetourTileCache"
+)
+
+func Test_FindVisibilityPolygon(t *testing.T) {
+	mesh, tileCache := LoadDynamicMesh("scene1.obj.tilecache.bin")
+	query := CreateQuery(mesh, PATH_MAX_NODE)
+	filter := detour.DtAllocDtQueryFilter()
+
+	for x := float32(-810); x < -690; x += 10 {
+		bmin := [3]float32{x, -100, 450}
+		bmax := [3]float32{x + 11, 100, 455}
+		var wall dtcache.DtObstacleRef
+		if stat := tileCache.AddBoxObstacle(bmin[:], bmax[:], &wall); detour.DtStatusFailed(stat) {
+			t.Fatalf("add obstacle: %x", stat)
+		}
+		for upToDate := false; !upToDate; {
+			tileCache.Update(0, mesh, &upToDate)
+		}
+	}
+
+	halfExtents := [3]float32{2, 4, 2}
+	centerPos := [3]float32{-690, 0, 462}
+	var centerRef detour.DtPolyRef
+	query.FindNearestPoly(centerPos[:], halfExtents[:], filter, &centerRef, centerPos[:])
+	if centerRef == 0 {
+		t.Fatal("no polygon at the center")
+	}
+
+	const radius = 20
+	var verts [256 * 3]float32
+	var nverts int
+	stat := query.FindVisibilityPolygon(centerRef, centerPos[:], nil, radius, math.Pi, filter, verts[:], &nverts, 256)
+	if stat != detour.DT_SUCCESS || nverts < 8 {
+		t.Fatalf("full circle: 0x%x %d", stat, nverts)
+	}
+	seenPast := false
+	for i := 0; i < nverts; i++ {
+		v := verts[i*3:]
+		if d := detour.DtVdist2D(v, centerPos[:]); d > radius+1e-3 {
+			t.Fatalf("vertex %d is %f away", i, d)
+		}
+		var hit float32
+		var n int
+		query.Raycast(centerRef, centerPos[:], v, filter, &hit, nil, nil, &n, 0)
+		if hit < 0.99 {
+			t.Fatalf("vertex %d cannot be seen: %f", i, hit)
+		}
+		if v[0] < -690 && v[2] < 455 {
+			t.Fatalf("vertex %d is behind the wall: %v", i, v[:3])
+		}
+		seenPast = seenPast || v[2] < 450
+	}
+	// The view goes around the end of the wall.
+	if !seenPast {
+		t.Fatal("nothing is seen past the end of the wall")
+	}
+
+	// A short buffer is filled from the first vertex.
+	var short [3 * 3]float32
+	var nshort int
+	stat = query.FindVisibilityPolygon(centerRef, centerPos[:], nil, radius, math.Pi, filter, short[:], &nshort, 3)
+	if stat != detour.DT_SUCCESS|detour.DT_BUFFER_TOO_SMALL || nshort != 3 || !detour.DtVequal(short[6:], verts[6:]) {
+		t.Fatalf("short buffer: 0x%x %d", stat, nshort)
+	}
+
+	// A cone starts at the center and stays within its angle.
+	dir := [3]float32{0, 0, -1}
+	const halfAngle = math.Pi / 4
+	stat = query.FindVisibilityPolygon(centerRef, centerPos[:], dir[:], radius, halfAngle, filter, verts[:], &nverts, 256)
+	if stat != detour.DT_SUCCESS || nverts < 4 || !detour.DtVequal(verts[:], centerPos[:]) {
+		t.Fatalf("cone: 0x%x %d", stat, nverts)
+	}
+	for i := 1; i < nverts; i++ {
+		var d [3]float32
+		detour.DtVsub(d[:], verts[i*3:], centerPos[:])
+		d[1] = 0
+		if detour.DtVdot2D(d[:], dir[:]) < float32(math.Cos(halfAngle))*detour.DtVlen(d[:])-1e-3 {
+			t.Fatalf("vertex %d is outside the cone: %v", i, verts[i*3:i*3+3])
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package tests
+
+import (
+	"testing"
+
+	detour "github.com/fananchong/recastnavigation-go/Detour"
+)
+
+// findPartialPortal returns a polygon with a tile border edge whose link
+// covers only a part of the edge.
+func findPartialPortal(mesh *detour.DtNavMesh) (*detour.DtMeshTile, int, *detour.DtLink) {
+	for i := 0; i < int(mesh.GetMaxTiles()); i++ {
+		tile := mesh.GetTile(i)
+		if tile == nil || tile.Header == nil {
+			continue
+		}
+		for j := 0; j < int(tile.Header.PolyCount); j++ {
+			poly := &tile.Polys[j]
+			if poly.GetType() != detour.DT_POLYTYPE_GROUND {
+				continue
+			}
+			for k := poly.FirstLink; k != detour.DT_NULL_LINK; k = tile.Links[k].Next {
+				link := &tile.Links[k]
+				if link.Side != 0xff && link.Bmin > 0 && link.Bmax < 255 {
+					return tile, j, link
+				}
+			}
+		}
+	}
+	return nil, -1, nil
+}
+
+func Test_GetPolyWallSegmentsPortals(t *testing.T) {
+	mesh, _ := LoadDynamicMesh("scene1.obj.tilecache.bin")
+	query := CreateQuery(mesh, PATH_MAX_NODE)
+	filter := detour.DtAllocDtQueryFilter()
+
+	tile, ip, link := findPartialPortal(mesh)
+	if tile == nil {
+		t.Fatal("no partial portal")
+	}
+	poly := &tile.Polys[ip]
+	ref := mesh.GetPolyRefBase(tile) | detour.DtPolyRef(ip)
+	vj := tile.Verts[poly.Verts[link.Edge]*3:]
+	vi := tile.Verts[poly.Verts[(int(link.Edge)+1)%int(poly.VertCount)]*3:]
+
+	const MAX_SEGS int = 32
+	var segs [MAX_SEGS * 6]float32
+	var refs [MAX_SEGS]detour.DtPolyRef
+	var nsegs int
+	if stat := query.GetPolyWallSegments(ref, filter, segs[:], refs[:], &nsegs, MAX_SEGS); detour.DtStatusFailed(stat) {
+		t.Fatalf("GetPolyWallSegments: 0x%x", stat)
+	}
+
+	// The portal spans the link interval of the edge.
+	var pmin, pmax [3]float32
+	detour.DtVlerp(pmin[:], vj, vi, float32(link.Bmin)/255)
+	detour.DtVlerp(pmax[:], vj, vi, float32(link.Bmax)/255)
+	found := false
+	for i := 0; i < nsegs; i++ {
+		if refs[i] == link.Ref {
+			found = detour.DtVdistSqr(segs[i*6:i*6+3], pmin[:]) < 1e-6 && detour.DtVdistSqr(segs[i*6+3:i*6+6], pmax[:]) < 1e-6
+			break
+		}
+	}
+	if !found {
+		t.Fatalf("portal to %d does not span [%d, %d] of edge %d", link.Ref, link.Bmin, link.Bmax, link.Edge)
+	}
+
+	// The sub-segments of the edge do not collapse.
+	for i := 0; i < nsegs; i++ {
+		if detour.DtVdistSqr(segs[i*6:i*6+3], segs[i*6+3:i*6+6]) == 0 {
+			t.Fatalf("segment %d to %d has no length", i, refs[i])
+		}
+	}
+}